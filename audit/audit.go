@@ -0,0 +1,658 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+
+	"github.com/joltify-finance/tss/conversion"
+)
+
+const (
+	ActionKeygen  = "keygen"
+	ActionKeysign = "keysign"
+	ActionPresign = "presign"
+	// ActionStart and ActionStop are the admin actions on the server
+	ActionStart = "start"
+	ActionStop  = "stop"
+	// ActionBlameHistory, ActionPeerScores and ActionAddresses are the operator queries of the node, the health and
+	// status probes are polled too often to be recorded
+	ActionBlameHistory = "blame_history"
+	ActionPeerScores   = "peer_scores"
+	ActionAddresses    = "addresses"
+
+	// DefaultMaxFileSize is the size of the log file above which the log is rotated to the new file
+	DefaultMaxFileSize = 64 << 20
+
+	// StageRequest is the entry of the request written before the node acts on it, StageOutcome is the entry of
+	// what the node returns for the request
+	StageRequest = "request"
+	StageOutcome = "outcome"
+
+	filePrefix = "audit-"
+	fileSuffix = ".log"
+	// checkpointFile keeps the signed head of the log
+	checkpointFile = "checkpoint.json"
+	// the buffer of the scanner must hold the longest entry, the keysign of many messages is long
+	maxEntrySize = 16 << 20
+)
+
+// Caller is the identity of the client that asked the node to act, as seen by the HTTP layer
+type Caller struct {
+	RemoteAddr   string `json:"remote_addr,omitempty"`
+	ForwardedFor string `json:"forwarded_for,omitempty"`
+	// Identity is the subject of the client certificate if the client is authenticated with TLS
+	Identity  string `json:"identity,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+type callerKey struct{}
+
+// WithCaller returns the context that carries the caller of the request
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller of the request, it is empty for the requests not from the HTTP layer
+func CallerFromContext(ctx context.Context) Caller {
+	if ctx == nil {
+		return Caller{}
+	}
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// Entry is one action of the node in the audit log, each entry commits to the hash of the previous one so that
+// the change or removal of any entry breaks the chain
+type Entry struct {
+	Seq       uint64    `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	// Stage is empty for the admin actions
+	Stage string `json:"stage,omitempty"`
+	// RequestHash is the hash of the request entry the outcome belongs to
+	RequestHash  string          `json:"request_hash,omitempty"`
+	Caller       Caller          `json:"caller"`
+	MsgID        string          `json:"msg_id,omitempty"`
+	Request      json.RawMessage `json:"request,omitempty"`
+	Participants []string        `json:"participants,omitempty"`
+	Status       string          `json:"status,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	// Result is what the node returned, the signatures of the keysign or the pool pub key of the keygen
+	Result     json.RawMessage `json:"result,omitempty"`
+	FailReason string          `json:"fail_reason,omitempty"`
+	BlameNodes []string        `json:"blame_nodes,omitempty"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// NewEntry creates the entry of the action asked by the caller in ctx, the request is recorded as it is received
+func NewEntry(ctx context.Context, action string, request interface{}) *Entry {
+	entry := &Entry{
+		Action: action,
+		Caller: CallerFromContext(ctx),
+	}
+	if request != nil {
+		if buf, err := json.Marshal(request); err == nil {
+			entry.Request = buf
+		}
+	}
+	return entry
+}
+
+// Outcome returns the entry of the outcome of the request, it links to the request entry appended to the log
+func (e *Entry) Outcome() *Entry {
+	return &Entry{
+		Action:      e.Action,
+		Stage:       StageOutcome,
+		RequestHash: e.Hash,
+		Caller:      e.Caller,
+		MsgID:       e.MsgID,
+	}
+}
+
+// SetResult records the value returned to the caller
+func (e *Entry) SetResult(result interface{}) {
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	e.Result = buf
+}
+
+// computeHash returns the hash of the entry without its own hash, it covers the hash of the previous entry
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Config is the rotation policy of the audit log
+type Config struct {
+	// MaxFileSize is the size of the log file above which the next entry goes to the new file, default to 64MiB
+	MaxFileSize int64
+	// MaxFiles is the number of the log files we keep, the oldest files are removed on rotation. The remaining
+	// files still verify as the first kept entry anchors the chain. Zero keeps all the files
+	MaxFiles int
+	// PrivKey signs the checkpoint of the head of the log, the checkpoint is not kept if it is nil
+	PrivKey tcrypto.PrivKey
+}
+
+// Log is the append-only audit log in the folder. All the methods are no-op on the nil log, so the callers need
+// not check whether it is enabled
+type Log struct {
+	logger   zerolog.Logger
+	folder   string
+	conf     Config
+	pubKey   string
+	lock     *sync.Mutex
+	file     *os.File
+	size     int64
+	firstSeq uint64
+	nextSeq  uint64
+	lastHash string
+	// failure is the error of the last append, it is nil once an append succeeds
+	failure error
+}
+
+// NewLog opens the audit log in the folder, the new entries continue the chain of the existing files. The record
+// torn by the crash at the end of the newest file is truncated, and the log that no longer reaches the signed
+// checkpoint is rejected
+func NewLog(folder string, conf Config) (*Log, error) {
+	if conf.MaxFileSize <= 0 {
+		conf.MaxFileSize = DefaultMaxFileSize
+	}
+	if err := os.MkdirAll(folder, 0o700); err != nil {
+		return nil, fmt.Errorf("fail to create the audit folder: %w", err)
+	}
+	l := &Log{
+		logger: log.With().Str("module", "audit").Logger(),
+		folder: folder,
+		conf:   conf,
+		lock:   &sync.Mutex{},
+	}
+	if conf.PrivKey != nil {
+		pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: conf.PrivKey.PubKey().Bytes()})
+		if err != nil {
+			return nil, fmt.Errorf("fail to marshal the pub key: %w", err)
+		}
+		l.pubKey = pubKey
+	}
+	files, err := logFiles(folder)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) != 0 {
+		if err := l.truncateTornRecord(files[len(files)-1]); err != nil {
+			return nil, err
+		}
+		if l.firstSeq, err = fileSeq(files[0]); err != nil {
+			return nil, err
+		}
+	}
+	// the chain continues from the last entry of the newest file that has one
+	var last *Entry
+	for i := len(files) - 1; i >= 0 && last == nil; i-- {
+		if last, err = lastEntry(files[i]); err != nil {
+			return nil, err
+		}
+	}
+	if last != nil {
+		l.nextSeq = last.Seq + 1
+		l.lastHash = last.Hash
+	}
+	if err := l.checkCheckpoint(last); err != nil {
+		return nil, err
+	}
+	if len(files) != 0 {
+		if err := l.open(files[len(files)-1]); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+	l.firstSeq = l.nextSeq
+	if err := l.open(l.fileName(l.nextSeq)); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// truncateTornRecord removes the incomplete record at the end of the file, each entry is written along with its
+// line break, so the record without it is torn by the crash in the middle of the write
+func (l *Log) truncateTornRecord(filePathName string) error {
+	buf, err := os.ReadFile(filePathName)
+	if err != nil {
+		return fmt.Errorf("fail to read the audit log: %w", err)
+	}
+	if len(buf) == 0 || buf[len(buf)-1] == '\n' {
+		return nil
+	}
+	size := bytes.LastIndexByte(buf, '\n') + 1
+	if err := os.Truncate(filePathName, int64(size)); err != nil {
+		return fmt.Errorf("fail to truncate the torn audit record: %w", err)
+	}
+	l.logger.Warn().Msgf("the torn record of %d bytes at the end of %s is truncated", len(buf)-size, filePathName)
+	return nil
+}
+
+// checkCheckpoint checks the log still reaches the checkpoint, only the entry written right before the crash may
+// be past it, in which case the checkpoint is signed again
+func (l *Log) checkCheckpoint(last *Entry) error {
+	if l.conf.PrivKey == nil {
+		return nil
+	}
+	cp, err := loadCheckpoint(l.folder)
+	if errors.Is(err, os.ErrNotExist) {
+		return l.writeCheckpoint()
+	}
+	if err != nil {
+		return err
+	}
+	if cp.PubKey != l.pubKey {
+		return fmt.Errorf("the audit checkpoint is signed by %s rather than this node", cp.PubKey)
+	}
+	if err := cp.verify(); err != nil {
+		return err
+	}
+	if l.firstSeq > cp.FirstSeq {
+		return fmt.Errorf("the audit log starts at seq %d after the checkpoint at seq %d", l.firstSeq, cp.FirstSeq)
+	}
+	switch {
+	case last == nil || last.Seq < cp.LastSeq:
+		return fmt.Errorf("the audit log ends before the checkpoint at seq %d", cp.LastSeq)
+	case last.Seq == cp.LastSeq && last.Hash == cp.Head:
+		return nil
+	case last.Seq == cp.LastSeq+1 && last.PrevHash == cp.Head:
+		return l.writeCheckpoint()
+	default:
+		return fmt.Errorf("the audit log does not match the checkpoint at seq %d", cp.LastSeq)
+	}
+}
+
+func (l *Log) fileName(firstSeq uint64) string {
+	return filepath.Join(l.folder, fmt.Sprintf("%s%020d%s", filePrefix, firstSeq, fileSuffix))
+}
+
+func (l *Log) open(filePathName string) error {
+	f, err := os.OpenFile(filePathName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("fail to open the audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("fail to stat the audit log: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Append chains the entry to the log and writes it to the disk before it returns
+func (l *Log) Append(entry *Entry) error {
+	if l == nil || entry == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.failure = l.append(entry)
+	return l.failure
+}
+
+// Failure returns the error of the last append if it failed, so the node can tell it is not able to account for the
+// requests it serves
+func (l *Log) Failure() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.failure
+}
+
+func (l *Log) append(entry *Entry) error {
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+	if l.size >= l.conf.MaxFileSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	entry.Seq = l.nextSeq
+	entry.Timestamp = time.Now().UTC()
+	entry.PrevHash = l.lastHash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return fmt.Errorf("fail to hash the audit entry: %w", err)
+	}
+	entry.Hash = hash
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the audit entry: %w", err)
+	}
+	buf = append(buf, '\n')
+	if _, err := l.file.Write(buf); err != nil {
+		return fmt.Errorf("fail to write the audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("fail to sync the audit log: %w", err)
+	}
+	l.size += int64(len(buf))
+	l.nextSeq++
+	l.lastHash = hash
+	return l.writeCheckpoint()
+}
+
+// rotate starts the new file named after the sequence of its first entry, and removes the oldest files beyond
+// the retention
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("fail to close the audit log: %w", err)
+	}
+	l.file = nil
+	if err := l.open(l.fileName(l.nextSeq)); err != nil {
+		return err
+	}
+	l.logger.Info().Msgf("audit log is rotated at seq %d", l.nextSeq)
+	if l.conf.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := logFiles(l.folder)
+	if err != nil {
+		return err
+	}
+	if len(files) <= l.conf.MaxFiles {
+		return nil
+	}
+	expired := files[:len(files)-l.conf.MaxFiles]
+	// the checkpoint moves to the first kept entry before the files are removed, so that the removal of the
+	// files beyond it is detected
+	firstSeq, err := fileSeq(files[len(expired)])
+	if err != nil {
+		return err
+	}
+	l.firstSeq = firstSeq
+	if err := l.writeCheckpoint(); err != nil {
+		return err
+	}
+	for _, f := range expired {
+		if err := os.Remove(f); err != nil {
+			return fmt.Errorf("fail to remove the expired audit log: %w", err)
+		}
+		l.logger.Info().Msgf("expired audit log %s is removed", f)
+	}
+	return nil
+}
+
+// writeCheckpoint signs the head of the log and replaces the checkpoint with it
+func (l *Log) writeCheckpoint() error {
+	if l.conf.PrivKey == nil || l.nextSeq == 0 {
+		return nil
+	}
+	cp := Checkpoint{
+		FirstSeq: l.firstSeq,
+		LastSeq:  l.nextSeq - 1,
+		Head:     l.lastHash,
+		PubKey:   l.pubKey,
+	}
+	buf, err := cp.signBytes()
+	if err != nil {
+		return fmt.Errorf("fail to marshal the audit checkpoint: %w", err)
+	}
+	cp.Signature, err = l.conf.PrivKey.Sign(buf)
+	if err != nil {
+		return fmt.Errorf("fail to sign the audit checkpoint: %w", err)
+	}
+	buf, err = json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the audit checkpoint: %w", err)
+	}
+	filePathName := filepath.Join(l.folder, checkpointFile)
+	tmpFile := filePathName + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("fail to write the audit checkpoint: %w", err)
+	}
+	_, err = f.Write(buf)
+	if err == nil {
+		err = f.Sync()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("fail to write the audit checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpFile, filePathName); err != nil {
+		return fmt.Errorf("fail to write the audit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log, the later entries are rejected
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// logFiles returns the log files in the folder, the oldest first
+func logFiles(folder string) ([]string, error) {
+	dirEntries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read the audit folder: %w", err)
+	}
+	var files []string
+	for _, el := range dirEntries {
+		name := el.Name()
+		if el.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		if _, err := fileSeq(name); err != nil {
+			continue
+		}
+		files = append(files, filepath.Join(folder, name))
+	}
+	// the fixed width sequence in the names sorts in the order of the files
+	sort.Strings(files)
+	return files, nil
+}
+
+// fileSeq returns the seq of the first entry of the log file, which the file is named after
+func fileSeq(filePathName string) (uint64, error) {
+	name := filepath.Base(filePathName)
+	seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid audit log name %s: %w", name, err)
+	}
+	return seq, nil
+}
+
+// readEntries calls fn with each entry of the file and the line it is on
+func readEntries(filePathName string, fn func(line int, entry Entry) error) error {
+	f, err := os.Open(filePathName)
+	if err != nil {
+		return fmt.Errorf("fail to open the audit log: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("%s:%d: invalid audit entry: %w", filePathName, line, err)
+		}
+		if err := fn(line, entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("fail to read the audit log %s: %w", filePathName, err)
+	}
+	return nil
+}
+
+func lastEntry(filePathName string) (*Entry, error) {
+	var last *Entry
+	err := readEntries(filePathName, func(_ int, entry Entry) error {
+		last = &entry
+		return nil
+	})
+	return last, err
+}
+
+// Checkpoint is the head of the log signed by the node, the log cut at either end no longer reaches it
+type Checkpoint struct {
+	FirstSeq  uint64 `json:"first_seq"`
+	LastSeq   uint64 `json:"last_seq"`
+	Head      string `json:"head"`
+	PubKey    string `json:"pub_key"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+func (c Checkpoint) signBytes() ([]byte, error) {
+	c.Signature = nil
+	return json.Marshal(c)
+}
+
+func (c Checkpoint) verify() error {
+	pk, err := conversion.ParsePubKey(c.PubKey)
+	if err != nil {
+		return fmt.Errorf("fail to parse the pub key of the audit checkpoint: %w", err)
+	}
+	buf, err := c.signBytes()
+	if err != nil {
+		return fmt.Errorf("fail to marshal the audit checkpoint: %w", err)
+	}
+	if !pk.VerifySignature(buf, c.Signature) {
+		return errors.New("invalid signature of the audit checkpoint")
+	}
+	return nil
+}
+
+func loadCheckpoint(folder string) (Checkpoint, error) {
+	var cp Checkpoint
+	buf, err := os.ReadFile(filepath.Join(folder, checkpointFile))
+	if err != nil {
+		return cp, err
+	}
+	if err := json.Unmarshal(buf, &cp); err != nil {
+		return cp, fmt.Errorf("fail to unmarshal the audit checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// Summary is the chain checked by Verify
+type Summary struct {
+	Files    int    `json:"files"`
+	Entries  uint64 `json:"entries"`
+	FirstSeq uint64 `json:"first_seq"`
+	LastSeq  uint64 `json:"last_seq"`
+	// Head is the hash of the last entry, publishing it elsewhere prevents the rewrite of the whole chain
+	Head string `json:"head"`
+	// PubKey is the key the checkpoint is signed with, it is empty if the log has no checkpoint
+	PubKey string `json:"pub_key,omitempty"`
+}
+
+// Verify checks the hash of each entry and the chain of the entries across all the log files in the folder. The
+// chain starts from the first kept entry if the older files are removed by the rotation. If the folder has the
+// checkpoint, the chain must span it, otherwise the entries are truncated at either end
+func Verify(folder string) (Summary, error) {
+	summary, err := verifyChain(folder)
+	if err != nil {
+		return summary, err
+	}
+	cp, err := loadCheckpoint(folder)
+	if errors.Is(err, os.ErrNotExist) {
+		return summary, nil
+	}
+	if err != nil {
+		return summary, err
+	}
+	if err := cp.verify(); err != nil {
+		return summary, err
+	}
+	summary.PubKey = cp.PubKey
+	if summary.FirstSeq > cp.FirstSeq {
+		return summary, fmt.Errorf("the audit log starts at seq %d after the checkpoint at seq %d", summary.FirstSeq, cp.FirstSeq)
+	}
+	if summary.LastSeq != cp.LastSeq || summary.Head != cp.Head {
+		return summary, fmt.Errorf("the audit log head at seq %d does not match the checkpoint at seq %d", summary.LastSeq, cp.LastSeq)
+	}
+	return summary, nil
+}
+
+func verifyChain(folder string) (Summary, error) {
+	files, err := logFiles(folder)
+	if err != nil {
+		return Summary{}, err
+	}
+	if len(files) == 0 {
+		return Summary{}, fmt.Errorf("no audit log in %s", folder)
+	}
+	summary := Summary{Files: len(files)}
+	for _, f := range files {
+		err := readEntries(f, func(line int, entry Entry) error {
+			hash, err := entry.computeHash()
+			if err != nil {
+				return fmt.Errorf("%s:%d: fail to hash the audit entry: %w", f, line, err)
+			}
+			if hash != entry.Hash {
+				return fmt.Errorf("%s:%d: hash of the entry seq %d mismatch", f, line, entry.Seq)
+			}
+			if summary.Entries == 0 {
+				summary.FirstSeq = entry.Seq
+				if entry.Seq == 0 && len(entry.PrevHash) != 0 {
+					return fmt.Errorf("%s:%d: the first entry has the previous hash", f, line)
+				}
+			} else {
+				if entry.Seq != summary.LastSeq+1 {
+					return fmt.Errorf("%s:%d: entry seq %d follows seq %d", f, line, entry.Seq, summary.LastSeq)
+				}
+				if entry.PrevHash != summary.Head {
+					return fmt.Errorf("%s:%d: entry seq %d is not chained to seq %d", f, line, entry.Seq, summary.LastSeq)
+				}
+			}
+			summary.Entries++
+			summary.LastSeq = entry.Seq
+			summary.Head = entry.Hash
+			return nil
+		})
+		if err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,224 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+)
+
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type AuditTestSuite struct{}
+
+var _ = Suite(&AuditTestSuite{})
+
+func (AuditTestSuite) TestCaller(c *C) {
+	c.Assert(CallerFromContext(context.Background()), DeepEquals, Caller{})
+	caller := Caller{RemoteAddr: "127.0.0.1:1234", Identity: "bridge"}
+	entry := NewEntry(WithCaller(context.Background(), caller), ActionKeysign, map[string]string{"pool_pub_key": "pool"})
+	c.Assert(entry.Caller, DeepEquals, caller)
+	c.Assert(string(entry.Request), Equals, `{"pool_pub_key":"pool"}`)
+}
+
+func (AuditTestSuite) TestAppendAndVerify(c *C) {
+	folder := c.MkDir()
+	l, err := NewLog(folder, Config{})
+	c.Assert(err, IsNil)
+	for i := 0; i < 3; i++ {
+		entry := NewEntry(context.Background(), ActionKeysign, nil)
+		entry.MsgID = "msgID"
+		entry.SetResult([]string{"signature"})
+		c.Assert(l.Append(entry), IsNil)
+		c.Assert(entry.Seq, Equals, uint64(i))
+	}
+	c.Assert(l.Failure(), IsNil)
+	c.Assert(l.Close(), IsNil)
+	c.Assert(l.Append(NewEntry(context.Background(), ActionStop, nil)), NotNil)
+	c.Assert(l.Failure(), NotNil)
+
+	// the reopened log continues the chain
+	l, err = NewLog(folder, Config{})
+	c.Assert(err, IsNil)
+	entry := NewEntry(context.Background(), ActionStart, nil)
+	c.Assert(l.Append(entry), IsNil)
+	c.Assert(entry.Seq, Equals, uint64(3))
+	c.Assert(l.Close(), IsNil)
+
+	summary, err := Verify(folder)
+	c.Assert(err, IsNil)
+	c.Assert(summary.Files, Equals, 1)
+	c.Assert(summary.Entries, Equals, uint64(4))
+	c.Assert(summary.LastSeq, Equals, uint64(3))
+	c.Assert(summary.Head, Equals, entry.Hash)
+
+	var nilLog *Log
+	c.Assert(nilLog.Append(entry), IsNil)
+	c.Assert(nilLog.Failure(), IsNil)
+	c.Assert(nilLog.Close(), IsNil)
+}
+
+func (AuditTestSuite) TestRotation(c *C) {
+	folder := c.MkDir()
+	// every entry is larger than the file, so each goes to its own file
+	l, err := NewLog(folder, Config{MaxFileSize: 1, MaxFiles: 3})
+	c.Assert(err, IsNil)
+	for i := 0; i < 5; i++ {
+		c.Assert(l.Append(NewEntry(context.Background(), ActionKeygen, nil)), IsNil)
+	}
+	c.Assert(l.Close(), IsNil)
+	files, err := logFiles(folder)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 3)
+	c.Assert(filepath.Base(files[0]), Equals, "audit-00000000000000000002.log")
+
+	summary, err := Verify(folder)
+	c.Assert(err, IsNil)
+	c.Assert(summary.Files, Equals, 3)
+	c.Assert(summary.FirstSeq, Equals, uint64(2))
+	c.Assert(summary.LastSeq, Equals, uint64(4))
+
+	// the removal of the file in the middle breaks the chain
+	c.Assert(os.Remove(files[1]), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, ".*entry seq 4 follows seq 2.*")
+}
+
+func (AuditTestSuite) TestTamper(c *C) {
+	folder := c.MkDir()
+	l, err := NewLog(folder, Config{})
+	c.Assert(err, IsNil)
+	for _, msgID := range []string{"msg1", "msg2", "msg3"} {
+		entry := NewEntry(context.Background(), ActionKeysign, nil)
+		entry.MsgID = msgID
+		c.Assert(l.Append(entry), IsNil)
+	}
+	c.Assert(l.Close(), IsNil)
+	files, err := logFiles(folder)
+	c.Assert(err, IsNil)
+	buf, err := os.ReadFile(files[0])
+	c.Assert(err, IsNil)
+
+	c.Assert(os.WriteFile(files[0], bytes.Replace(buf, []byte("msg2"), []byte("msg4"), 1), 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, ".*:2: hash of the entry seq 1 mismatch")
+
+	// the entry removed from the middle of the file
+	lines := bytes.SplitAfter(buf, []byte("\n"))
+	c.Assert(os.WriteFile(files[0], append(append([]byte{}, lines[0]...), lines[2]...), 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, ".*:2: entry seq 2 follows seq 0")
+
+	c.Assert(os.WriteFile(files[0], append(buf, []byte("{\n")...), 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, ".*:4: invalid audit entry.*")
+
+	_, err = Verify(c.MkDir())
+	c.Assert(err, ErrorMatches, "no audit log in .*")
+}
+
+func (AuditTestSuite) TestTornRecord(c *C) {
+	folder := c.MkDir()
+	l, err := NewLog(folder, Config{})
+	c.Assert(err, IsNil)
+	for i := 0; i < 2; i++ {
+		c.Assert(l.Append(NewEntry(context.Background(), ActionKeysign, nil)), IsNil)
+	}
+	c.Assert(l.Close(), IsNil)
+	files, err := logFiles(folder)
+	c.Assert(err, IsNil)
+	buf, err := os.ReadFile(files[0])
+	c.Assert(err, IsNil)
+	// the node crashes in the middle of the write of the third entry
+	c.Assert(os.WriteFile(files[0], append(buf, []byte(`{"seq":2,"timest`)...), 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, NotNil)
+
+	l, err = NewLog(folder, Config{})
+	c.Assert(err, IsNil)
+	entry := NewEntry(context.Background(), ActionStart, nil)
+	c.Assert(l.Append(entry), IsNil)
+	c.Assert(entry.Seq, Equals, uint64(2))
+	c.Assert(l.Close(), IsNil)
+	summary, err := Verify(folder)
+	c.Assert(err, IsNil)
+	c.Assert(summary.Entries, Equals, uint64(3))
+}
+
+func (AuditTestSuite) TestCheckpoint(c *C) {
+	folder := c.MkDir()
+	sk := ed25519.GenPrivKey()
+	conf := Config{MaxFileSize: 1, MaxFiles: 3, PrivKey: sk}
+	l, err := NewLog(folder, conf)
+	c.Assert(err, IsNil)
+	for i := 0; i < 5; i++ {
+		c.Assert(l.Append(NewEntry(context.Background(), ActionKeygen, nil)), IsNil)
+	}
+	c.Assert(l.Close(), IsNil)
+	summary, err := Verify(folder)
+	c.Assert(err, IsNil)
+	c.Assert(summary.PubKey, Equals, l.pubKey)
+	c.Assert(summary.FirstSeq, Equals, uint64(2))
+	c.Assert(summary.LastSeq, Equals, uint64(4))
+
+	files, err := logFiles(folder)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 3)
+	for _, el := range []struct {
+		removed string
+		errMsg  string
+		openErr string
+	}{
+		{files[2], ".*log head at seq 3 does not match the checkpoint at seq 4", ".*ends before the checkpoint at seq 4"},
+		{files[0], ".*starts at seq 3 after the checkpoint at seq 2", ".*starts at seq 3 after the checkpoint at seq 2"},
+	} {
+		buf, err := os.ReadFile(el.removed)
+		c.Assert(err, IsNil)
+		c.Assert(os.Remove(el.removed), IsNil)
+		_, err = Verify(folder)
+		c.Assert(err, ErrorMatches, el.errMsg)
+		_, err = NewLog(folder, conf)
+		c.Assert(err, ErrorMatches, el.openErr)
+		c.Assert(os.WriteFile(el.removed, buf, 0o600), IsNil)
+	}
+
+	cpFile := filepath.Join(folder, checkpointFile)
+	buf, err := os.ReadFile(cpFile)
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(cpFile, bytes.Replace(buf, []byte(`"last_seq":4`), []byte(`"last_seq":3`), 1), 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, "invalid signature of the audit checkpoint")
+	c.Assert(os.WriteFile(cpFile, buf, 0o600), IsNil)
+
+	// the log signed by another node is not continued
+	_, err = NewLog(folder, Config{PrivKey: ed25519.GenPrivKey()})
+	c.Assert(err, ErrorMatches, "the audit checkpoint is signed by .* rather than this node")
+}
+
+func (AuditTestSuite) TestCheckpointRecovery(c *C) {
+	folder := c.MkDir()
+	conf := Config{PrivKey: ed25519.GenPrivKey()}
+	l, err := NewLog(folder, conf)
+	c.Assert(err, IsNil)
+	c.Assert(l.Append(NewEntry(context.Background(), ActionStart, nil)), IsNil)
+	cpFile := filepath.Join(folder, checkpointFile)
+	buf, err := os.ReadFile(cpFile)
+	c.Assert(err, IsNil)
+	c.Assert(l.Append(NewEntry(context.Background(), ActionStop, nil)), IsNil)
+	c.Assert(l.Close(), IsNil)
+
+	// the node crashes after the write of the entry before the checkpoint is updated
+	c.Assert(os.WriteFile(cpFile, buf, 0o600), IsNil)
+	_, err = Verify(folder)
+	c.Assert(err, ErrorMatches, ".*log head at seq 1 does not match the checkpoint at seq 0")
+	l, err = NewLog(folder, conf)
+	c.Assert(err, IsNil)
+	c.Assert(l.Close(), IsNil)
+	summary, err := Verify(folder)
+	c.Assert(err, IsNil)
+	c.Assert(summary.LastSeq, Equals, uint64(1))
+}
@@ -0,0 +1,154 @@
+package blame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	tcrypto "github.com/tendermint/tendermint/crypto"
+
+	"github.com/joltify-finance/tss/conversion"
+)
+
+var ErrInvalidVote = errors.New("invalid blame vote")
+
+// Vote is the blame a node computed on its own after the failed ceremony, the parties exchange the signed
+// votes to agree on the nodes to blame
+type Vote struct {
+	MsgID      string   `json:"msg_id"`
+	Voter      string   `json:"voter"`
+	FailReason string   `json:"fail_reason"`
+	BlameNodes []string `json:"blame_nodes"`
+	Signature  []byte   `json:"signature,omitempty"`
+}
+
+// NewVote create the vote of the voter with the blame nodes in the given blame
+func NewVote(msgID, voter string, b Blame) Vote {
+	seen := make(map[string]bool)
+	blameNodes := make([]string, 0, len(b.BlameNodes))
+	for _, el := range b.BlameNodes {
+		if seen[el.Pubkey] {
+			continue
+		}
+		seen[el.Pubkey] = true
+		blameNodes = append(blameNodes, el.Pubkey)
+	}
+	sort.Strings(blameNodes)
+	return Vote{
+		MsgID:      msgID,
+		Voter:      voter,
+		FailReason: b.FailReason,
+		BlameNodes: blameNodes,
+	}
+}
+
+func (v Vote) bytesToSign() ([]byte, error) {
+	v.Signature = nil
+	return json.Marshal(v)
+}
+
+// Sign signs the vote with the node key of the voter
+func (v *Vote) Sign(privKey tcrypto.PrivKey) error {
+	buf, err := v.bytesToSign()
+	if err != nil {
+		return fmt.Errorf("fail to marshal the vote: %w", err)
+	}
+	sig, err := privKey.Sign(buf)
+	if err != nil {
+		return fmt.Errorf("fail to sign the vote: %w", err)
+	}
+	v.Signature = sig
+	return nil
+}
+
+// VerifyVote checks the vote is signed by the voter
+func VerifyVote(v Vote) error {
+	if len(v.Signature) == 0 {
+		return fmt.Errorf("vote is not signed: %w", ErrInvalidVote)
+	}
+	pk, err := conversion.ParsePubKey(v.Voter)
+	if err != nil {
+		return fmt.Errorf("fail to parse the voter pubkey(%s): %w", v.Voter, err)
+	}
+	buf, err := v.bytesToSign()
+	if err != nil {
+		return fmt.Errorf("fail to marshal the vote: %w", err)
+	}
+	if !pk.VerifySignature(buf, v.Signature) {
+		return fmt.Errorf("signature mismatch: %w", ErrInvalidVote)
+	}
+	return nil
+}
+
+// AccusedNodes returns the nodes blamed by at least minVotes voters. The node blamed by fewer voters is not
+// accused, so that a minority of the nodes can not silence the honest voters by blaming them.
+func AccusedNodes(votes []Vote, minVotes int) map[string]bool {
+	counts := make(map[string]int)
+	for _, v := range votes {
+		for _, el := range v.BlameNodes {
+			counts[el]++
+		}
+	}
+	accused := make(map[string]bool)
+	for k, v := range counts {
+		if v >= minVotes {
+			accused[k] = true
+		}
+	}
+	return accused
+}
+
+// MergeVotes returns the blame of the nodes voted by at least minVotes voters and the number of votes of
+// every blamed node. The votes of the accused nodes are never counted, so the result does not depend on
+// whether they arrive. The blame data of the local blame is kept as the evidence, and the fail reason is
+// the one that most voters agree on.
+func MergeVotes(local Blame, votes []Vote, minVotes int) (Blame, map[string]int) {
+	accused := AccusedNodes(votes, minVotes)
+	counts := make(map[string]int)
+	reasons := make(map[string]int)
+	for _, v := range votes {
+		if accused[v.Voter] {
+			continue
+		}
+		for _, el := range v.BlameNodes {
+			counts[el]++
+		}
+		if len(v.FailReason) != 0 {
+			reasons[v.FailReason]++
+		}
+	}
+
+	failReason := local.FailReason
+	maxReason := 0
+	for k, v := range reasons {
+		// we break the tie by the reason itself so that all the nodes agree on it
+		if v > maxReason || (v == maxReason && k < failReason) {
+			maxReason = v
+			failReason = k
+		}
+	}
+
+	localNodes := make(map[string]Node)
+	for _, el := range local.BlameNodes {
+		localNodes[el.Pubkey] = el
+	}
+	var agreed []string
+	for k, v := range counts {
+		if v >= minVotes {
+			agreed = append(agreed, k)
+		}
+	}
+	sort.Strings(agreed)
+	blameNodes := make([]Node, 0, len(agreed))
+	for _, el := range agreed {
+		node, ok := localNodes[el]
+		if !ok {
+			node = NewNode(el, nil, nil)
+		}
+		blameNodes = append(blameNodes, node)
+	}
+	merged := NewBlame(failReason, blameNodes)
+	merged.IsUnicast = local.IsUnicast
+	return merged, counts
+}
@@ -0,0 +1,97 @@
+package blame
+
+import (
+	"errors"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+)
+
+type AgreementTestSuite struct{}
+
+var _ = Suite(&AgreementTestSuite{})
+
+func createVoter(c *C) (string, ed25519.PrivKey) {
+	sk := ed25519.GenPrivKey()
+	pk := coskey.PubKey{Key: sk.PubKey().Bytes()}
+	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pk)
+	c.Assert(err, IsNil)
+	return pubKey, sk
+}
+
+func (AgreementTestSuite) TestSignAndVerifyVote(c *C) {
+	voter, sk := createVoter(c)
+	other, _ := createVoter(c)
+	b := NewBlame(TssTimeout, []Node{createNewNode("2"), createNewNode("1"), createNewNode("2")})
+	vote := NewVote("testMsgID", voter, b)
+	c.Assert(vote.BlameNodes, DeepEquals, []string{"1", "2"})
+	c.Assert(errors.Is(VerifyVote(vote), ErrInvalidVote), Equals, true)
+
+	c.Assert(vote.Sign(sk), IsNil)
+	c.Assert(VerifyVote(vote), IsNil)
+
+	tampered := vote
+	tampered.BlameNodes = []string{"1"}
+	c.Assert(errors.Is(VerifyVote(tampered), ErrInvalidVote), Equals, true)
+	tampered = vote
+	tampered.Voter = other
+	c.Assert(errors.Is(VerifyVote(tampered), ErrInvalidVote), Equals, true)
+	tampered.Voter = "whatever"
+	c.Assert(VerifyVote(tampered), NotNil)
+}
+
+func (AgreementTestSuite) TestMergeVotes(c *C) {
+	local := NewBlame(TssTimeout, []Node{NewNode("1", []byte("data"), []byte("sig")), createNewNode("2")})
+	local.IsUnicast = true
+	votes := []Vote{
+		NewVote("testMsgID", "a", local),
+		NewVote("testMsgID", "b", NewBlame(TssBrokenMsg, []Node{createNewNode("1"), createNewNode("3")})),
+		NewVote("testMsgID", "c", NewBlame(TssBrokenMsg, []Node{createNewNode("1"), createNewNode("3")})),
+		NewVote("testMsgID", "d", NewBlame("", nil)),
+	}
+	merged, counts := MergeVotes(local, votes, 2)
+	c.Assert(counts, DeepEquals, map[string]int{"1": 3, "2": 1, "3": 2})
+	c.Assert(merged.FailReason, Equals, TssBrokenMsg)
+	c.Assert(merged.IsUnicast, Equals, true)
+	c.Assert(merged.BlameNodes, HasLen, 2)
+	// we keep the blame data we have locally
+	c.Assert(merged.BlameNodes[0].Pubkey, Equals, "1")
+	c.Assert(merged.BlameNodes[0].BlameData, DeepEquals, []byte("data"))
+	c.Assert(merged.BlameNodes[1].Pubkey, Equals, "3")
+
+	merged, _ = MergeVotes(local, votes, 4)
+	c.Assert(merged.BlameNodes, HasLen, 0)
+
+	// only our own vote
+	merged, counts = MergeVotes(local, votes[:1], 1)
+	c.Assert(merged.FailReason, Equals, TssTimeout)
+	c.Assert(merged.BlameNodes, HasLen, 2)
+	c.Assert(counts, DeepEquals, map[string]int{"1": 1, "2": 1})
+
+	// the votes of the accused nodes are not counted, whether they arrive or not
+	accusedVotes := []Vote{
+		NewVote("testMsgID", "a", NewBlame(TssTimeout, []Node{createNewNode("d")})),
+		NewVote("testMsgID", "b", NewBlame(TssTimeout, []Node{createNewNode("d")})),
+		NewVote("testMsgID", "c", NewBlame(TssTimeout, []Node{createNewNode("d")})),
+	}
+	merged, counts = MergeVotes(local, accusedVotes, 2)
+	c.Assert(counts, DeepEquals, map[string]int{"d": 3})
+	c.Assert(merged.BlameNodes, HasLen, 1)
+	withAccused, countsWithAccused := MergeVotes(local, append(accusedVotes, NewVote("testMsgID", "d", NewBlame(TssBrokenMsg, []Node{createNewNode("a"), createNewNode("b")}))), 2)
+	c.Assert(countsWithAccused, DeepEquals, counts)
+	c.Assert(withAccused.BlameNodes, DeepEquals, merged.BlameNodes)
+	c.Assert(withAccused.FailReason, Equals, TssTimeout)
+}
+
+func (AgreementTestSuite) TestAccusedNodes(c *C) {
+	votes := []Vote{
+		NewVote("testMsgID", "a", NewBlame(TssTimeout, []Node{createNewNode("d")})),
+		NewVote("testMsgID", "b", NewBlame(TssTimeout, []Node{createNewNode("d")})),
+		NewVote("testMsgID", "d", NewBlame(TssTimeout, []Node{createNewNode("a"), createNewNode("b")})),
+	}
+	c.Assert(AccusedNodes(votes, 2), DeepEquals, map[string]bool{"d": true})
+	c.Assert(AccusedNodes(votes, 1), DeepEquals, map[string]bool{"a": true, "b": true, "d": true})
+	c.Assert(AccusedNodes(nil, 1), HasLen, 0)
+}
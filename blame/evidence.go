@@ -0,0 +1,135 @@
+package blame
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+)
+
+var ErrInvalidEvidence = errors.New("invalid blame evidence")
+
+// Evidence bundles everything a third party needs to check that the blamed node did send the offending
+// message in the given tss session, it can be verified with VerifyEvidence without a running node.
+type Evidence struct {
+	MsgID     string `json:"msg_id"`
+	Round     string `json:"round"`
+	Reason    string `json:"reason"`
+	Pubkey    string `json:"pubkey"`
+	Message   []byte `json:"message"`
+	Signature []byte `json:"signature"`
+}
+
+// NewEvidence create the evidence of the blame node with the message it sent in the given round
+func NewEvidence(msgID, round, reason string, node Node) Evidence {
+	return Evidence{
+		MsgID:     msgID,
+		Round:     round,
+		Reason:    reason,
+		Pubkey:    node.Pubkey,
+		Message:   node.BlameData,
+		Signature: node.BlameSignature,
+	}
+}
+
+// VerifyEvidence checks the message in the evidence is signed by the blamed node for the tss session
+// identified by the msgID, the same way the tss nodes verify the wire message, and that the message belongs to the
+// round of the evidence
+func VerifyEvidence(evidence Evidence) error {
+	if len(evidence.MsgID) == 0 || len(evidence.Message) == 0 || len(evidence.Signature) == 0 {
+		return fmt.Errorf("incomplete evidence: %w", ErrInvalidEvidence)
+	}
+	pk, err := conversion.ParsePubKey(evidence.Pubkey)
+	if err != nil {
+		return fmt.Errorf("fail to parse the pubkey(%s): %w", evidence.Pubkey, err)
+	}
+	var dataForSign bytes.Buffer
+	dataForSign.Write(evidence.Message)
+	dataForSign.WriteString(evidence.MsgID)
+	if !pk.VerifySignature(dataForSign.Bytes(), evidence.Signature) {
+		return fmt.Errorf("signature mismatch: %w", ErrInvalidEvidence)
+	}
+	round, err := messageRound(evidence.Message)
+	if err != nil {
+		return fmt.Errorf("fail to get the round of the message(%v): %w", err, ErrInvalidEvidence)
+	}
+	if round != evidence.Round {
+		return fmt.Errorf("the message is of round %s rather than %s: %w", round, evidence.Round, ErrInvalidEvidence)
+	}
+	return nil
+}
+
+// messageRound returns the round of the signed message. The tss messages are sent in bulk, and the round of each
+// one is the type of the protobuf message it carries, while the presign share is the only message signed on its own
+func messageRound(message []byte) (string, error) {
+	message = bytes.TrimSpace(message)
+	if len(message) != 0 && message[0] == '{' {
+		if err := checkPresignShare(message); err != nil {
+			return "", err
+		}
+		return messages.TSSPresignShare.String(), nil
+	}
+	var bulkMsgs []struct {
+		WiredBulkMsgs []byte
+	}
+	if err := json.Unmarshal(message, &bulkMsgs); err != nil {
+		return "", fmt.Errorf("fail to unmarshal the bulk message: %w", err)
+	}
+	round := ""
+	for _, el := range bulkMsgs {
+		var content anypb.Any
+		if err := proto.Unmarshal(el.WiredBulkMsgs, &content); err != nil {
+			return "", fmt.Errorf("fail to unmarshal the wire message: %w", err)
+		}
+		msgRound := string(content.MessageName())
+		if len(msgRound) == 0 || (len(round) != 0 && msgRound != round) {
+			return "", errors.New("the bulk message is not of one round")
+		}
+		round = msgRound
+	}
+	if len(round) == 0 {
+		return "", errors.New("empty bulk message")
+	}
+	return round, nil
+}
+
+// checkPresignShare checks the message is the signature share the signer sends in the online round of the presigned
+// keysign
+func checkPresignShare(message []byte) error {
+	var share messages.PresignShare
+	dec := json.NewDecoder(bytes.NewReader(message))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&share); err != nil {
+		return fmt.Errorf("fail to unmarshal the presign share: %w", err)
+	}
+	if dec.More() {
+		return errors.New("trailing data after the presign share")
+	}
+	if len(share.PresignIDs) == 0 || len(share.PresignIDs) != len(share.Shares) {
+		return errors.New("the presign share does not have one share for each presignature")
+	}
+	return nil
+}
+
+// UnmarshalEvidences parses the evidence bundle, which is either a single evidence or a list of them
+func UnmarshalEvidences(data []byte) ([]Evidence, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) != 0 && data[0] == '[' {
+		var evidences []Evidence
+		if err := json.Unmarshal(data, &evidences); err != nil {
+			return nil, fmt.Errorf("fail to unmarshal the evidences: %w", err)
+		}
+		return evidences, nil
+	}
+	var evidence Evidence
+	if err := json.Unmarshal(data, &evidence); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the evidence: %w", err)
+	}
+	return []Evidence{evidence}, nil
+}
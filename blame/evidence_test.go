@@ -0,0 +1,134 @@
+package blame
+
+import (
+	"encoding/json"
+	"errors"
+
+	bkg "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+type EvidenceTestSuite struct{}
+
+var _ = Suite(&EvidenceTestSuite{})
+
+func createSignedNode(c *C, msgID string, msg []byte) (Node, ed25519.PrivKey) {
+	sk := ed25519.GenPrivKey()
+	pk := coskey.PubKey{Key: sk.PubKey().Bytes()}
+	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pk)
+	c.Assert(err, IsNil)
+	sig, err := sk.Sign(append(append([]byte{}, msg...), []byte(msgID)...))
+	c.Assert(err, IsNil)
+	return NewNode(pubKey, msg, sig), sk
+}
+
+// bulkMessage builds the message of the tss round the same way as the tss nodes send it
+func bulkMessage(c *C, contents ...proto.Message) []byte {
+	var bulkMsgs []map[string][]byte
+	for _, el := range contents {
+		content, err := anypb.New(el)
+		c.Assert(err, IsNil)
+		buf, err := proto.Marshal(content)
+		c.Assert(err, IsNil)
+		bulkMsgs = append(bulkMsgs, map[string][]byte{"WiredBulkMsgs": buf})
+	}
+	buf, err := json.Marshal(bulkMsgs)
+	c.Assert(err, IsNil)
+	return buf
+}
+
+func (EvidenceTestSuite) TestVerifyEvidence(c *C) {
+	msg := bulkMessage(c, &bkg.KGRound2Message1{Share: []byte("invalid share")})
+	node, _ := createSignedNode(c, "testMsgID", msg)
+	evidence := NewEvidence("testMsgID", messages.KEYGEN2aUnicast, TssBrokenMsg, node)
+	c.Assert(VerifyEvidence(evidence), IsNil)
+
+	// the round is not signed, so it must match the message
+	wrongRound := evidence
+	wrongRound.Round = messages.KEYGEN1
+	c.Assert(errors.Is(VerifyEvidence(wrongRound), ErrInvalidEvidence), Equals, true)
+	wrongRound.Round = messages.TSSPresignShare.String()
+	c.Assert(errors.Is(VerifyEvidence(wrongRound), ErrInvalidEvidence), Equals, true)
+
+	mixedNode, _ := createSignedNode(c, "testMsgID", bulkMessage(c, &bkg.KGRound2Message1{}, &bkg.KGRound1Message{}))
+	mixed := NewEvidence("testMsgID", messages.KEYGEN2aUnicast, TssBrokenMsg, mixedNode)
+	c.Assert(errors.Is(VerifyEvidence(mixed), ErrInvalidEvidence), Equals, true)
+
+	brokenNode, _ := createSignedNode(c, "testMsgID", []byte(`[{"WiredBulkMsgs":"aW52YWxpZA=="}]`))
+	broken := NewEvidence("testMsgID", messages.KEYGEN2aUnicast, TssBrokenMsg, brokenNode)
+	c.Assert(errors.Is(VerifyEvidence(broken), ErrInvalidEvidence), Equals, true)
+
+	// the presign share is the only message signed on its own
+	shareNode, _ := createSignedNode(c, "testMsgID", []byte(`{"presign_ids":["id"],"shares":["AQ=="]}`))
+	share := NewEvidence("testMsgID", messages.TSSPresignShare.String(), TssBrokenMsg, shareNode)
+	c.Assert(VerifyEvidence(share), IsNil)
+	share.Round = messages.KEYGEN2aUnicast
+	c.Assert(errors.Is(VerifyEvidence(share), ErrInvalidEvidence), Equals, true)
+
+	// the message that is neither the bulk message nor the presign share has no round
+	for _, el := range []string{"invalid share", `{"presign_ids":["id"]}`, `{"presign_ids":["id"],"shares":["AQ=="],"extra":1}`, `{"presign_ids":["id"],"shares":["AQ=="]}{}`} {
+		otherNode, _ := createSignedNode(c, "testMsgID", []byte(el))
+		other := NewEvidence("testMsgID", messages.TSSPresignShare.String(), TssBrokenMsg, otherNode)
+		c.Assert(errors.Is(VerifyEvidence(other), ErrInvalidEvidence), Equals, true, Commentf("%s", el))
+	}
+
+	// the signature is bound to the tss session
+	wrongSession := evidence
+	wrongSession.MsgID = "anotherMsgID"
+	c.Assert(errors.Is(VerifyEvidence(wrongSession), ErrInvalidEvidence), Equals, true)
+
+	tampered := evidence
+	tampered.Message = bulkMessage(c, &bkg.KGRound2Message1{Share: []byte("valid share")})
+	c.Assert(errors.Is(VerifyEvidence(tampered), ErrInvalidEvidence), Equals, true)
+
+	// the evidence should not be pinned on another node
+	otherNode, _ := createSignedNode(c, "testMsgID", msg)
+	wrongSigner := evidence
+	wrongSigner.Pubkey = otherNode.Pubkey
+	c.Assert(errors.Is(VerifyEvidence(wrongSigner), ErrInvalidEvidence), Equals, true)
+
+	incomplete := evidence
+	incomplete.Signature = nil
+	c.Assert(errors.Is(VerifyEvidence(incomplete), ErrInvalidEvidence), Equals, true)
+
+	invalidPubKey := evidence
+	invalidPubKey.Pubkey = "whatever"
+	c.Assert(VerifyEvidence(invalidPubKey), NotNil)
+}
+
+func (EvidenceTestSuite) TestUnmarshalEvidences(c *C) {
+	node, _ := createSignedNode(c, "testMsgID", bulkMessage(c, &bkg.KGRound2Message1{Share: []byte("invalid share")}))
+	evidence := NewEvidence("testMsgID", messages.KEYGEN2aUnicast, TssBrokenMsg, node)
+	buf, err := json.Marshal(evidence)
+	c.Assert(err, IsNil)
+	evidences, err := UnmarshalEvidences(buf)
+	c.Assert(err, IsNil)
+	c.Assert(evidences, DeepEquals, []Evidence{evidence})
+
+	buf, err = json.Marshal([]Evidence{evidence, evidence})
+	c.Assert(err, IsNil)
+	evidences, err = UnmarshalEvidences(append([]byte("\n "), buf...))
+	c.Assert(err, IsNil)
+	c.Assert(evidences, HasLen, 2)
+	c.Assert(VerifyEvidence(evidences[1]), IsNil)
+
+	_, err = UnmarshalEvidences([]byte("whatever"))
+	c.Assert(err, NotNil)
+}
+
+func (EvidenceTestSuite) TestManagerEvidences(c *C) {
+	mgr := NewBlameManager()
+	c.Assert(mgr.GetEvidences(), HasLen, 0)
+	node, _ := createSignedNode(c, "testMsgID", []byte("invalid share"))
+	mgr.AddEvidence(NewEvidence("testMsgID", "KGR2", HashCheckFail, node))
+	evidences := mgr.GetEvidences()
+	c.Assert(evidences, HasLen, 1)
+	c.Assert(evidences[0].Reason, Equals, HashCheckFail)
+}
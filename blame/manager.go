@@ -19,9 +19,12 @@ type Manager struct {
 	PartyIDtoP2PID    map[string]peer.ID
 	lastMsgLocker     *sync.RWMutex
 	lastMsg           btss.Message
+	failedRound       string
 	acceptedShares    map[RoundInfo][]string
 	acceptShareLocker *sync.Mutex
 	localPartyID      string
+	evidences         []Evidence
+	evidenceLocker    *sync.Mutex
 }
 
 func NewBlameManager() *Manager {
@@ -37,6 +40,7 @@ func NewBlameManager() *Manager {
 		lastMsgLocker:     &sync.RWMutex{},
 		acceptedShares:    make(map[RoundInfo][]string),
 		acceptShareLocker: &sync.Mutex{},
+		evidenceLocker:    &sync.Mutex{},
 	}
 }
 
@@ -44,6 +48,22 @@ func (m *Manager) GetBlame() *Blame {
 	return m.blame
 }
 
+// AddEvidence keeps the evidence of the blame nodes that sent us the invalid signed message
+func (m *Manager) AddEvidence(evidences ...Evidence) {
+	m.evidenceLocker.Lock()
+	defer m.evidenceLocker.Unlock()
+	m.evidences = append(m.evidences, evidences...)
+}
+
+// GetEvidences returns the evidences we collected in this tss session
+func (m *Manager) GetEvidences() []Evidence {
+	m.evidenceLocker.Lock()
+	defer m.evidenceLocker.Unlock()
+	ret := make([]Evidence, len(m.evidences))
+	copy(ret, m.evidences)
+	return ret
+}
+
 func (m *Manager) GetShareMgr() *ShareMgr {
 	return m.shareMgr
 }
@@ -91,6 +111,28 @@ func (m *Manager) GetLastMsg() btss.Message {
 	return m.lastMsg
 }
 
+// SetFailedRound records the round the tss session fails in
+func (m *Manager) SetFailedRound(round string) {
+	m.lastMsgLocker.Lock()
+	defer m.lastMsgLocker.Unlock()
+	m.failedRound = round
+}
+
+// GetFailedRound returns the round the tss session fails in, it is the round of the first evidence if no round
+// is recorded
+func (m *Manager) GetFailedRound() string {
+	m.lastMsgLocker.RLock()
+	round := m.failedRound
+	m.lastMsgLocker.RUnlock()
+	if len(round) != 0 {
+		return round
+	}
+	if evidences := m.GetEvidences(); len(evidences) != 0 {
+		return evidences[0].Round
+	}
+	return ""
+}
+
 func (m *Manager) SetPartyInfo(partyMap *sync.Map, partyIDMap map[string]*btss.PartyID) {
 	partyInfo := &PartyInfo{
 		PartyMap:   partyMap,
@@ -3,6 +3,7 @@ package blame
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	btss "github.com/binance-chain/tss-lib/tss"
 	mapset "github.com/deckarep/golang-set"
@@ -196,3 +197,48 @@ func (m *Manager) TssMissingShareBlame(rounds int) ([]Node, bool, error) {
 	}
 	return blameNodes, isUnicast, nil
 }
+
+// RoundMissingBlame blames the parties that have not sent us their shares of the first incomplete round, rounds
+// are the messages of the rounds up to the one we are in. It returns the incomplete round and the missing nodes, the
+// caller caps the number of the missing nodes by the threshold as the other blame policies.
+func (m *Manager) RoundMissingBlame(rounds []string) (RoundInfo, []Node, error) {
+	var msgIdentifiers []string
+	m.partyInfo.PartyMap.Range(func(key, _ interface{}) bool {
+		msgIdentifiers = append(msgIdentifiers, key.(string))
+		return true
+	})
+	m.acceptShareLocker.Lock()
+	defer m.acceptShareLocker.Unlock()
+	for index, roundMsg := range rounds {
+		missing := mapset.NewSet()
+		for _, msgIdentifier := range msgIdentifiers {
+			accepted := mapset.NewSet()
+			for _, el := range m.acceptedShares[RoundInfo{index, roundMsg, msgIdentifier}] {
+				accepted.Add(el)
+			}
+			for partyID := range m.partyInfo.PartyIDMap {
+				if partyID != m.localPartyID && !accepted.Contains(partyID) {
+					missing.Add(partyID)
+				}
+			}
+		}
+		if missing.Cardinality() == 0 {
+			continue
+		}
+		var partyIDs []string
+		for _, el := range missing.ToSlice() {
+			partyIDs = append(partyIDs, el.(string))
+		}
+		sort.Strings(partyIDs)
+		blamePubKeys, err := conversion.AccPubKeysFromPartyIDs(partyIDs, m.partyInfo.PartyIDMap)
+		if err != nil {
+			return RoundInfo{}, nil, err
+		}
+		var blameNodes []Node
+		for _, el := range blamePubKeys {
+			blameNodes = append(blameNodes, NewNode(el, nil, nil))
+		}
+		return RoundInfo{Index: index, RoundMsg: roundMsg}, blameNodes, nil
+	}
+	return RoundInfo{}, nil, nil
+}
@@ -141,3 +141,40 @@ func (p *policyTestSuite) TestTssMissingShareBlame(c *C) {
 	sort.Strings(results)
 	c.Assert(results, DeepEquals, localTestPubKeys[2:])
 }
+
+func (p *policyTestSuite) TestRoundMissingBlame(c *C) {
+	localTestPubKeys := testPubKeys[:]
+	sort.Strings(localTestPubKeys)
+	blameMgr := p.blameMgr
+	rounds := []string{"round0", "round1"}
+	// nobody sends us the share of the first round
+	round, nodes, err := blameMgr.RoundMissingBlame(rounds)
+	c.Assert(err, IsNil)
+	c.Assert(round, Equals, RoundInfo{Index: 0, RoundMsg: "round0"})
+	c.Assert(nodes, HasLen, 3)
+
+	blameMgr.acceptShareLocker.Lock()
+	blameMgr.acceptedShares[RoundInfo{0, "round0", ""}] = []string{"1", "2", "3"}
+	blameMgr.acceptedShares[RoundInfo{1, "round1", ""}] = []string{"1"}
+	blameMgr.acceptShareLocker.Unlock()
+	round, nodes, err = blameMgr.RoundMissingBlame(rounds)
+	c.Assert(err, IsNil)
+	c.Assert(round, Equals, RoundInfo{Index: 1, RoundMsg: "round1"})
+	c.Assert(nodes, HasLen, 2)
+	c.Assert([]string{nodes[0].Pubkey, nodes[1].Pubkey}, DeepEquals, localTestPubKeys[2:])
+
+	// everyone sends us the shares of the rounds we are in
+	_, nodes, err = blameMgr.RoundMissingBlame(rounds[:1])
+	c.Assert(err, IsNil)
+	c.Assert(nodes, HasLen, 0)
+}
+
+func (p *policyTestSuite) TestFailedRound(c *C) {
+	blameMgr := NewBlameManager()
+	c.Assert(blameMgr.GetFailedRound(), Equals, "")
+	blameMgr.AddEvidence(Evidence{Round: "round1"})
+	c.Assert(blameMgr.GetFailedRound(), Equals, "round1")
+	// the round of the timeout does not come with any evidence
+	blameMgr.SetFailedRound("round0")
+	c.Assert(blameMgr.GetFailedRound(), Equals, "round0")
+}
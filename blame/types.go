@@ -12,6 +12,7 @@ const (
 	TssTimeout    = "Tss timeout"
 	TssSyncFail   = "signers fail to sync before keygen/keysign"
 	TssBrokenMsg  = "tss share verification failed"
+	Equivocation  = "different broadcast messages signed for the same round"
 	InternalError = "fail to start the join party "
 )
 
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/tss"
+	"github.com/joltify-finance/tss/tsstest"
+)
+
+// benchNode runs the requests on one TssServer and returns the phases the server observes
+type benchNode interface {
+	keygen(req keygen.Request) (keygen.Response, map[string]time.Duration, error)
+	keysign(req keysign.Request) (keysign.Response, map[string]time.Duration, error)
+}
+
+// cluster is the TssServers under the benchmark
+type cluster interface {
+	nodes() []benchNode
+	pubKeys() []string
+	stop()
+}
+
+// phaseRecorder keeps the phases the server observes for the ongoing request, the requests on the node are run one
+// after another, so the phases belong to the latest request. The phases observed for each message are summed up
+type phaseRecorder struct {
+	lock   *sync.Mutex
+	phases map[string]time.Duration
+}
+
+func newPhaseRecorder() *phaseRecorder {
+	return &phaseRecorder{
+		lock:   &sync.Mutex{},
+		phases: make(map[string]time.Duration),
+	}
+}
+
+func (p *phaseRecorder) observe(phase string, elapsed time.Duration, success bool) {
+	if !success {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.phases[phase] += elapsed
+}
+
+// take returns the phases recorded since the last take
+func (p *phaseRecorder) take() map[string]time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	phases := p.phases
+	p.phases = make(map[string]time.Duration)
+	return phases
+}
+
+// serverNode is the TssServer in this process
+type serverNode struct {
+	server   *tss.TssServer
+	recorder *phaseRecorder
+}
+
+func newServerNode(server *tss.TssServer) *serverNode {
+	recorder := newPhaseRecorder()
+	server.SetPhaseObserver(recorder.observe)
+	return &serverNode{
+		server:   server,
+		recorder: recorder,
+	}
+}
+
+func (s *serverNode) keygen(req keygen.Request) (keygen.Response, map[string]time.Duration, error) {
+	s.recorder.take()
+	resp, err := s.server.Keygen(req)
+	return resp, s.recorder.take(), err
+}
+
+func (s *serverNode) keysign(req keysign.Request) (keysign.Response, map[string]time.Duration, error) {
+	s.recorder.take()
+	resp, err := s.server.KeySign(req)
+	return resp, s.recorder.take(), err
+}
+
+// inProcessCluster runs the TssServers in this process on the libp2p mock network
+type inProcessCluster struct {
+	network *tsstest.Network
+	servers []benchNode
+}
+
+func newInProcessCluster(cfg benchConfig, preParams []*bkeygen.LocalPreParams) (*inProcessCluster, error) {
+	network, err := tsstest.NewNetwork(tsstest.Config{
+		Nodes:     cfg.nodes,
+		Seed:      cfg.seed,
+		TssConfig: cfg.tssConfig(),
+		PreParams: preParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.latency > 0 {
+		network.AddRule(tsstest.Rule{From: tsstest.AnyNode, To: tsstest.AnyNode, Fault: tsstest.Fault{Delay: cfg.latency}})
+	}
+	c := &inProcessCluster{network: network}
+	for _, el := range network.Nodes() {
+		c.servers = append(c.servers, newServerNode(el.Server))
+	}
+	return c, nil
+}
+
+func (c *inProcessCluster) nodes() []benchNode {
+	return c.servers
+}
+
+func (c *inProcessCluster) pubKeys() []string {
+	return c.network.PubKeys()
+}
+
+func (c *inProcessCluster) stop() {
+	c.network.Stop()
+}
+
+// nodeResult is the response of the node process to the request
+type nodeResult struct {
+	Keygen  *keygen.Response         `json:"keygen,omitempty"`
+	Keysign *keysign.Response        `json:"keysign,omitempty"`
+	Phases  map[string]time.Duration `json:"phases"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+// processNode is the TssServer in the child process, the requests are sent to its http endpoint
+type processNode struct {
+	client *http.Client
+	addr   string
+}
+
+func (p *processNode) post(path string, req interface{}) (nodeResult, error) {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nodeResult{}, err
+	}
+	resp, err := p.client.Post(fmt.Sprintf("http://%s%s", p.addr, path), "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return nodeResult{}, err
+	}
+	defer resp.Body.Close()
+	var result nodeResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nodeResult{}, fmt.Errorf("fail to decode the response of node(%s): %w", p.addr, err)
+	}
+	if len(result.Error) != 0 {
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
+func (p *processNode) keygen(req keygen.Request) (keygen.Response, map[string]time.Duration, error) {
+	result, err := p.post(keygenPath, req)
+	if result.Keygen == nil {
+		return keygen.Response{Status: common.Fail}, result.Phases, err
+	}
+	return *result.Keygen, result.Phases, err
+}
+
+func (p *processNode) keysign(req keysign.Request) (keysign.Response, map[string]time.Duration, error) {
+	result, err := p.post(keysignPath, req)
+	if result.Keysign == nil {
+		return keysign.Response{Status: common.Fail}, result.Phases, err
+	}
+	return *result.Keysign, result.Phases, err
+}
+
+// ready returns nil once the node serves the requests
+func (p *processNode) ready() error {
+	resp, err := p.client.Get(fmt.Sprintf("http://%s%s", p.addr, readyPath))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("node(%s) is not ready", p.addr)
+	}
+	return nil
+}
+
+// processCluster runs each TssServer in a child process on localhost, the first node is the bootstrap peer of the
+// others
+type processCluster struct {
+	cfg        benchConfig
+	baseFolder string
+	removeBase bool
+	keys       []string
+	children   []*exec.Cmd
+	exited     []chan error
+	servers    []benchNode
+}
+
+func newProcessCluster(cfg benchConfig, preParamsFile string) (*processCluster, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("fail to find the executable: %w", err)
+	}
+	c := &processCluster{
+		cfg:        cfg,
+		baseFolder: cfg.home,
+	}
+	if len(c.baseFolder) == 0 {
+		folder, err := ioutil.TempDir("", "tss-bench")
+		if err != nil {
+			return nil, fmt.Errorf("fail to create the base folder: %w", err)
+		}
+		c.baseFolder = folder
+		c.removeBase = true
+	}
+	var bootstrap string
+	for i := 0; i < cfg.nodes; i++ {
+		key, err := newNodeKey(cfg.seed, i)
+		if err != nil {
+			c.stop()
+			return nil, err
+		}
+		c.keys = append(c.keys, key.pubKey)
+		if i == 0 {
+			bootstrap = fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", cfg.p2pPort, key.peerID)
+		}
+	}
+	start := func(i int) error {
+		home := filepath.Join(c.baseFolder, fmt.Sprintf("node%d", i))
+		if err := os.MkdirAll(home, 0o700); err != nil {
+			return err
+		}
+		logFile, err := os.Create(filepath.Join(home, "node.log"))
+		if err != nil {
+			return err
+		}
+		args := []string{
+			nodeCmd,
+			"-index", strconv.Itoa(i),
+			"-seed", strconv.FormatInt(cfg.seed, 10),
+			"-home", home,
+			"-p2p-port", strconv.Itoa(cfg.p2pPort + i),
+			"-http", c.httpAddr(i),
+			"-latency", cfg.latency.String(),
+			"-party-timeout", cfg.partyTimeout.String(),
+			"-timeout", cfg.timeout.String(),
+			"-loglevel", cfg.logLevel,
+			"-preparams", preParamsFile,
+		}
+		if i != 0 {
+			args = append(args, "-peer", bootstrap)
+		}
+		cmd := exec.Command(executable, args...)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		if err := cmd.Start(); err != nil {
+			logFile.Close()
+			return fmt.Errorf("fail to start node %d: %w", i, err)
+		}
+		exited := make(chan error, 1)
+		go func() {
+			exited <- cmd.Wait()
+			logFile.Close()
+		}()
+		c.children = append(c.children, cmd)
+		c.exited = append(c.exited, exited)
+		c.servers = append(c.servers, &processNode{
+			client: &http.Client{},
+			addr:   c.httpAddr(i),
+		})
+		return nil
+	}
+	// the bootstrap node must be up before the others connect to it
+	if err := start(0); err != nil {
+		c.stop()
+		return nil, err
+	}
+	if err := c.waitReady(0); err != nil {
+		c.stop()
+		return nil, err
+	}
+	for i := 1; i < cfg.nodes; i++ {
+		if err := start(i); err != nil {
+			c.stop()
+			return nil, err
+		}
+	}
+	for i := 1; i < cfg.nodes; i++ {
+		if err := c.waitReady(i); err != nil {
+			c.stop()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *processCluster) httpAddr(i int) string {
+	return fmt.Sprintf("127.0.0.1:%d", c.cfg.httpPort+i)
+}
+
+// waitReady waits for the node to serve the requests, the node may need to generate the pre parameters first
+func (c *processCluster) waitReady(i int) error {
+	node := c.servers[i].(*processNode)
+	deadline := time.Now().Add(c.cfg.startTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-c.exited[i]:
+			c.exited[i] <- err
+			return fmt.Errorf("node %d exited(%v), see %s", i, err, filepath.Join(c.baseFolder, fmt.Sprintf("node%d", i), "node.log"))
+		case <-time.After(200 * time.Millisecond):
+		}
+		if node.ready() == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %d is not ready in %s", i, c.cfg.startTimeout)
+}
+
+func (c *processCluster) nodes() []benchNode {
+	return c.servers
+}
+
+func (c *processCluster) pubKeys() []string {
+	return append([]string{}, c.keys...)
+}
+
+func (c *processCluster) stop() {
+	for i, el := range c.children {
+		if err := el.Process.Signal(os.Interrupt); err != nil {
+			continue
+		}
+		select {
+		case <-c.exited[i]:
+		case <-time.After(10 * time.Second):
+			_ = el.Process.Kill()
+		}
+	}
+	if c.removeBase {
+		_ = os.RemoveAll(c.baseFolder)
+	}
+}
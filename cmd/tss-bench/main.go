@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/messages"
+)
+
+const (
+	modeInProcess = "inproc"
+	modeProcess   = "process"
+)
+
+func usage() {
+	if _, err := fmt.Fprintf(os.Stderr, "usage: tss-bench [-flag=value, ...]\n"); err != nil {
+		panic(err)
+	}
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == nodeCmd {
+		os.Exit(runNode(os.Args[2:]))
+	}
+	var (
+		cfg           benchConfig
+		mode          = flag.String("mode", modeInProcess, "run the nodes in this process on the mock network (inproc) or as child processes on localhost (process)")
+		keygenRuns    = flag.Int("keygen", 1, "the number of keygen runs")
+		keysignRuns   = flag.Int("keysign", 3, "the number of keysign runs of each batch size")
+		batches       = flag.String("batch", "1", "the comma separated numbers of the messages signed in one keysign")
+		preParamsFile = flag.String("preparams", "", "the file of the pre parameters of the nodes, one hex encoded json per line as test_data/preParam_test.data, the nodes without them generate their own")
+	)
+	flag.IntVar(&cfg.nodes, "n", 4, "the number of the nodes")
+	flag.Int64Var(&cfg.seed, "seed", 1, "the seed of the node keys")
+	flag.DurationVar(&cfg.latency, "latency", 0, "the latency emulated on each tss message the nodes send")
+	flag.DurationVar(&cfg.partyTimeout, "party-timeout", 30*time.Second, "join party timeout")
+	flag.DurationVar(&cfg.timeout, "timeout", 2*time.Minute, "keygen and keysign timeout")
+	flag.DurationVar(&cfg.startTimeout, "start-timeout", 10*time.Minute, "how long to wait for the child processes to start")
+	flag.IntVar(&cfg.p2pPort, "p2p-port", 17668, "the p2p port of the first child process, the others use the following ports")
+	flag.IntVar(&cfg.httpPort, "http-port", 18080, "the http port of the first child process, the others use the following ports")
+	flag.StringVar(&cfg.home, "home", "", "the folder of the state and logs of the child processes, a temporary folder is used and removed if not set")
+	flag.StringVar(&cfg.logLevel, "loglevel", "error", "the log level of the nodes")
+	flag.Usage = usage
+	flag.Parse()
+
+	batchSizes, err := parseBatches(*batches)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.nodes < 2 || *keygenRuns < 1 || *keysignRuns < 0 {
+		fmt.Println("Error: n must be greater than 1, keygen must be greater than 0, keysign must not be negative.")
+		os.Exit(1)
+	}
+	if *mode != modeInProcess && *mode != modeProcess {
+		fmt.Printf("Error: unknown mode %s\n", *mode)
+		os.Exit(1)
+	}
+	_ = golog.SetLogLevel("tss-lib", cfg.logLevel)
+	common.InitLog(cfg.logLevel, false, "tss-bench")
+
+	fmt.Println("ECDSA/GG20 Benchmark Tool - Networked")
+	fmt.Println("-----------------------------------")
+	fmt.Printf("Nodes: %d, mode: %s\n", cfg.nodes, *mode)
+	fmt.Printf("Keygen runs: %d, keysign runs: %d of the batch sizes %v\n", *keygenRuns, *keysignRuns, batchSizes)
+	fmt.Printf("Network latency per message: %s\n", cfg.latency)
+	fmt.Println("-----------------------------------")
+
+	var c cluster
+	if *mode == modeInProcess {
+		preParams, errLoad := loadPreParams(*preParamsFile)
+		if errLoad != nil {
+			fmt.Printf("Error: %v\n", errLoad)
+			os.Exit(1)
+		}
+		c, err = newInProcessCluster(cfg, preParams)
+	} else {
+		c, err = newProcessCluster(cfg, *preParamsFile)
+	}
+	if err != nil {
+		fmt.Printf("Error: fail to start the nodes: %v\n", err)
+		os.Exit(1)
+	}
+	rep := newReport()
+	runErr := runWorkloads(c, rep, *keygenRuns, *keysignRuns, batchSizes)
+	c.stop()
+
+	fmt.Println("Results summary:")
+	rep.render(os.Stdout)
+	if runErr != nil {
+		fmt.Printf("Error: %v\n", runErr)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func parseBatches(value string) ([]int, error) {
+	var batchSizes []int
+	for _, el := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(el))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid batch size(%s)", el)
+		}
+		batchSizes = append(batchSizes, size)
+	}
+	return batchSizes, nil
+}
+
+// runWorkloads runs the keygen, and then signs with the key of the last successful keygen
+func runWorkloads(c cluster, rep *report, keygenRuns, keysignRuns int, batchSizes []int) error {
+	prt := message.NewPrinter(language.English)
+	pubKeys := c.pubKeys()
+	var blockHeight int64
+	var poolPubKey string
+	for run := 0; run < keygenRuns; run++ {
+		fmt.Printf("Keygen run %d... ", run+1)
+		blockHeight++
+		req := keygen.NewRequest(pubKeys, blockHeight, messages.NEWJOINPARTYVERSION)
+		start := time.Now()
+		samples, pubKey := runKeygen(c.nodes(), req)
+		rep.add("keygen", samples)
+		_, _ = prt.Printf("%d ms.\n", time.Since(start).Milliseconds())
+		if len(pubKey) != 0 {
+			poolPubKey = pubKey
+		}
+	}
+	if len(poolPubKey) == 0 {
+		return fmt.Errorf("no keygen succeeds")
+	}
+	for _, size := range batchSizes {
+		operation := fmt.Sprintf("keysign x%d", size)
+		for run := 0; run < keysignRuns; run++ {
+			fmt.Printf("Keysign run %d of %d messages... ", run+1, size)
+			msgs, err := randomMessages(size)
+			if err != nil {
+				return err
+			}
+			blockHeight++
+			req := keysign.NewRequest(poolPubKey, msgs, blockHeight, pubKeys, messages.NEWJOINPARTYVERSION)
+			start := time.Now()
+			rep.add(operation, runKeysign(c.nodes(), req))
+			_, _ = prt.Printf("%d ms.\n", time.Since(start).Milliseconds())
+		}
+	}
+	return nil
+}
+
+// runKeygen sends the request to all the nodes at the same time, it returns the pool pub key if all of them succeed
+func runKeygen(nodes []benchNode, req keygen.Request) ([]sample, string) {
+	samples := make([]sample, len(nodes))
+	pubKeys := make([]string, len(nodes))
+	var wg sync.WaitGroup
+	for i, el := range nodes {
+		wg.Add(1)
+		go func(i int, node benchNode) {
+			defer wg.Done()
+			localReq := req
+			localReq.Keys = append([]string{}, req.Keys...)
+			start := time.Now()
+			resp, phases, err := node.keygen(localReq)
+			samples[i] = newSample(phases, time.Since(start), err == nil && resp.Status == common.Success)
+			pubKeys[i] = resp.PubKey
+		}(i, el)
+	}
+	wg.Wait()
+	for i, el := range samples {
+		if el.failed || pubKeys[i] != pubKeys[0] {
+			return samples, ""
+		}
+	}
+	return samples, pubKeys[0]
+}
+
+// runKeysign sends the request to all the nodes at the same time
+func runKeysign(nodes []benchNode, req keysign.Request) []sample {
+	samples := make([]sample, len(nodes))
+	var wg sync.WaitGroup
+	for i, el := range nodes {
+		wg.Add(1)
+		go func(i int, node benchNode) {
+			defer wg.Done()
+			localReq := req
+			localReq.Messages = append([]string{}, req.Messages...)
+			localReq.SignerPubKeys = append([]string{}, req.SignerPubKeys...)
+			start := time.Now()
+			resp, phases, err := node.keysign(localReq)
+			samples[i] = newSample(phases, time.Since(start), err == nil && resp.Status == common.Success)
+		}(i, el)
+	}
+	wg.Wait()
+	return samples
+}
+
+func newSample(phases map[string]time.Duration, total time.Duration, success bool) sample {
+	s := sample{
+		phases: make(map[string]time.Duration),
+		failed: !success,
+	}
+	for phase, elapsed := range phases {
+		s.phases[phase] = elapsed
+	}
+	if success {
+		s.phases[phaseTotal] = total
+	}
+	return s
+}
+
+// randomMessages returns the base64 encoded random hashes to sign
+func randomMessages(n int) ([]string, error) {
+	msgs := make([]string, n)
+	for i := range msgs {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		msgs[i] = base64.StdEncoding.EncodeToString(buf)
+	}
+	return msgs, nil
+}
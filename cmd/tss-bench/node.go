@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	maddr "github.com/multiformats/go-multiaddr"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tss"
+	"github.com/joltify-finance/tss/tsstest"
+)
+
+const (
+	nodeCmd     = "node"
+	readyPath   = "/ready"
+	keygenPath  = "/keygen"
+	keysignPath = "/keysign"
+)
+
+// benchConfig is the configuration of the TssServers under the benchmark
+type benchConfig struct {
+	nodes        int
+	seed         int64
+	latency      time.Duration
+	partyTimeout time.Duration
+	timeout      time.Duration
+	startTimeout time.Duration
+	p2pPort      int
+	httpPort     int
+	home         string
+	logLevel     string
+}
+
+func (c benchConfig) tssConfig() common.TssConfig {
+	return common.TssConfig{
+		PartyTimeout:    c.partyTimeout,
+		KeyGenTimeout:   c.timeout,
+		KeySignTimeout:  c.timeout,
+		PreParamTimeout: 5 * time.Minute,
+	}
+}
+
+type nodeKey struct {
+	privKey ed25519.PrivKey
+	pubKey  string
+	peerID  peer.ID
+}
+
+// newNodeKey returns the key of the node derived from the seed, so the parent knows the keys of its children
+func newNodeKey(seed int64, idx int) (nodeKey, error) {
+	privKey := ed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("tss-bench-%d-%d", seed, idx)))
+	pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: privKey.PubKey().Bytes()})
+	if err != nil {
+		return nodeKey{}, err
+	}
+	peerID, err := conversion.GetPeerIDFromPubKey(pubKey)
+	if err != nil {
+		return nodeKey{}, err
+	}
+	return nodeKey{
+		privKey: privKey,
+		pubKey:  pubKey,
+		peerID:  peerID,
+	}, nil
+}
+
+// loadPreParams reads the pre parameters in the format of test_data/preParam_test.data, one hex encoded json per line
+func loadPreParams(filePathName string) ([]*bkeygen.LocalPreParams, error) {
+	if len(filePathName) == 0 {
+		return nil, nil
+	}
+	file, err := os.Open(filePathName)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open the pre parameters: %w", err)
+	}
+	defer file.Close()
+	var preParams []*bkeygen.LocalPreParams
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		buf, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("fail to decode the pre parameters: %w", err)
+		}
+		var preParam bkeygen.LocalPreParams
+		if err := json.Unmarshal(buf, &preParam); err != nil {
+			return nil, fmt.Errorf("fail to unmarshal the pre parameters: %w", err)
+		}
+		preParams = append(preParams, &preParam)
+	}
+	return preParams, scanner.Err()
+}
+
+// runNode runs the TssServer of the child process until it is interrupted
+func runNode(args []string) int {
+	var (
+		cfg       benchConfig
+		index     int
+		httpAddr  string
+		bootstrap string
+		preParams string
+	)
+	fs := flag.NewFlagSet(nodeCmd, flag.ContinueOnError)
+	fs.IntVar(&index, "index", 0, "the index of the node")
+	fs.Int64Var(&cfg.seed, "seed", 1, "the seed of the node keys")
+	fs.StringVar(&cfg.home, "home", "", "the folder of the node state")
+	fs.IntVar(&cfg.p2pPort, "p2p-port", 0, "the p2p port")
+	fs.StringVar(&httpAddr, "http", "", "the address of the http endpoint")
+	fs.StringVar(&bootstrap, "peer", "", "the multiaddress of the bootstrap peer")
+	fs.DurationVar(&cfg.latency, "latency", 0, "the latency added to each message the node sends")
+	fs.DurationVar(&cfg.partyTimeout, "party-timeout", 30*time.Second, "join party timeout")
+	fs.DurationVar(&cfg.timeout, "timeout", 2*time.Minute, "keygen and keysign timeout")
+	fs.StringVar(&cfg.logLevel, "loglevel", "info", "log level")
+	fs.StringVar(&preParams, "preparams", "", "the file of the pre parameters")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	common.InitLog(cfg.logLevel, false, "tss-bench-node")
+	if err := serveNode(cfg, index, httpAddr, bootstrap, preParams); err != nil {
+		fmt.Fprintf(os.Stderr, "node %d: %v\n", index, err)
+		return 1
+	}
+	return 0
+}
+
+// newCommunication creates the communication of the node, the messages it sends are delayed by the latency on the
+// host wrapped by the interceptor
+func newCommunication(cfg benchConfig, key nodeKey, bootstrapPeers []maddr.Multiaddr) (*p2p.Communication, error) {
+	if cfg.latency <= 0 {
+		return p2p.NewCommunication("tss-bench", bootstrapPeers, cfg.p2pPort, "")
+	}
+	sk, err := crypto.UnmarshalEd25519PrivateKey(key.privKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	h, err := libp2p.New(libp2p.Identity(sk), libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", cfg.p2pPort)))
+	if err != nil {
+		return nil, fmt.Errorf("fail to create p2p host: %w", err)
+	}
+	h = tsstest.NewInterceptedHost(h, func(_ peer.ID, msg []byte, send func([]byte) error) error {
+		time.Sleep(cfg.latency)
+		return send(msg)
+	})
+	return p2p.NewCommunicationWithHost("tss-bench", bootstrapPeers, h)
+}
+
+func serveNode(cfg benchConfig, index int, httpAddr, bootstrap, preParamsFile string) error {
+	key, err := newNodeKey(cfg.seed, index)
+	if err != nil {
+		return err
+	}
+	allPreParams, err := loadPreParams(preParamsFile)
+	if err != nil {
+		return err
+	}
+	var preParams *bkeygen.LocalPreParams
+	if index < len(allPreParams) {
+		preParams = allPreParams[index]
+	}
+	var bootstrapPeers []maddr.Multiaddr
+	if len(bootstrap) != 0 {
+		addr, err := maddr.NewMultiaddr(bootstrap)
+		if err != nil {
+			return fmt.Errorf("invalid bootstrap peer: %w", err)
+		}
+		bootstrapPeers = append(bootstrapPeers, addr)
+	}
+	stateManager, err := storage.NewFileStateMgr(cfg.home)
+	if err != nil {
+		return err
+	}
+	comm, err := newCommunication(cfg, key, bootstrapPeers)
+	if err != nil {
+		return err
+	}
+	server, err := tss.NewTssWithCommunication(comm, key.privKey, cfg.home, stateManager, cfg.tssConfig(), preParams)
+	if err != nil {
+		return err
+	}
+	defer server.Stop()
+	node := newServerNode(server)
+	// the node runs one request at a time, so the phases recorded belong to the request
+	lock := &sync.Mutex{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(keygenPath, func(w http.ResponseWriter, r *http.Request) {
+		var req keygen.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeNodeResult(w, nodeResult{Error: err.Error()})
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		resp, phases, err := node.keygen(req)
+		result := nodeResult{Keygen: &resp, Phases: phases}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		writeNodeResult(w, result)
+	})
+	mux.HandleFunc(keysignPath, func(w http.ResponseWriter, r *http.Request) {
+		var req keysign.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeNodeResult(w, nodeResult{Error: err.Error()})
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		resp, phases, err := node.keysign(req)
+		result := nodeResult{Keysign: &resp, Phases: phases}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		writeNodeResult(w, result)
+	})
+	s := &http.Server{
+		Addr:    httpAddr,
+		Handler: mux,
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.ListenAndServe()
+	}()
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-ch:
+	case err := <-errChan:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return s.Close()
+}
+
+func writeNodeResult(w http.ResponseWriter, result nodeResult) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "fail to write the result: %v\n", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/joltify-finance/tss/monitor"
+)
+
+// phaseTotal is the latency of the whole request seen by the caller of the node
+const phaseTotal = "total"
+
+// phaseOrder is the order of the phases in the summary
+var phaseOrder = []string{
+	monitor.PhaseKeygenJoinParty,
+	monitor.PhaseKeygen,
+	monitor.PhaseKeysignJoinParty,
+	monitor.PhaseKeysign,
+	monitor.PhaseHashCheck,
+	monitor.PhaseJSONFraming,
+	monitor.PhaseStreamSetup,
+	phaseTotal,
+}
+
+// sample is what one node observes of one request, the phases only have the ones that succeed
+type sample struct {
+	phases map[string]time.Duration
+	failed bool
+}
+
+// report gathers the samples of the operations
+type report struct {
+	operations []string
+	phases     map[string]map[string][]time.Duration
+	requests   map[string]int
+	failures   map[string]int
+}
+
+func newReport() *report {
+	return &report{
+		phases:   make(map[string]map[string][]time.Duration),
+		requests: make(map[string]int),
+		failures: make(map[string]int),
+	}
+}
+
+func (r *report) add(operation string, samples []sample) {
+	phases, ok := r.phases[operation]
+	if !ok {
+		phases = make(map[string][]time.Duration)
+		r.phases[operation] = phases
+		r.operations = append(r.operations, operation)
+	}
+	for _, el := range samples {
+		r.requests[operation]++
+		if el.failed {
+			r.failures[operation]++
+		}
+		for phase, elapsed := range el.phases {
+			phases[phase] = append(phases[phase], elapsed)
+		}
+	}
+}
+
+// percentile returns the nearest rank percentile of the sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// render writes the percentiles of each phase of the operations as a table
+func (r *report) render(w io.Writer) {
+	prt := message.NewPrinter(language.English)
+	ms := func(d time.Duration) string {
+		return prt.Sprintf("%d ms", d.Milliseconds())
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Operation", "Phase", "Samples", "Failed", "p50", "p90", "p99", "Max"})
+	for _, operation := range r.operations {
+		for _, phase := range phaseOrder {
+			durations, ok := r.phases[operation][phase]
+			if !ok {
+				continue
+			}
+			sorted := append([]time.Duration{}, durations...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			table.Append([]string{
+				operation,
+				phase,
+				prt.Sprintf("%d", len(sorted)),
+				fmt.Sprintf("%d/%d", r.failures[operation], r.requests[operation]),
+				ms(percentile(sorted, 50)),
+				ms(percentile(sorted, 90)),
+				ms(percentile(sorted, 99)),
+				ms(sorted[len(sorted)-1]),
+			})
+		}
+		if len(r.phases[operation]) == 0 {
+			table.Append([]string{operation, "-", "0", fmt.Sprintf("%d/%d", r.failures[operation], r.requests[operation]), "-", "-", "-", "-"})
+		}
+	}
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("|")
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.Render()
+}
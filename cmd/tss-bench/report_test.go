@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/monitor"
+)
+
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type ReportTestSuite struct{}
+
+var _ = Suite(&ReportTestSuite{})
+
+func (ReportTestSuite) TestPercentile(c *C) {
+	c.Assert(percentile(nil, 50), Equals, time.Duration(0))
+	var sorted []time.Duration
+	for i := 1; i <= 10; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	c.Assert(percentile(sorted, 0), Equals, time.Millisecond)
+	c.Assert(percentile(sorted, 50), Equals, 5*time.Millisecond)
+	c.Assert(percentile(sorted, 90), Equals, 9*time.Millisecond)
+	c.Assert(percentile(sorted, 99), Equals, 10*time.Millisecond)
+	c.Assert(percentile(sorted[:1], 99), Equals, time.Millisecond)
+}
+
+func (ReportTestSuite) TestRender(c *C) {
+	rep := newReport()
+	rep.add("keygen", []sample{
+		newSample(map[string]time.Duration{monitor.PhaseKeygenJoinParty: time.Second, monitor.PhaseKeygen: 3 * time.Second}, 4*time.Second, true),
+		newSample(map[string]time.Duration{monitor.PhaseKeygenJoinParty: 2 * time.Second}, 5*time.Second, false),
+	})
+	rep.add("keysign x2", []sample{newSample(nil, time.Second, false)})
+	c.Assert(rep.phases["keygen"][monitor.PhaseKeygenJoinParty], HasLen, 2)
+	c.Assert(rep.phases["keygen"][phaseTotal], DeepEquals, []time.Duration{4 * time.Second})
+	c.Assert(rep.failures["keygen"], Equals, 1)
+
+	var out bytes.Buffer
+	rep.render(&out)
+	c.Assert(out.String(), Matches, "(?s).*keygen +\\| keygen_join_party +\\| 2 +\\| 1/2 +\\| 1,000 ms +\\| 2,000 ms.*")
+	c.Assert(out.String(), Matches, "(?s).*keysign x2 +\\| - +\\| 0 +\\| 1/1.*")
+}
+
+func (ReportTestSuite) TestParseBatches(c *C) {
+	batches, err := parseBatches("1, 4,16")
+	c.Assert(err, IsNil)
+	c.Assert(batches, DeepEquals, []int{1, 4, 16})
+	_, err = parseBatches("1,0")
+	c.Assert(err, NotNil)
+	_, err = parseBatches("a")
+	c.Assert(err, NotNil)
+}
+
+func (ReportTestSuite) TestPhaseRecorder(c *C) {
+	recorder := newPhaseRecorder()
+	recorder.observe(monitor.PhaseKeysignJoinParty, time.Second, true)
+	recorder.observe(monitor.PhaseKeysign, time.Second, false)
+	recorder.observe(monitor.PhaseStreamSetup, time.Millisecond, true)
+	recorder.observe(monitor.PhaseStreamSetup, 2*time.Millisecond, true)
+	c.Assert(recorder.take(), DeepEquals, map[string]time.Duration{
+		monitor.PhaseKeysignJoinParty: time.Second,
+		monitor.PhaseStreamSetup:      3 * time.Millisecond,
+	})
+	c.Assert(recorder.take(), HasLen, 0)
+}
+
+func (ReportTestSuite) TestLoadPreParams(c *C) {
+	preParams, err := loadPreParams("../../test_data/preParam_test.data")
+	c.Assert(err, IsNil)
+	c.Assert(preParams, HasLen, 4)
+	for _, el := range preParams {
+		c.Assert(el.Validate(), Equals, true)
+	}
+	preParams, err = loadPreParams("")
+	c.Assert(err, IsNil)
+	c.Assert(preParams, IsNil)
+	_, err = loadPreParams("not_exist")
+	c.Assert(err, NotNil)
+}
@@ -14,8 +14,8 @@ import (
 	"github.com/binance-chain/tss-lib/ecdsa/keygen"
 	"github.com/btcsuite/btcd/btcec/v2"
 	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
-	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+
+	"github.com/joltify-finance/tss/conversion"
 )
 
 type (
@@ -43,17 +43,9 @@ func getTssSecretFile(file string) (KeygenLocalState, error) {
 	return localState, nil
 }
 
-func setupBech32Prefix() {
-	config := sdk.GetConfig()
-	// thorchain will import go-tss as a library , thus this is not needed, we copy the prefix here to avoid go-tss to import thorchain
-	config.SetBech32PrefixForAccount("thor", "thorpub")
-	config.SetBech32PrefixForValidator("thorv", "thorvpub")
-	config.SetBech32PrefixForConsensusNode("thorc", "thorcpub")
-}
-
-func getTssPubKey(x, y *big.Int) (string, sdk.AccAddress, error) {
+func getTssPubKey(codec conversion.PubKeyCodec, x, y *big.Int) (string, string, error) {
 	if x == nil || y == nil {
-		return "", sdk.AccAddress{}, errors.New("invalid points")
+		return "", "", errors.New("invalid points")
 	}
 	bx, by := new(btcec.FieldVal), new(btcec.FieldVal)
 	bx.SetByteSlice(x.Bytes())
@@ -63,8 +55,11 @@ func getTssPubKey(x, y *big.Int) (string, sdk.AccAddress, error) {
 		Key: tssPubKey.SerializeCompressed(),
 	}
 
-	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pubKeyCompressed)
-	addr := sdk.AccAddress(pubKeyCompressed.Address().Bytes())
+	pubKey, err := codec.EncodePubKey(&pubKeyCompressed)
+	if err != nil {
+		return "", "", err
+	}
+	addr, err := codec.EncodeAddress(pubKeyCompressed.Address())
 	return pubKey, addr, err
 }
 
@@ -8,7 +8,10 @@ import (
 	"os"
 
 	"github.com/binance-chain/tss-lib/crypto/vss"
+	"github.com/btcsuite/btcd/btcec/v2"
 	. "github.com/decred/dcrd/dcrec/secp256k1"
+
+	"github.com/joltify-finance/tss/conversion"
 )
 
 func main() {
@@ -16,10 +19,15 @@ func main() {
 	threshold := n - 1
 	export := flag.String("export", "", "path to export keyfile")
 	password := flag.String("password", "", "encryption password for keyfile")
+	prefix := flag.String("prefix", "thor", "bech32 account prefix of the recovered pub key and address")
 	flag.Parse()
 	files := flag.Args()
 
-	setupBech32Prefix()
+	codec, err := conversion.NewPubKeyCodec(conversion.PubKeyEncodingBech32, *prefix)
+	if err != nil {
+		fmt.Printf("---%v\n", err)
+		return
+	}
 	allSecret := make([]KeygenLocalState, len(files))
 	for i, f := range files {
 		tssSecret, err := getTssSecretFile(f)
@@ -48,13 +56,26 @@ func main() {
 	privKey := NewPrivateKey(tssPrivateKey)
 
 	pk := privKey.PubKey()
-	thorchainpk, address, err := getTssPubKey(pk.X, pk.Y)
+	thorchainpk, address, err := getTssPubKey(codec, pk.X, pk.Y)
 	if err != nil {
 		fmt.Printf("--->%v", err)
 	}
 	fmt.Printf("---recoverd sk:%v\n", privKey)
 	fmt.Printf("---recoverd pk:%v\n", thorchainpk)
 	fmt.Printf("-------%v\n", address)
+	btcecPk, err := btcec.ParsePubKey(pk.SerializeCompressed())
+	if err != nil {
+		fmt.Printf("--->%v\n", err)
+		return
+	}
+	addresses, err := codec.DeriveAddresses(btcecPk)
+	if err != nil {
+		fmt.Printf("--->%v\n", err)
+		return
+	}
+	for name, addr := range addresses {
+		fmt.Printf("---%s address:%v\n", name, addr)
+	}
 
 	if len(*export) > 0 && len(*password) >= 8 {
 		keyfile, err := exportKeyStore(privKey.Serialize(), *password)
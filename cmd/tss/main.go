@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,15 +21,22 @@ import (
 	"github.com/joltify-finance/tss/common"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/tracing"
 	"github.com/joltify-finance/tss/tss"
 )
 
+// version is the version of the binary, set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 var (
 	help       bool
 	logLevel   string
 	pretty     bool
 	baseFolder string
 	tssAddr    string
+	// the OTLP collector the spans are sent to, the tracing is disabled if it is not set
+	otlpEndpoint string
+	otlpInsecure bool
 )
 
 type CosPrivKey struct {
@@ -43,6 +52,15 @@ type CosPrivKey struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == verifyEvidenceCmd {
+		os.Exit(verifyEvidence(os.Args[2:], os.Stdin, os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == replayTranscriptCmd {
+		os.Exit(replayTranscript(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == verifyAuditCmd {
+		os.Exit(verifyAudit(os.Args[2:], os.Stdout))
+	}
 	// Parse the cli into configuration structs
 	tssConf, p2pConf := parseFlags()
 	if help {
@@ -53,9 +71,18 @@ func main() {
 	golog.SetAllLoggers(golog.LevelInfo)
 	_ = golog.SetLogLevel("tss-lib", "INFO")
 	common.InitLog(logLevel, pretty, "tss_service")
+	if len(otlpEndpoint) != 0 {
+		shutdown, err := tracing.Init(context.Background(), otlpEndpoint, "tss", otlpInsecure)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
 
-	// Setup Bech32 Prefixes
-	conversion.SetupBech32Prefix()
 	// this is only need for the binance library
 	if os.Getenv("NET") == "testnet" || os.Getenv("NET") == "mocknet" {
 		types.Network = types.TestNetwork
@@ -118,10 +145,16 @@ func parseFlags() (tssConf common.TssConfig, p2pConf p2p.Config) {
 	flag.StringVar(&logLevel, "loglevel", "info", "Log Level")
 	flag.BoolVar(&pretty, "pretty-log", false, "Enables unstructured prettified logging. This is useful for local debugging")
 	flag.StringVar(&baseFolder, "home", "", "home folder to store the keygen state file")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "the host:port of the OTLP grpc collector the ceremony traces are sent to, tracing is disabled if not set")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "connect to the OTLP collector without TLS")
 
 	// we setup the Tss parameter configuration
 	flag.DurationVar(&tssConf.KeyGenTimeout, "gentimeout", 30*time.Second, "keygen timeout")
 	flag.DurationVar(&tssConf.KeySignTimeout, "signtimeout", 30*time.Second, "keysign timeout")
+	flag.DurationVar(&tssConf.KeyGenRoundTimeout, "genroundtimeout", 0, "keygen timeout of each round, disabled if not set")
+	flag.DurationVar(&tssConf.KeySignRoundTimeout, "signroundtimeout", 0, "keysign timeout of each round, disabled if not set")
+	flag.DurationVar(&tssConf.KeyGenDeadline, "gendeadline", 0, "the deadline of the whole keygen, disabled if not set")
+	flag.DurationVar(&tssConf.KeySignDeadline, "signdeadline", 0, "the deadline of the whole keysign, disabled if not set")
 	flag.DurationVar(&tssConf.PreParamTimeout, "preparamtimeout", 5*time.Minute, "pre-parameter generation timeout")
 	flag.BoolVar(&tssConf.EnableMonitor, "enablemonitor", true, "enable the tss monitor")
 
@@ -131,6 +164,35 @@ func parseFlags() (tssConf common.TssConfig, p2pConf p2p.Config) {
 	flag.IntVar(&p2pConf.Port, "p2p-port", 6668, "listening port local")
 	flag.StringVar(&p2pConf.ExternalIP, "external-ip", "", "external IP of this node")
 	flag.Var(&p2pConf.BootstrapPeers, "peer", "Adds a peer multiaddress to the bootstrap list")
+	flag.BoolVar(&tssConf.EnableMDNS, "mdns", false, "discover the peers with the same rendezvous in the local network, for development only")
+	flag.BoolVar(&tssConf.EnableRelay, "enable-relay", false, "enable the circuit relay and hole punching for the node behind NAT")
+	flag.BoolVar(&tssConf.RelayService, "relay-service", false, "act as a circuit relay for the other committee members")
+	flag.Var(&p2pConf.RelayPeers, "relay-peer", "Adds a relay multiaddress to the static relay list")
+	flag.BoolVar(&tssConf.BehindNAT, "behind-nat", false, "reserve the slots on the relay peers right away rather than wait for AutoNAT to find out the node is not reachable")
+	flag.BoolVar(&tssConf.EnableBlameAgreement, "blame-agreement", false, "exchange the blame with the other parties after the failed ceremony and only blame the nodes the parties agree on")
+	flag.DurationVar(&tssConf.BlameAgreementTimeout, "blame-agreement-timeout", 10*time.Second, "how long do we wait for the blame of the other parties")
+	flag.IntVar(&tssConf.BlameHistoryWindow, "blame-history-window", 100, "how many recent ceremonies of a peer are used to compute its score")
+	flag.BoolVar(&tssConf.DeprioritizeFailingPeers, "deprioritize-failing-peers", false, "form the party without the chronically failing peers if the others are enough")
+	flag.Float64Var(&tssConf.MinPeerScore, "min-peer-score", 0.5, "the score below which the peer is regarded as chronically failing")
+	flag.StringVar(&tssConf.LeaderSelector, "leader-selector", p2p.HashLeaderSelectorName, "the strategy to choose the join party leader: hash, roundrobin or latency. With latency, the node that missed the party whose RTTs the others order by may pick another leader, and the party waits up to the party timeout for the failover")
+	flag.StringVar(&tssConf.PubKeyEncoding, "pubkey-encoding", conversion.PubKeyEncodingBech32, "the encoding of the pub keys on the API: bech32, compressed or uncompressed")
+	flag.StringVar(&tssConf.Bech32Prefix, "bech32-prefix", conversion.DefaultBech32Prefix, "the bech32 account prefix of the pool address and pub keys")
+	flag.BoolVar(&tssConf.EnableTranscript, "transcript", false, "record the signed transcript of the messages of each ceremony for the offline replay")
+	flag.BoolVar(&tssConf.EnableAudit, "audit", false, "write the hash-chained audit log of the keygen, keysign and presign requests and the admin actions, verify it with the verify-audit command")
+	flag.Int64Var(&tssConf.AuditMaxFileSize, "audit-max-size", 64<<20, "the size in bytes of the audit log file above which the log is rotated")
+	flag.IntVar(&tssConf.AuditMaxFiles, "audit-max-files", 0, "how many rotated audit log files we keep, 0 keeps all of them")
+	flag.Func("address-prefix", "Adds the bech32 address of the cosmos chain to the pool addresses, in the form of chain=hrp", func(value string) error {
+		name, hrp, ok := strings.Cut(value, "=")
+		if !ok || len(name) == 0 || len(hrp) == 0 {
+			return fmt.Errorf("invalid address prefix(%s)", value)
+		}
+		if tssConf.AddressPrefixes == nil {
+			tssConf.AddressPrefixes = make(map[string]string)
+		}
+		tssConf.AddressPrefixes[name] = hrp
+		return nil
+	})
 	flag.Parse()
+	tssConf.RelayPeers = p2pConf.RelayPeers
 	return
 }
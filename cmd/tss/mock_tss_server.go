@@ -1,19 +1,25 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/common"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keygen"
 	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tss"
 )
 
 type MockTssServer struct {
 	failToStart   bool
 	failToKeyGen  bool
 	failToKeySign bool
+	failToHistory bool
+	notReady      bool
 }
 
 func (mts *MockTssServer) Start() error {
@@ -44,3 +50,70 @@ func (mts *MockTssServer) KeySign(req keysign.Request) (keysign.Response, error)
 	newSig := keysign.NewSignature("", "", "", "")
 	return keysign.NewResponse([]keysign.Signature{newSig}, common.Success, blame.Blame{}), nil
 }
+
+func (mts *MockTssServer) Presign(req keysign.PresignRequest) (keysign.PresignResponse, error) {
+	if mts.failToKeySign {
+		return keysign.PresignResponse{}, errors.New("you ask for it")
+	}
+	return keysign.PresignResponse{Count: req.Count, PoolDepth: req.Count, Status: common.Success}, nil
+}
+
+func (mts *MockTssServer) KeygenWithContext(_ context.Context, req keygen.Request) (keygen.Response, error) {
+	return mts.Keygen(req)
+}
+
+func (mts *MockTssServer) KeySignWithContext(_ context.Context, req keysign.Request) (keysign.Response, error) {
+	return mts.KeySign(req)
+}
+
+func (mts *MockTssServer) PresignWithContext(_ context.Context, req keysign.PresignRequest) (keysign.PresignResponse, error) {
+	return mts.Presign(req)
+}
+
+func (mts *MockTssServer) GetBlameHistory() ([]storage.CeremonyRecord, error) {
+	if mts.failToHistory {
+		return nil, errors.New("you ask for it")
+	}
+	return []storage.CeremonyRecord{
+		{MsgID: "msg1", Type: "keygen", Participants: []string{"A", "B"}, Success: true},
+		{MsgID: "msg2", Type: "keysign", Participants: []string{"A", "B"}, FailReason: blame.TssTimeout, BlameNodes: []string{"B"}},
+	}, nil
+}
+
+func (mts *MockTssServer) GetPeerScores() ([]storage.PeerScore, error) {
+	if mts.failToHistory {
+		return nil, errors.New("you ask for it")
+	}
+	records, _ := mts.GetBlameHistory()
+	return storage.ComputePeerScores(records, 0), nil
+}
+
+func (mts *MockTssServer) GetAddresses(poolPubKey string) (map[string]string, error) {
+	return conversion.GetAddresses(poolPubKey)
+}
+
+func (mts *MockTssServer) GetBlameHistoryWithContext(_ context.Context) ([]storage.CeremonyRecord, error) {
+	return mts.GetBlameHistory()
+}
+
+func (mts *MockTssServer) GetPeerScoresWithContext(_ context.Context) ([]storage.PeerScore, error) {
+	return mts.GetPeerScores()
+}
+
+func (mts *MockTssServer) GetAddressesWithContext(_ context.Context, poolPubKey string) (map[string]string, error) {
+	return mts.GetAddresses(poolPubKey)
+}
+
+func (mts *MockTssServer) Readiness() tss.Readiness {
+	if mts.notReady {
+		return tss.Readiness{Checks: []tss.HealthCheck{{Name: tss.CheckCommittee, Detail: "you ask for it"}}}
+	}
+	return tss.Readiness{Ready: true, Checks: []tss.HealthCheck{{Name: tss.CheckCommittee, Healthy: true}}}
+}
+
+func (mts *MockTssServer) Status() tss.Status {
+	return tss.Status{
+		PeerID:     "peer",
+		Ceremonies: []tss.CeremonyStatus{{MsgID: "msg1", Type: "keysign", Phase: "join_party", Elapsed: time.Second}},
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/transcript"
+)
+
+const replayTranscriptCmd = "replay-transcript"
+
+// replayTranscript verifies the recorded transcripts and replays the messages we received to reproduce the hash
+// check and blame decisions, it returns the exit code of the command
+func replayTranscript(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet(replayTranscriptCmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintf(out, "Usage: tss %s transcript.json [transcript.json ...]\n", replayTranscriptCmd)
+		fmt.Fprintln(out, "replay the ceremony transcripts and print the hash check and blame decisions")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	failed := 0
+	for _, f := range fs.Args() {
+		t, err := transcript.Load(f)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", f, err)
+			failed++
+			continue
+		}
+		result, err := common.ReplayTranscript(t, common.TssConfig{})
+		if err != nil {
+			fmt.Fprintf(out, "%s: fail to replay the transcript: %v\n", f, err)
+			failed++
+			continue
+		}
+		buf, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(out, "%s: fail to marshal the result: %v\n", f, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "%s:\n%s\n", f, buf)
+	}
+	if failed != 0 {
+		return 1
+	}
+	return 0
+}
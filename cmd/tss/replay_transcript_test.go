@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/transcript"
+)
+
+type ReplayTranscriptTestSuite struct{}
+
+var _ = Suite(&ReplayTranscriptTestSuite{})
+
+func (ReplayTranscriptTestSuite) TestReplayTranscript(c *C) {
+	var parties []string
+	for i := 0; i < 4; i++ {
+		pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: ed25519.GenPrivKey().PubKey().Bytes()})
+		c.Assert(err, IsNil)
+		parties = append(parties, pubKey)
+	}
+	sk := ed25519.GenPrivKey()
+	localPubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: sk.PubKey().Bytes()})
+	c.Assert(err, IsNil)
+	parties = append(parties, localPubKey)
+	localPeerID, err := conversion.GetPeerIDFromPubKey(localPubKey)
+	c.Assert(err, IsNil)
+	remotePeerID, err := conversion.GetPeerIDFromPubKey(parties[0])
+	c.Assert(err, IsNil)
+
+	folder := c.MkDir()
+	r, err := transcript.NewRecorder(folder, localPeerID.String(), sk)
+	c.Assert(err, IsNil)
+	r.Start("testMsgID")
+	r.SetParties("testMsgID", parties)
+	payload, err := json.Marshal(messages.TssTaskNotifier{TaskDone: true})
+	c.Assert(err, IsNil)
+	r.Record(transcript.DirectionIn, []string{remotePeerID.String()}, messages.WrappedMessage{
+		MessageType: messages.TSSTaskDone,
+		MsgID:       "testMsgID",
+		Payload:     payload,
+	})
+	validFile, err := r.Finish("testMsgID")
+	c.Assert(err, IsNil)
+
+	var out bytes.Buffer
+	c.Assert(replayTranscript([]string{validFile}, &out), Equals, 0)
+	c.Assert(out.String(), Matches, "(?s).*\"processed\": 1.*")
+
+	buf, err := os.ReadFile(validFile)
+	c.Assert(err, IsNil)
+	invalidFile := filepath.Join(folder, "invalid.json")
+	c.Assert(os.WriteFile(invalidFile, bytes.Replace(buf, []byte("testMsgID"), []byte("anotherMsgID"), 1), 0o600), IsNil)
+	out.Reset()
+	c.Assert(replayTranscript([]string{validFile, invalidFile}, &out), Equals, 1)
+	c.Assert(out.String(), Matches, "(?s).*invalid signature of the transcript.*")
+
+	out.Reset()
+	c.Assert(replayTranscript([]string{filepath.Join(folder, "notexist.json")}, &out), Equals, 1)
+	c.Assert(replayTranscript(nil, &out), Equals, 2)
+	c.Assert(replayTranscript([]string{"-whatever"}, &out), Equals, 2)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -13,8 +14,11 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/joltify-finance/tss/audit"
 	"github.com/joltify-finance/tss/keygen"
 	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
 	"github.com/joltify-finance/tss/tss"
 )
 
@@ -42,12 +46,20 @@ func NewTssHttpServer(tssAddr string, t tss.Server) *TssHttpServer {
 // NewHandler registers the API routes and returns a new HTTP handler
 func (t *TssHttpServer) tssNewHandler() http.Handler {
 	router := mux.NewRouter()
-	router.Handle("/keygen", http.HandlerFunc(t.keygenHandler)).Methods(http.MethodPost)
-	router.Handle("/keysign", http.HandlerFunc(t.keySignHandler)).Methods(http.MethodPost)
+	router.Handle("/keygen", traced(http.HandlerFunc(t.keygenHandler))).Methods(http.MethodPost)
+	router.Handle("/keysign", traced(http.HandlerFunc(t.keySignHandler))).Methods(http.MethodPost)
+	router.Handle("/presign", traced(http.HandlerFunc(t.presignHandler))).Methods(http.MethodPost)
 	router.Handle("/ping", http.HandlerFunc(t.pingHandler)).Methods(http.MethodGet)
+	router.Handle("/healthz", http.HandlerFunc(t.healthzHandler)).Methods(http.MethodGet)
+	router.Handle("/readyz", http.HandlerFunc(t.readyzHandler)).Methods(http.MethodGet)
+	router.Handle("/status", http.HandlerFunc(t.statusHandler)).Methods(http.MethodGet)
 	router.Handle("/p2pid", http.HandlerFunc(t.getP2pIDHandler)).Methods(http.MethodGet)
+	router.Handle("/blame/history", http.HandlerFunc(t.blameHistoryHandler)).Methods(http.MethodGet)
+	router.Handle("/peers/scores", http.HandlerFunc(t.peerScoresHandler)).Methods(http.MethodGet)
+	router.Handle("/keys/{pubkey}/addresses", http.HandlerFunc(t.addressesHandler)).Methods(http.MethodGet)
 	router.Handle("/metrics", promhttp.Handler())
 	router.Use(logMiddleware())
+	router.Use(callerMiddleware())
 	return router
 }
 
@@ -70,7 +82,7 @@ func (t *TssHttpServer) keygenHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := t.tssServer.Keygen(keygenReq)
+	resp, err := t.tssServer.KeygenWithContext(r.Context(), keygenReq)
 	if err != nil {
 		t.logger.Error().Err(err).Msg("fail to key gen")
 	}
@@ -107,7 +119,7 @@ func (t *TssHttpServer) keySignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	t.logger.Info().Msgf("request:%+v", keySignReq)
-	signResp, err := t.tssServer.KeySign(keySignReq)
+	signResp, err := t.tssServer.KeySignWithContext(r.Context(), keySignReq)
 	if err != nil {
 		t.logger.Error().Err(err).Msg("fail to key sign")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -126,6 +138,31 @@ func (t *TssHttpServer) keySignHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (t *TssHttpServer) presignHandler(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); nil != err {
+			t.logger.Error().Err(err).Msg("fail to close request body")
+		}
+	}()
+	t.logger.Info().Msg("receive presign request")
+
+	var presignReq keysign.PresignRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&presignReq); nil != err {
+		t.logger.Error().Err(err).Msg("fail to decode presign request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	t.logger.Info().Msgf("request:%+v", presignReq)
+	presignResp, err := t.tssServer.PresignWithContext(r.Context(), presignReq)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to presign")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t.writeJSON(w, presignResp)
+}
+
 func (t *TssHttpServer) Start() error {
 	if t.s == nil {
 		return errors.New("invalid http server instance")
@@ -156,6 +193,32 @@ func logMiddleware() mux.MiddlewareFunc {
 	}
 }
 
+// callerMiddleware passes the identity of the client to the tss server, which records it in the audit log
+func callerMiddleware() mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller := audit.Caller{
+				RemoteAddr:   r.RemoteAddr,
+				ForwardedFor: r.Header.Get("X-Forwarded-For"),
+				UserAgent:    r.UserAgent(),
+			}
+			if r.TLS != nil && len(r.TLS.PeerCertificates) != 0 {
+				caller.Identity = r.TLS.PeerCertificates[0].Subject.String()
+			}
+			handler.ServeHTTP(w, r.WithContext(audit.WithCaller(r.Context(), caller)))
+		})
+	}
+}
+
+// traced starts the span of the request in the trace of the caller, the ceremony is traced in it
+func traced(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(tracing.ExtractHeader(r.Context(), r.Header), "http "+r.URL.Path)
+		defer span.End()
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (t *TssHttpServer) Stop() error {
 	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -171,6 +234,28 @@ func (t *TssHttpServer) pingHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// healthzHandler tells the process is alive, it does not check the dependencies of the node
+func (t *TssHttpServer) healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	t.writeJSON(w, map[string]string{"status": "ok"})
+}
+
+// readyzHandler returns the breakdown of the readiness checks, with 503 if the node is not ready to serve
+func (t *TssHttpServer) readyzHandler(w http.ResponseWriter, _ *http.Request) {
+	readiness := t.tssServer.Readiness()
+	if !readiness.Ready {
+		t.writeJSONWithStatus(w, http.StatusServiceUnavailable, readiness)
+		return
+	}
+	t.writeJSON(w, readiness)
+}
+
+// statusHandler returns what the node is connected to and busy with
+func (t *TssHttpServer) statusHandler(w http.ResponseWriter, _ *http.Request) {
+	status := t.tssServer.Status()
+	status.Version = version
+	t.writeJSON(w, status)
+}
+
 func (t *TssHttpServer) getP2pIDHandler(w http.ResponseWriter, _ *http.Request) {
 	localPeerID := t.tssServer.GetLocalPeerID()
 	_, err := w.Write([]byte(localPeerID))
@@ -178,3 +263,70 @@ func (t *TssHttpServer) getP2pIDHandler(w http.ResponseWriter, _ *http.Request)
 		t.logger.Error().Err(err).Msg("fail to write to response")
 	}
 }
+
+// blameHistoryHandler returns the outcome of the ceremonies, the latest first, limit restricts the number of records
+func (t *TssHttpServer) blameHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if val := r.URL.Query().Get("limit"); len(val) != 0 {
+		var err error
+		limit, err = strconv.Atoi(val)
+		if err != nil || limit < 0 {
+			t.logger.Error().Msgf("invalid limit(%s)", val)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	records, err := t.tssServer.GetBlameHistoryWithContext(r.Context())
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the blame history")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	latest := make([]storage.CeremonyRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if limit != 0 && len(latest) == limit {
+			break
+		}
+		latest = append(latest, records[i])
+	}
+	t.writeJSON(w, latest)
+}
+
+func (t *TssHttpServer) peerScoresHandler(w http.ResponseWriter, r *http.Request) {
+	scores, err := t.tssServer.GetPeerScoresWithContext(r.Context())
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the peer scores")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t.writeJSON(w, scores)
+}
+
+// addressesHandler returns the addresses of the pool pub key on all the supported chains
+func (t *TssHttpServer) addressesHandler(w http.ResponseWriter, r *http.Request) {
+	addresses, err := t.tssServer.GetAddressesWithContext(r.Context(), mux.Vars(r)["pubkey"])
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the addresses of the pool pub key")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	t.writeJSON(w, addresses)
+}
+
+func (t *TssHttpServer) writeJSON(w http.ResponseWriter, value interface{}) {
+	t.writeJSONWithStatus(w, http.StatusOK, value)
+}
+
+func (t *TssHttpServer) writeJSONWithStatus(w http.ResponseWriter, statusCode int, value interface{}) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to marshal response to json")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(buf); err != nil {
+		t.logger.Error().Err(err).Msg("fail to write to response")
+	}
+}
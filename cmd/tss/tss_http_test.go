@@ -9,9 +9,15 @@ import (
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	. "gopkg.in/check.v1"
 
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tss"
 )
 
 func TestPackage(t *testing.T) { TestingT(t) }
@@ -38,6 +44,37 @@ func (TssHttpServerTestSuite) TestNewTssHttpServer(c *C) {
 	c.Assert(s.Start(), NotNil)
 }
 
+func (TssHttpServerTestSuite) TestTraced(c *C) {
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	var called bool
+	handler := traced(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		c.Assert(trace.SpanContextFromContext(r.Context()).TraceID().String(), Equals, traceID)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/keysign", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	c.Assert(called, Equals, true)
+}
+
+func (TssHttpServerTestSuite) TestCallerMiddleware(c *C) {
+	var called bool
+	handler := callerMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		c.Assert(audit.CallerFromContext(r.Context()), DeepEquals, audit.Caller{
+			RemoteAddr:   "10.0.0.1:1234",
+			ForwardedFor: "10.0.0.2",
+			UserAgent:    "bridge",
+		})
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/keysign", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.2")
+	req.Header.Set("User-Agent", "bridge")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	c.Assert(called, Equals, true)
+}
+
 func (TssHttpServerTestSuite) TestPingHandler(c *C) {
 	tssServer := &MockTssServer{}
 	s := NewTssHttpServer("127.0.0.1:8080", tssServer)
@@ -48,6 +85,44 @@ func (TssHttpServerTestSuite) TestPingHandler(c *C) {
 	c.Assert(res.Code, Equals, http.StatusOK)
 }
 
+func (TssHttpServerTestSuite) TestHealthHandlers(c *C) {
+	tssServer := &MockTssServer{}
+	handler := NewTssHttpServer("127.0.0.1:8080", tssServer).tssNewHandler()
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var readiness tss.Readiness
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &readiness), IsNil)
+	c.Assert(readiness.Ready, Equals, true)
+
+	tssServer.notReady = true
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	c.Assert(res.Code, Equals, http.StatusServiceUnavailable)
+	c.Assert(res.Header().Get("Content-Type"), Equals, "application/json")
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &readiness), IsNil)
+	c.Assert(readiness.Ready, Equals, false)
+	c.Assert(readiness.Checks[0].Detail, Equals, "you ask for it")
+}
+
+func (TssHttpServerTestSuite) TestStatusHandler(c *C) {
+	handler := NewTssHttpServer("127.0.0.1:8080", &MockTssServer{}).tssNewHandler()
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/status", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var status tss.Status
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &status), IsNil)
+	c.Assert(status.Version, Equals, version)
+	c.Assert(status.PeerID, Equals, "peer")
+	c.Assert(status.Ceremonies, HasLen, 1)
+	c.Assert(status.Ceremonies[0].Phase, Equals, "join_party")
+	c.Assert(status.Ceremonies[0].Elapsed, Equals, time.Second)
+}
+
 func (TssHttpServerTestSuite) TestGetP2pIDHandler(c *C) {
 	tssServer := &MockTssServer{}
 	s := NewTssHttpServer("127.0.0.1:8080", tssServer)
@@ -202,3 +277,103 @@ func (TssHttpServerTestSuite) TestKeysignHandler(c *C) {
 		tc.resultChecker(c, res)
 	}
 }
+
+func (TssHttpServerTestSuite) TestBlameHistoryHandler(c *C) {
+	tssServer := &MockTssServer{}
+	s := NewTssHttpServer("127.0.0.1:8080", tssServer)
+	c.Assert(s, NotNil)
+	handler := s.tssNewHandler()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/blame/history", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var records []storage.CeremonyRecord
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &records), IsNil)
+	c.Assert(records, HasLen, 2)
+	// the latest record comes first
+	c.Assert(records[0].MsgID, Equals, "msg2")
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/blame/history?limit=1", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &records), IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].MsgID, Equals, "msg2")
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/blame/history?limit=abc", nil))
+	c.Assert(res.Code, Equals, http.StatusBadRequest)
+
+	tssServer.failToHistory = true
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/blame/history", nil))
+	c.Assert(res.Code, Equals, http.StatusInternalServerError)
+}
+
+func (TssHttpServerTestSuite) TestPeerScoresHandler(c *C) {
+	tssServer := &MockTssServer{}
+	s := NewTssHttpServer("127.0.0.1:8080", tssServer)
+	c.Assert(s, NotNil)
+	res := httptest.NewRecorder()
+	s.peerScoresHandler(res, httptest.NewRequest(http.MethodGet, "/peers/scores", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var scores []storage.PeerScore
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &scores), IsNil)
+	c.Assert(scores, HasLen, 2)
+	c.Assert(scores[1].Pubkey, Equals, "B")
+	c.Assert(scores[1].Score, Equals, 0.5)
+
+	tssServer.failToHistory = true
+	res = httptest.NewRecorder()
+	s.peerScoresHandler(res, httptest.NewRequest(http.MethodGet, "/peers/scores", nil))
+	c.Assert(res.Code, Equals, http.StatusInternalServerError)
+}
+
+func (TssHttpServerTestSuite) TestPresignHandler(c *C) {
+	tssServer := &MockTssServer{}
+	s := NewTssHttpServer("127.0.0.1:8080", tssServer)
+	c.Assert(s, NotNil)
+	handler := s.tssNewHandler()
+	presignRequest := `{"pool_pub_key": "pool", "signer_pub_keys": ["A", "B", "C"], "count": 5}`
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/presign", bytes.NewBufferString(presignRequest)))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var resp keysign.PresignResponse
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &resp), IsNil)
+	c.Assert(resp.Count, Equals, 5)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/presign", bytes.NewBufferString("invalid")))
+	c.Assert(res.Code, Equals, http.StatusBadRequest)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/presign", nil))
+	c.Assert(res.Code, Equals, http.StatusMethodNotAllowed)
+
+	tssServer.failToKeySign = true
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/presign", bytes.NewBufferString(presignRequest)))
+	c.Assert(res.Code, Equals, http.StatusInternalServerError)
+}
+
+func (TssHttpServerTestSuite) TestAddressesHandler(c *C) {
+	conversion.SetupBech32Prefix()
+	s := NewTssHttpServer("127.0.0.1:8080", &MockTssServer{})
+	handler := s.tssNewHandler()
+	poolPubKey := "oppypub1addwnpepqtmru87hylm9q0tcza8p0vze2zvmqk0wr0933qr472hggzw2tp4pvy3756g"
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/keys/"+poolPubKey+"/addresses", nil))
+	c.Assert(res.Code, Equals, http.StatusOK)
+	var addresses map[string]string
+	c.Assert(json.Unmarshal(res.Body.Bytes(), &addresses), IsNil)
+	expected, err := conversion.GetAddresses(poolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(addresses, DeepEquals, expected)
+	c.Assert(addresses[conversion.AddressEVM], Matches, "0x[0-9a-fA-F]{40}")
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/keys/invalid/addresses", nil))
+	c.Assert(res.Code, Equals, http.StatusBadRequest)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/joltify-finance/tss/audit"
+)
+
+const verifyAuditCmd = "verify-audit"
+
+// verifyAudit checks the hash chain of the audit log in the folder, it returns the exit code of the command
+func verifyAudit(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet(verifyAuditCmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	pubKey := fs.String("pubkey", "", "the pub key of the node, the log must have the checkpoint signed by it")
+	fs.Usage = func() {
+		fmt.Fprintf(out, "Usage: tss %s [-pubkey <node pub key>] <home>/audit\n", verifyAuditCmd)
+		fmt.Fprintln(out, "verify the integrity of the audit log and print the hash of its last entry")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	summary, err := audit.Verify(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(out, "INVALID: %v\n", err)
+		return 1
+	}
+	if len(*pubKey) != 0 && summary.PubKey != *pubKey {
+		fmt.Fprintf(out, "INVALID: the audit log is not checkpointed by %s\n", *pubKey)
+		return 1
+	}
+	buf, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		fmt.Fprintf(out, "fail to marshal the summary: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "VALID:\n%s\n", buf)
+	return 0
+}
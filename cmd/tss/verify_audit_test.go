@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/conversion"
+)
+
+type VerifyAuditTestSuite struct{}
+
+var _ = Suite(&VerifyAuditTestSuite{})
+
+func (VerifyAuditTestSuite) TestVerifyAudit(c *C) {
+	folder := c.MkDir()
+	l, err := audit.NewLog(folder, audit.Config{})
+	c.Assert(err, IsNil)
+	for _, msgID := range []string{"msg1", "msg2"} {
+		entry := audit.NewEntry(context.Background(), audit.ActionKeysign, nil)
+		entry.MsgID = msgID
+		c.Assert(l.Append(entry), IsNil)
+	}
+	c.Assert(l.Close(), IsNil)
+
+	var out bytes.Buffer
+	c.Assert(verifyAudit([]string{folder}, &out), Equals, 0)
+	c.Assert(out.String(), Matches, "(?s)VALID.*\"entries\": 2.*")
+
+	files, err := filepath.Glob(filepath.Join(folder, "audit-*.log"))
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+	buf, err := os.ReadFile(files[0])
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(files[0], bytes.Replace(buf, []byte("msg1"), []byte("msg3"), 1), 0o600), IsNil)
+	out.Reset()
+	c.Assert(verifyAudit([]string{folder}, &out), Equals, 1)
+	c.Assert(out.String(), Matches, "INVALID: .*hash of the entry seq 0 mismatch\n")
+
+	out.Reset()
+	c.Assert(verifyAudit(nil, &out), Equals, 2)
+}
+
+func (VerifyAuditTestSuite) TestVerifyAuditPubKey(c *C) {
+	folder := c.MkDir()
+	sk := ed25519.GenPrivKey()
+	pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: sk.PubKey().Bytes()})
+	c.Assert(err, IsNil)
+	l, err := audit.NewLog(folder, audit.Config{PrivKey: sk})
+	c.Assert(err, IsNil)
+	c.Assert(l.Append(audit.NewEntry(context.Background(), audit.ActionStart, nil)), IsNil)
+	c.Assert(l.Close(), IsNil)
+
+	var out bytes.Buffer
+	c.Assert(verifyAudit([]string{"-pubkey", pubKey, folder}, &out), Equals, 0)
+	// the checkpoint removed along with the log is detected by the pub key of the node
+	c.Assert(os.Remove(filepath.Join(folder, "checkpoint.json")), IsNil)
+	out.Reset()
+	c.Assert(verifyAudit([]string{"-pubkey", pubKey, folder}, &out), Equals, 1)
+	c.Assert(out.String(), Matches, "INVALID: the audit log is not checkpointed by .*\n")
+}
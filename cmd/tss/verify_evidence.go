@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joltify-finance/tss/blame"
+)
+
+const verifyEvidenceCmd = "verify-evidence"
+
+// verifyEvidence validates the blame evidence bundles in the given files (or stdin) without a running node,
+// it returns the exit code of the command
+func verifyEvidence(args []string, stdin io.Reader, out io.Writer) int {
+	fs := flag.NewFlagSet(verifyEvidenceCmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintf(out, "Usage: tss %s [evidence.json ...]\n", verifyEvidenceCmd)
+		fmt.Fprintln(out, "verify the blame evidence bundles, read from stdin if no file is given")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	invalid := 0
+	for _, f := range files {
+		var data []byte
+		var err error
+		if f == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(f)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%s: fail to read the evidence: %v\n", f, err)
+			invalid++
+			continue
+		}
+		evidences, err := blame.UnmarshalEvidences(data)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", f, err)
+			invalid++
+			continue
+		}
+		for i, el := range evidences {
+			if err := blame.VerifyEvidence(el); err != nil {
+				fmt.Fprintf(out, "%s[%d]: INVALID msgID(%s) round(%s) pubkey(%s): %v\n", f, i, el.MsgID, el.Round, el.Pubkey, err)
+				invalid++
+				continue
+			}
+			fmt.Fprintf(out, "%s[%d]: VALID msgID(%s) round(%s) pubkey(%s) reason(%s)\n", f, i, el.MsgID, el.Round, el.Pubkey, el.Reason)
+		}
+	}
+	if invalid != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+)
+
+type VerifyEvidenceTestSuite struct{}
+
+var _ = Suite(&VerifyEvidenceTestSuite{})
+
+func (VerifyEvidenceTestSuite) TestVerifyEvidence(c *C) {
+	conversion.SetupBech32Prefix()
+	sk := ed25519.GenPrivKey()
+	pk := coskey.PubKey{Key: sk.PubKey().Bytes()}
+	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pk)
+	c.Assert(err, IsNil)
+	msg := []byte(`{"presign_ids":["testMsgID-0"],"shares":["AQ=="]}`)
+	sig, err := sk.Sign(append(append([]byte{}, msg...), []byte("testMsgID")...))
+	c.Assert(err, IsNil)
+	evidence := blame.NewEvidence("testMsgID", messages.TSSPresignShare.String(), blame.TssBrokenMsg, blame.NewNode(pubKey, msg, sig))
+	buf, err := json.Marshal(evidence)
+	c.Assert(err, IsNil)
+
+	folder := c.MkDir()
+	validFile := filepath.Join(folder, "valid.json")
+	c.Assert(os.WriteFile(validFile, buf, 0o600), IsNil)
+	var out bytes.Buffer
+	c.Assert(verifyEvidence([]string{validFile}, nil, &out), Equals, 0)
+	c.Assert(out.String(), Matches, "(?s).*VALID msgID\\(testMsgID\\).*")
+
+	// read from stdin
+	out.Reset()
+	c.Assert(verifyEvidence(nil, bytes.NewReader(buf), &out), Equals, 0)
+
+	evidence.MsgID = "anotherMsgID"
+	buf, err = json.Marshal([]blame.Evidence{evidence})
+	c.Assert(err, IsNil)
+	invalidFile := filepath.Join(folder, "invalid.json")
+	c.Assert(os.WriteFile(invalidFile, buf, 0o600), IsNil)
+	out.Reset()
+	c.Assert(verifyEvidence([]string{validFile, invalidFile}, nil, &out), Equals, 1)
+	c.Assert(out.String(), Matches, "(?s).*INVALID msgID\\(anotherMsgID\\).*")
+
+	out.Reset()
+	c.Assert(verifyEvidence([]string{filepath.Join(folder, "notexist.json")}, nil, &out), Equals, 1)
+	c.Assert(verifyEvidence([]string{"-whatever"}, nil, &out), Equals, 2)
+}
@@ -0,0 +1,195 @@
+package common
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	btsskeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	btss "github.com/binance-chain/tss-lib/tss"
+	"github.com/rs/zerolog"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+)
+
+const (
+	fuzzMsgID = "fuzz"
+	// maxSeedSize skips the large seeds, the fuzzing engine crawls on them
+	maxSeedSize = 16 * 1024
+)
+
+// fuzzEnv is the local party of testBlamePubKeys[1], the messages are sent by the party of testBlamePubKeys[0] which
+// has the key testBlamePrivKey
+type fuzzEnv struct {
+	privKey ed25519.PrivKey
+	sender  *btss.PartyID
+	peerID  string
+}
+
+func newFuzzEnv(f *testing.F) *fuzzEnv {
+	conversion.SetupBech32Prefix()
+	buf, err := base64.StdEncoding.DecodeString(testBlamePrivKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	partiesID, _, err := conversion.GetParties(append([]string{}, testBlamePubKeys...), testBlamePubKeys[1])
+	if err != nil {
+		f.Fatal(err)
+	}
+	env := &fuzzEnv{privKey: buf}
+	for _, el := range partiesID {
+		pk, err := conversion.PartyIDtoPubKey(el)
+		if err != nil {
+			f.Fatal(err)
+		}
+		if pk == testBlamePubKeys[0] {
+			env.sender = el
+		}
+	}
+	peerID, err := conversion.GetPeerIDFromPubKey(testBlamePubKeys[0])
+	if err != nil {
+		f.Fatal(err)
+	}
+	env.peerID = peerID.String()
+	return env
+}
+
+// tssCommon returns the TssCommon of the local party, the party info is not set if withPartyInfo is false
+func (e *fuzzEnv) tssCommon(t *testing.T, withPartyInfo bool) *TssCommon {
+	tssCommon := NewTssCommon("", nil, TssConfig{}, fuzzMsgID, e.privKey, 1)
+	tssCommon.logger = zerolog.Nop()
+	if !withPartyInfo {
+		return tssCommon
+	}
+	partiesID, localPartyID, err := conversion.GetParties(append([]string{}, testBlamePubKeys...), testBlamePubKeys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	partyIDMap := conversion.SetupPartyIDMap(partiesID)
+	params := btss.NewParameters(btss.NewPeerContext(partiesID), localPartyID, len(partiesID), 2)
+	keyGenParty := btsskeygen.NewLocalParty(params, make(chan btss.Message, len(partiesID)), make(chan btsskeygen.LocalPartySaveData, len(partiesID)))
+	partyMap := new(sync.Map)
+	partyMap.Store("tester", keyGenParty)
+	tssCommon.SetPartyInfo(&PartyInfo{
+		PartyMap:   partyMap,
+		PartyIDMap: partyIDMap,
+	})
+	tssCommon.blameMgr.SetPartyInfo(partyMap, partyIDMap)
+	if err := conversion.SetupIDMaps(partyIDMap, tssCommon.PartyIDtoP2PID); err != nil {
+		t.Fatal(err)
+	}
+	if err := conversion.SetupIDMaps(partyIDMap, tssCommon.blameMgr.PartyIDtoP2PID); err != nil {
+		t.Fatal(err)
+	}
+	return tssCommon
+}
+
+// wireMessages returns the wire messages of the sender that carry the keygen shares in test_data
+func (e *fuzzEnv) wireMessages(f *testing.F) []*messages.WireMessage {
+	file, err := os.Open("../test_data/tss_keygen_shares/shareskeygen0")
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer file.Close()
+	var ret []*messages.WireMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, p2p.MaxPayload), p2p.MaxPayload)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > maxSeedSize {
+			continue
+		}
+		var share messages.WireMessage
+		if err := json.Unmarshal(scanner.Bytes(), &share); err != nil {
+			f.Fatal(err)
+		}
+		routing := &btss.MessageRouting{
+			From:        e.sender,
+			IsBroadcast: share.Routing.IsBroadcast,
+		}
+		buf, err := json.Marshal([]BulkWireMsg{NewBulkWireMsg(share.Message, "tester", routing)})
+		if err != nil {
+			f.Fatal(err)
+		}
+		sig, err := generateSignature(buf, fuzzMsgID, e.privKey)
+		if err != nil {
+			f.Fatal(err)
+		}
+		ret = append(ret, &messages.WireMessage{
+			Routing:   routing,
+			RoundInfo: share.RoundInfo,
+			Message:   buf,
+			Sig:       sig,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		f.Fatal(err)
+	}
+	return ret
+}
+
+func FuzzProcessOneMessage(f *testing.F) {
+	env := newFuzzEnv(f)
+	for _, el := range env.wireMessages(f) {
+		buf, err := json.Marshal(el)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(uint8(messages.TSSKeyGenMsg), buf, true)
+		f.Add(uint8(messages.TSSKeyGenMsg), buf, false)
+		control, err := json.Marshal(messages.TssControl{RequestType: messages.TSSKeyGenMsg, Msg: el})
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(uint8(messages.TSSControlMsg), control, true)
+	}
+	f.Add(uint8(messages.TSSKeyGenVerMsg), []byte(`{"key":"1-KGRound1Message","hash":"abc"}`), true)
+	f.Add(uint8(messages.TSSKeyGenVerMsg), []byte(`{"key":"1-KGRound1Message","hash":"abc"}`), false)
+	f.Add(uint8(messages.TSSTaskDone), []byte(`{"task_done":true}`), true)
+	f.Add(uint8(messages.TSSTaskDone), []byte(`{"task_done":true}`), false)
+	f.Add(uint8(messages.TSSControlMsg), []byte(`{"reqest_hash":"abc","request_key":"1-KGRound1Message","request_type":0}`), true)
+	f.Add(uint8(messages.TSSKeySignMsg), []byte(`{"routing":{"From":null}}`), false)
+
+	f.Fuzz(func(t *testing.T, msgType uint8, payload []byte, withPartyInfo bool) {
+		tssCommon := env.tssCommon(t, withPartyInfo)
+		_ = tssCommon.ProcessOneMessage(&messages.WrappedMessage{
+			MessageType: messages.THORChainTSSMessageType(msgType),
+			MsgID:       fuzzMsgID,
+			Payload:     payload,
+		}, env.peerID)
+	})
+}
+
+func FuzzUpdateLocal(f *testing.F) {
+	env := newFuzzEnv(f)
+	for _, el := range env.wireMessages(f) {
+		buf, err := json.Marshal(el)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf)
+	}
+	f.Add([]byte(`{"routing":{"From":{"id":"unknown"}}}`))
+	// the bulk message without the routing
+	buf, err := json.Marshal(messages.WireMessage{
+		Routing: &btss.MessageRouting{From: env.sender},
+		Message: []byte(`[{"MsgIdentifier":"tester"}]`),
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var wireMsg messages.WireMessage
+		if err := json.Unmarshal(data, &wireMsg); err != nil {
+			return
+		}
+		_ = env.tssCommon(t, true).updateLocal(&wireMsg)
+	})
+}
@@ -2,16 +2,21 @@ package common
 
 import (
 	"sync"
+	"time"
 
 	"github.com/joltify-finance/tss/messages"
 )
 
 // LocalCacheItem used to cache the unconfirmed broadcast message
 type LocalCacheItem struct {
-	Msg           *messages.WireMessage
-	Hash          string
+	Msg  *messages.WireMessage
+	Hash string
+	// MinorityMsg is the message we received that does not match the majority, it is the evidence of the
+	// equivocation if the owner has signed the one of the majority as well
+	MinorityMsg   *messages.WireMessage
 	lock          *sync.Mutex
 	ConfirmedList map[string]string
+	created       time.Time
 }
 
 func NewLocalCacheItem(msg *messages.WireMessage, hash string) *LocalCacheItem {
@@ -20,6 +25,7 @@ func NewLocalCacheItem(msg *messages.WireMessage, hash string) *LocalCacheItem {
 		Hash:          hash,
 		lock:          &sync.Mutex{},
 		ConfirmedList: make(map[string]string),
+		created:       time.Now(),
 	}
 }
 
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	btss "github.com/binance-chain/tss-lib/tss"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/transcript"
+)
+
+// ReplayError is the recorded message TssCommon rejects in the replay
+type ReplayError struct {
+	Seq         uint64 `json:"seq"`
+	Peer        string `json:"peer"`
+	MessageType string `json:"message_type"`
+	Error       string `json:"error"`
+}
+
+// ReplayResult is the decisions TssCommon makes on the messages of the transcript
+type ReplayResult struct {
+	MsgID     string           `json:"msg_id"`
+	Processed int              `json:"processed"`
+	Skipped   int              `json:"skipped"`
+	Errors    []ReplayError    `json:"errors"`
+	Blame     blame.Blame      `json:"blame"`
+	Evidences []blame.Evidence `json:"evidences"`
+	// Unconfirmed are the broadcast messages that never pass the hash check
+	Unconfirmed []string `json:"unconfirmed"`
+}
+
+// replayParty stands in for the local party, which cannot run without the key shares, it accepts all the shares
+// that pass the hash check
+type replayParty struct {
+	btss.Party
+	partyID *btss.PartyID
+}
+
+func (p *replayParty) UpdateFromBytes(_ []byte, _ *btss.PartyID, _ bool) (bool, *btss.Error) {
+	return true, nil
+}
+
+func (p *replayParty) PartyID() *btss.PartyID {
+	return p.partyID
+}
+
+// ReplayTranscript feeds the messages we received in the transcript to TssCommon in their recorded order, and
+// returns the hash check and blame decisions it makes. Nothing is sent to the peers in the replay, and the
+// redacted messages are skipped. The caller should verify the transcript before the replay.
+func ReplayTranscript(t transcript.Transcript, conf TssConfig) (ReplayResult, error) {
+	if len(t.Parties) == 0 {
+		return ReplayResult{}, errors.New("transcript has no parties")
+	}
+	partiesID, localPartyID, err := conversion.GetParties(t.Parties, t.PubKey)
+	if err != nil {
+		return ReplayResult{}, fmt.Errorf("fail to get the parties of the transcript: %w", err)
+	}
+	identifiers := replayIdentifiers(t.Entries)
+	tssCommon := NewTssCommon(t.LocalPeerID, nil, conf, t.MsgID, nil, len(identifiers))
+	partyIDMap := conversion.SetupPartyIDMap(partiesID)
+	err1 := conversion.SetupIDMaps(partyIDMap, tssCommon.PartyIDtoP2PID)
+	err2 := conversion.SetupIDMaps(partyIDMap, tssCommon.blameMgr.PartyIDtoP2PID)
+	if err1 != nil || err2 != nil {
+		return ReplayResult{}, errors.New("error in creating mapping between partyID and P2P ID")
+	}
+	partyMap := new(sync.Map)
+	for _, el := range identifiers {
+		partyMap.Store(el, &replayParty{partyID: localPartyID})
+	}
+	tssCommon.SetPartyInfo(&PartyInfo{
+		PartyMap:   partyMap,
+		PartyIDMap: partyIDMap,
+	})
+	// the blame manager takes the local party from the party map, there is none without the tss messages
+	if len(identifiers) != 0 {
+		tssCommon.blameMgr.SetPartyInfo(partyMap, partyIDMap)
+	}
+	tssCommon.P2PPeers = conversion.GetPeersID(tssCommon.PartyIDtoP2PID, t.LocalPeerID)
+
+	result := ReplayResult{MsgID: t.MsgID}
+	for _, el := range t.Entries {
+		if el.Direction != transcript.DirectionIn || len(el.Peers) == 0 {
+			continue
+		}
+		if el.Redacted {
+			result.Skipped++
+			continue
+		}
+		result.Processed++
+		msg := el.Message
+		if err := tssCommon.ProcessOneMessage(&msg, el.Peers[0]); err != nil {
+			result.Errors = append(result.Errors, ReplayError{
+				Seq:         el.Seq,
+				Peer:        el.Peers[0],
+				MessageType: msg.MessageType.String(),
+				Error:       err.Error(),
+			})
+		}
+	}
+	result.Blame = *tssCommon.blameMgr.GetBlame()
+	result.Evidences = tssCommon.blameMgr.GetEvidences()
+	tssCommon.unConfirmedMsgLock.Lock()
+	for key := range tssCommon.unConfirmedMessages {
+		result.Unconfirmed = append(result.Unconfirmed, key)
+	}
+	tssCommon.unConfirmedMsgLock.Unlock()
+	sort.Strings(result.Unconfirmed)
+	return result, nil
+}
+
+// replayIdentifiers returns the identifiers of the local parties the recorded tss messages are sent to
+func replayIdentifiers(entries []transcript.Entry) []string {
+	seen := make(map[string]bool)
+	var identifiers []string
+	for _, el := range entries {
+		var wireMsg *messages.WireMessage
+		switch el.Message.MessageType {
+		case messages.TSSKeyGenMsg, messages.TSSKeySignMsg:
+			wireMsg = &messages.WireMessage{}
+			if err := json.Unmarshal(el.Message.Payload, wireMsg); err != nil {
+				continue
+			}
+		case messages.TSSControlMsg:
+			var control messages.TssControl
+			if err := json.Unmarshal(el.Message.Payload, &control); err != nil {
+				continue
+			}
+			wireMsg = control.Msg
+		}
+		if wireMsg == nil {
+			continue
+		}
+		var bulkMsg []BulkWireMsg
+		if err := json.Unmarshal(wireMsg.Message, &bulkMsg); err != nil {
+			continue
+		}
+		for _, each := range bulkMsg {
+			if !seen[each.MsgIdentifier] {
+				seen[each.MsgIdentifier] = true
+				identifiers = append(identifiers, each.MsgIdentifier)
+			}
+		}
+	}
+	return identifiers
+}
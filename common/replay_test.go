@@ -0,0 +1,62 @@
+package common
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/transcript"
+)
+
+func (t *TssTestSuite) TestReplayTranscript(c *C) {
+	_, err := ReplayTranscript(transcript.Transcript{MsgID: "123"}, TssConfig{})
+	c.Assert(err, NotNil)
+
+	localPubKey := testBlamePubKeys[1]
+	localPeerID, err := conversion.GetPeerIDFromPubKey(localPubKey)
+	c.Assert(err, IsNil)
+	partiesID, _, err := conversion.GetParties(append([]string{}, testBlamePubKeys...), localPubKey)
+	c.Assert(err, IsNil)
+	sender := findSender(partiesID)
+	senderPeerID, err := conversion.GetPeerIDFromPartyID(sender)
+	c.Assert(err, IsNil)
+
+	// the sender sends the hash of its own message, which fails the hash check
+	roundInfo := messages.KEYGEN1
+	tssMsg, expectedSignature := fabricateTssMsg(c, t.privKey, sender, roundInfo, fabricateKeygenRound1Msg(c), "123", messages.TSSKeyGenMsg)
+	tssMsg.MsgID = "123"
+	verMsg := fabricateVerMsg(c, "hash", fmt.Sprintf("%s-%s", sender.Id, roundInfo))
+	verMsg.MsgID = "123"
+	tr := transcript.Transcript{
+		MsgID:       "123",
+		LocalPeerID: localPeerID.String(),
+		PubKey:      localPubKey,
+		Parties:     testBlamePubKeys,
+		Entries: []transcript.Entry{
+			{Seq: 0, Direction: transcript.DirectionIn, Peers: []string{senderPeerID.String()}, Message: *tssMsg},
+			{Seq: 1, Direction: transcript.DirectionOut, Peers: []string{senderPeerID.String()}, Message: *tssMsg},
+			{Seq: 2, Direction: transcript.DirectionIn, Peers: []string{senderPeerID.String()}, Redacted: true, Message: *tssMsg},
+			{Seq: 3, Direction: transcript.DirectionIn, Peers: []string{senderPeerID.String()}, Message: *verMsg},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		// the replay is deterministic
+		result, err := ReplayTranscript(tr, TssConfig{})
+		c.Assert(err, IsNil)
+		c.Assert(result.Processed, Equals, 2)
+		c.Assert(result.Skipped, Equals, 1)
+		c.Assert(result.Errors, HasLen, 1)
+		c.Assert(result.Errors[0].Seq, Equals, uint64(3))
+		c.Assert(result.Errors[0].Error, Equals, blame.ErrHashCheck.Error())
+		c.Assert(result.Blame.FailReason, Equals, blame.HashCheckFail)
+		c.Assert(result.Blame.BlameNodes, HasLen, 1)
+		c.Assert(result.Blame.BlameNodes[0].Pubkey, Equals, testSenderPubKey)
+		c.Assert(result.Blame.BlameNodes[0].BlameSignature, DeepEquals, expectedSignature)
+		c.Assert(result.Evidences, HasLen, 1)
+		c.Assert(blame.VerifyEvidence(result.Evidences[0]), IsNil)
+		c.Assert(result.Unconfirmed, DeepEquals, []string{fmt.Sprintf("%s-%s", sender.Id, roundInfo)})
+	}
+}
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"time"
+
+	btss "github.com/binance-chain/tss-lib/tss"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+)
+
+// RoundTimer gives each tss round its own deadline, the deadline of a round starts when the local party
+// enters the round, that is, when it produces the first message of the round. The timer with zero timeout
+// never expires
+type RoundTimer struct {
+	timeout time.Duration
+	round   int
+	timer   *time.Timer
+}
+
+// NewRoundTimer create a new instance of RoundTimer, the deadline of the first round starts immediately
+func NewRoundTimer(timeout time.Duration) *RoundTimer {
+	r := &RoundTimer{
+		timeout: timeout,
+		round:   -1,
+	}
+	if timeout > 0 {
+		r.timer = time.NewTimer(timeout)
+	}
+	return r
+}
+
+// C returns the channel fires when the current round expires
+func (r *RoundTimer) C() <-chan time.Time {
+	if r.timer == nil {
+		return nil
+	}
+	return r.timer.C
+}
+
+// Update resets the deadline if the message produced by the local party starts a new round
+func (r *RoundTimer) Update(msg btss.Message) {
+	round, err := GetRoundFromMsg(msg)
+	if err != nil || round.Index <= r.round {
+		return
+	}
+	r.round = round.Index
+	if r.timer == nil {
+		return
+	}
+	if !r.timer.Stop() {
+		select {
+		case <-r.timer.C:
+		default:
+		}
+	}
+	r.timer.Reset(r.timeout)
+}
+
+// Stop the timer
+func (r *RoundTimer) Stop() {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// Deadline is the hard deadline of the whole ceremony, the deadline of zero never expires
+type Deadline struct {
+	timer *time.Timer
+}
+
+// NewDeadline create a new instance of Deadline, it starts immediately
+func NewDeadline(deadline time.Duration) *Deadline {
+	if deadline <= 0 {
+		return &Deadline{}
+	}
+	return &Deadline{timer: time.NewTimer(deadline)}
+}
+
+// C returns the channel fires when the deadline expires
+func (d *Deadline) C() <-chan time.Time {
+	if d.timer == nil {
+		return nil
+	}
+	return d.timer.C
+}
+
+// Stop the deadline
+func (d *Deadline) Stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// BlameMissingRound blames the parties that have not sent us their shares of the round we are stuck in, rounds
+// are the messages of each round of the ceremony. It returns false if we cannot find the missing parties, or there
+// are more of them than the threshold, in which case it is more likely that we are the one cut off.
+func (t *TssCommon) BlameMissingRound(lastMsg btss.Message, rounds []string, failReason string) bool {
+	if lastMsg == nil {
+		return false
+	}
+	current, err := GetRoundFromMsg(lastMsg)
+	if err != nil || current.Index >= len(rounds) {
+		t.logger.Error().Err(err).Msg("fail to get the round of the last message")
+		return false
+	}
+	t.blameMgr.SetFailedRound(current.RoundMsg)
+	round, blameNodes, err := t.blameMgr.RoundMissingBlame(rounds[:current.Index+1])
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the parties missing in the round")
+		return false
+	}
+	if len(blameNodes) == 0 {
+		return false
+	}
+	t.P2PPeersLock.RLock()
+	threshold, err := conversion.GetThreshold(len(t.P2PPeers) + 1)
+	t.P2PPeersLock.RUnlock()
+	if err != nil {
+		t.logger.Error().Err(err).Msg("error in get the threshold to generate blame")
+		return false
+	}
+	if len(blameNodes) > threshold {
+		t.logger.Error().Msgf("round %s(%d) expired without the shares from %d parties, more than the threshold", round.RoundMsg, round.Index, len(blameNodes))
+		return false
+	}
+	t.logger.Error().Msgf("round %s(%d) expired without the shares from %v", round.RoundMsg, round.Index, blameNodes)
+	t.blameMgr.SetFailedRound(round.RoundMsg)
+	t.blameMgr.GetBlame().SetBlame(failReason, blameNodes, messages.IsUnicastRound(round.RoundMsg))
+	return true
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path"
+	"time"
+
+	btss "github.com/binance-chain/tss-lib/tss"
+	"github.com/libp2p/go-libp2p/core/peer"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/messages"
+)
+
+type roundTimerSuite struct{}
+
+var _ = Suite(&roundTimerSuite{})
+
+func (roundTimerSuite) TestDeadline(c *C) {
+	deadline := NewDeadline(0)
+	c.Assert(deadline.C(), IsNil)
+	deadline.Stop()
+	deadline = NewDeadline(time.Millisecond * 100)
+	defer deadline.Stop()
+	select {
+	case <-deadline.C():
+	case <-time.After(time.Second):
+		c.Fatal("the deadline should expire")
+	}
+}
+
+func (roundTimerSuite) TestRoundTimer(c *C) {
+	data, err := ioutil.ReadFile(path.Join("../test_data/tss_keygen_shares", "shareskeygen0"))
+	c.Assert(err, IsNil)
+	var shares []btss.Message
+	mockParty := btss.NewPartyID("12", "22", big.NewInt(2))
+	for _, el := range bytes.Split(data, []byte("\n"))[:2] {
+		var msg messages.WireMessage
+		c.Assert(json.Unmarshal(el, &msg), IsNil)
+		parsed, err := btss.ParseWireMessage(msg.Message, mockParty, msg.Routing.IsBroadcast)
+		c.Assert(err, IsNil)
+		shares = append(shares, parsed)
+	}
+
+	timer := NewRoundTimer(time.Millisecond * 300)
+	defer timer.Stop()
+	time.Sleep(time.Millisecond * 200)
+	// the new round gets a new deadline
+	timer.Update(shares[0])
+	select {
+	case <-timer.C():
+		c.Fatal("the round should not expire")
+	case <-time.After(time.Millisecond * 200):
+	}
+	// the message of the same round does not extend the deadline
+	timer.Update(shares[0])
+	select {
+	case <-timer.C():
+	case <-time.After(time.Millisecond * 200):
+		c.Fatal("the round should expire")
+	}
+	timer.Update(shares[1])
+	select {
+	case <-timer.C():
+		c.Fatal("the round should not expire")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// the rounds have no deadline of their own without the timeout
+	disabled := NewRoundTimer(0)
+	defer disabled.Stop()
+	disabled.Update(shares[0])
+	c.Assert(disabled.C(), IsNil)
+}
+
+func (t *TssTestSuite) TestBlameMissingRound(c *C) {
+	tssCommonStruct, peerPartiesID, _ := setupProcessVerMsgEnv(c, t.privKey, testBlamePubKeys, 4)
+	var peers []peer.ID
+	for _, el := range peerPartiesID {
+		peers = append(peers, tssCommonStruct.PartyIDtoP2PID[el.Id])
+	}
+	tssCommonStruct.P2PPeersLock.Lock()
+	tssCommonStruct.P2PPeers = peers
+	tssCommonStruct.P2PPeersLock.Unlock()
+
+	data, err := ioutil.ReadFile(path.Join("../test_data/tss_keygen_shares", "shareskeygen0"))
+	c.Assert(err, IsNil)
+	var msg messages.WireMessage
+	c.Assert(json.Unmarshal(bytes.Split(data, []byte("\n"))[0], &msg), IsNil)
+	lastMsg, err := btss.ParseWireMessage(msg.Message, btss.NewPartyID("12", "22", big.NewInt(2)), msg.Routing.IsBroadcast)
+	c.Assert(err, IsNil)
+
+	// all the 3 peers are missing, more than the threshold, so it is more likely that we are cut off
+	blameMgr := tssCommonStruct.GetBlameMgr()
+	c.Assert(tssCommonStruct.BlameMissingRound(lastMsg, messages.TSSKeyGenRoundMsgs, blame.TssTimeout), Equals, false)
+	c.Assert(blameMgr.GetBlame().BlameNodes, HasLen, 0)
+	c.Assert(blameMgr.GetFailedRound(), Equals, messages.KEYGEN1)
+
+	round := blame.RoundInfo{Index: 0, RoundMsg: messages.KEYGEN1, MsgIdentifier: "tester"}
+	for _, el := range peerPartiesID[:2] {
+		blameMgr.UpdateAcceptShare(round, el.Id)
+	}
+	c.Assert(tssCommonStruct.BlameMissingRound(lastMsg, messages.TSSKeyGenRoundMsgs, blame.TssTimeout), Equals, true)
+	c.Assert(blameMgr.GetBlame().BlameNodes, HasLen, 1)
+	c.Assert(blameMgr.GetBlame().FailReason, Equals, blame.TssTimeout)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/tracing"
+)
+
+type TracingTestSuite struct {
+	exporter *tracetest.InMemoryExporter
+}
+
+var _ = Suite(&TracingTestSuite{})
+
+func (s *TracingTestSuite) SetUpSuite(c *C) {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+}
+
+func (s *TracingTestSuite) TestMessageJoinsTheTraceOfSender(c *C) {
+	broadcastChan := make(chan *messages.BroadcastMsgChan, 1)
+	sender := NewTssCommon("", broadcastChan, TssConfig{}, "msgID", nil, 1)
+	senderCtx, senderSpan := tracing.Start(context.Background(), "tss.keysign")
+	defer senderSpan.End()
+	sender.SetTraceContext(senderCtx)
+	sender.renderToP2P(&messages.BroadcastMsgChan{
+		WrappedMessage: messages.WrappedMessage{
+			MessageType: messages.TSSKeySignMsg,
+			MsgID:       "msgID",
+			Payload:     []byte("{"),
+		},
+	})
+	wrappedMsg := (<-broadcastChan).WrappedMessage
+	c.Assert(wrappedMsg.TraceContext["traceparent"], Matches, ".*"+senderSpan.SpanContext().TraceID().String()+".*")
+
+	receiver := NewTssCommon("", nil, TssConfig{}, "msgID", nil, 1)
+	c.Assert(receiver.TraceContext(), Equals, context.Background())
+	receiverCtx, receiverSpan := tracing.Start(context.Background(), "tss.keysign")
+	defer receiverSpan.End()
+	receiver.SetTraceContext(receiverCtx)
+	c.Assert(receiver.ProcessOneMessage(&wrappedMsg, "peer"), NotNil)
+
+	spans := s.exporter.GetSpans()
+	c.Assert(spans, HasLen, 1)
+	c.Assert(spans[0].Name, Equals, "tss.process_message")
+	c.Assert(spans[0].SpanContext.TraceID(), Equals, senderSpan.SpanContext().TraceID())
+	c.Assert(spans[0].Parent.SpanID(), Equals, senderSpan.SpanContext().SpanID())
+	c.Assert(spans[0].Links, HasLen, 1)
+	c.Assert(spans[0].Links[0].SpanContext.SpanID(), Equals, receiverSpan.SpanContext().SpanID())
+	c.Assert(spans[0].Status.Code, Equals, codes.Error)
+}
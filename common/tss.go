@@ -1,12 +1,15 @@
 package common
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	btss "github.com/binance-chain/tss-lib/tss"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -18,7 +21,10 @@ import (
 	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
 	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/tracing"
+	"github.com/joltify-finance/tss/transcript"
 )
 
 // PartyInfo the information used by tss key gen and key sign
@@ -50,6 +56,11 @@ type TssCommon struct {
 	cachedWireBroadcastMsgLists *sync.Map
 	cachedWireUnicastMsgLists   *sync.Map
 	msgNum                      int
+	recorder                    *transcript.Recorder
+	metrics                     *monitor.Metric
+	traceCtx                    context.Context
+	deferredMsgsLock            *sync.Mutex
+	deferredMsgs                []*p2p.Message
 }
 
 func NewTssCommon(peerID string, broadcastChannel chan *messages.BroadcastMsgChan, conf TssConfig, msgID string, privKey tcrypto.PrivKey, msgNum int) *TssCommon {
@@ -75,6 +86,7 @@ func NewTssCommon(peerID string, broadcastChannel chan *messages.BroadcastMsgCha
 		cachedWireBroadcastMsgLists: &sync.Map{},
 		cachedWireUnicastMsgLists:   &sync.Map{},
 		msgNum:                      msgNum,
+		deferredMsgsLock:            &sync.Mutex{},
 	}
 }
 
@@ -129,12 +141,18 @@ func (t *TssCommon) doTssJob(tssJobChan chan *tssJob, jobWg *sync.WaitGroup) {
 		}
 		round.MsgIdentifier = tssjob.msgIdentifier
 
+		_, span := tracing.Start(t.TraceContext(), "tss.apply_share",
+			tracing.MsgIDKey.String(t.msgID), tracing.RoundKey.String(round.RoundMsg), tracing.PeerKey.String(partyID.Id))
+		start := time.Now()
 		_, errUp := party.UpdateFromBytes(wireBytes, partyID, isBroadcast)
+		t.metrics.UpdateRoundTime(round.RoundMsg, time.Since(start))
 		if errUp != nil {
+			tracing.End(span, errUp)
 			err := t.processInvalidMsgBlame(round.RoundMsg, round, errUp)
 			t.logger.Error().Err(err).Msgf("fail to apply the share to tss")
 			continue
 		}
+		span.End()
 		// we need to retrieve the partylist again as others may update it once we process apply tss share
 		t.blameMgr.UpdateAcceptShare(round, partyID.Id)
 	}
@@ -145,6 +163,14 @@ func (t *TssCommon) renderToP2P(broadcastMsg *messages.BroadcastMsgChan) {
 		t.logger.Warn().Msg("broadcast channel is not set")
 		return
 	}
+	if broadcastMsg.WrappedMessage.TraceContext == nil {
+		broadcastMsg.WrappedMessage.TraceContext = tracing.Inject(t.TraceContext())
+	}
+	peers := make([]string, len(broadcastMsg.PeersID))
+	for i, el := range broadcastMsg.PeersID {
+		peers[i] = el.String()
+	}
+	t.recorder.Record(transcript.DirectionOut, peers, broadcastMsg.WrappedMessage)
 	t.broadcastChannel <- broadcastMsg
 }
 
@@ -165,6 +191,43 @@ func (t *TssCommon) SetPartyInfo(partyInfo *PartyInfo) {
 	t.partyLock.Lock()
 	defer t.partyLock.Unlock()
 	t.partyInfo = partyInfo
+	if t.recorder != nil && partyInfo != nil {
+		var parties []string
+		for _, el := range partyInfo.PartyIDMap {
+			pk, err := conversion.PartyIDtoPubKey(el)
+			if err != nil {
+				t.logger.Error().Err(err).Msg("fail to get the pub key of the party")
+				continue
+			}
+			parties = append(parties, pk)
+		}
+		t.recorder.SetParties(t.msgID, parties)
+	}
+}
+
+// SetRecorder records the messages we send in the transcript of the ceremony, the messages we receive are
+// recorded by the p2p communication
+func (t *TssCommon) SetRecorder(recorder *transcript.Recorder) {
+	t.recorder = recorder
+}
+
+// SetTraceContext sets the context of the span of the ceremony, the messages we send carry its trace and the
+// processing of the messages is traced in it
+func (t *TssCommon) SetTraceContext(ctx context.Context) {
+	t.traceCtx = ctx
+}
+
+// TraceContext returns the context of the span of the ceremony, the background context if it is not traced
+func (t *TssCommon) TraceContext() context.Context {
+	if t.traceCtx == nil {
+		return context.Background()
+	}
+	return t.traceCtx
+}
+
+// SetMetrics sets the metrics the processing of the shares is recorded in
+func (t *TssCommon) SetMetrics(metrics *monitor.Metric) {
+	t.metrics = metrics
 }
 
 func (t *TssCommon) getPartyInfo() *PartyInfo {
@@ -183,20 +246,24 @@ func (t *TssCommon) SetLocalPeerID(peerID string) {
 
 func (t *TssCommon) processInvalidMsgBlame(roundInfo string, round blame.RoundInfo, err *btss.Error) error {
 	// now we get the culprits ID, invalid message and signature the culprits sent
-	var culpritsID []string
+	var pubkeys []string
 	var invalidMsgs []*messages.WireMessage
 	unicast := checkUnicast(round)
 	t.culpritsLock.Lock()
 	t.culprits = append(t.culprits, err.Culprits()...)
 	t.culpritsLock.Unlock()
 	for _, el := range err.Culprits() {
-		culpritsID = append(culpritsID, el.Id)
+		pk, errBlame := t.culpritPubKey(el)
+		if errBlame != nil {
+			t.logger.Error().Err(errBlame).Msgf("fail to get the public key of the culprit")
+			continue
+		}
 		key := fmt.Sprintf("%s-%s", el.Id, roundInfo)
 		storedMsg := t.blameMgr.GetRoundMgr().Get(key)
+		pubkeys = append(pubkeys, pk)
 		invalidMsgs = append(invalidMsgs, storedMsg)
 	}
-	pubkeys, errBlame := conversion.AccPubKeysFromPartyIDs(culpritsID, t.partyInfo.PartyIDMap)
-	if errBlame != nil {
+	if len(pubkeys) == 0 {
 		t.logger.Error().Err(err.Cause()).Msgf("error in get the blame nodes")
 		t.blameMgr.GetBlame().SetBlame(blame.TssBrokenMsg, nil, unicast)
 		return fmt.Errorf("error in getting the blame nodes")
@@ -215,15 +282,31 @@ func (t *TssCommon) processInvalidMsgBlame(roundInfo string, round blame.RoundIn
 			msgBody = invalidMsg.Message
 			sig = invalidMsg.Sig
 		}
-		blameNodes = append(blameNodes, blame.NewNode(pk, msgBody, sig))
+		blameNode := blame.NewNode(pk, msgBody, sig)
+		blameNodes = append(blameNodes, blameNode)
+		if invalidMsg != nil {
+			t.blameMgr.AddEvidence(blame.NewEvidence(t.msgID, invalidMsg.RoundInfo, blame.TssBrokenMsg, blameNode))
+		}
 	}
 	t.blameMgr.GetBlame().SetBlame(blame.TssBrokenMsg, blameNodes, unicast)
 	return fmt.Errorf("fail to set bytes to local party: %w", err)
 }
 
+// culpritPubKey returns the public key of the culprit, if the culprit is not in our party map, we use the key
+// it carries, so that the culprit reported by the protocol is always named
+func (t *TssCommon) culpritPubKey(culprit *btss.PartyID) (string, error) {
+	if culprit == nil {
+		return "", errors.New("nil culprit")
+	}
+	if party, ok := t.partyInfo.PartyIDMap[culprit.Id]; ok {
+		return conversion.PartyIDtoPubKey(party)
+	}
+	return conversion.PartyIDtoPubKey(culprit)
+}
+
 // updateLocal will apply the wireMsg to local keygen/keysign party
 func (t *TssCommon) updateLocal(wireMsg *messages.WireMessage) error {
-	if wireMsg == nil || wireMsg.Routing == nil || wireMsg.Routing.From == nil {
+	if wireMsg == nil || !isValidRouting(wireMsg.Routing) {
 		t.logger.Warn().Msg("wire msg is nil")
 		return errors.New("invalid wireMsg")
 	}
@@ -231,9 +314,8 @@ func (t *TssCommon) updateLocal(wireMsg *messages.WireMessage) error {
 	if partyInfo == nil {
 		return nil
 	}
-	partyID, ok := partyInfo.PartyIDMap[wireMsg.Routing.From.Id]
-	if !ok {
-		return fmt.Errorf("get message from unknown party %s", partyID.Id)
+	if _, ok := partyInfo.PartyIDMap[wireMsg.Routing.From.Id]; !ok {
+		return fmt.Errorf("get message from unknown party %s", wireMsg.Routing.From.Id)
 	}
 
 	dataOwnerPeerID, ok := t.PartyIDtoP2PID[wireMsg.Routing.From.Id]
@@ -260,7 +342,16 @@ func (t *TssCommon) updateLocal(wireMsg *messages.WireMessage) error {
 		jobWg.Add(1)
 		go t.doTssJob(tssJobChan, &jobWg)
 	}
+	// the workers quit once the channel is closed, including when we return early on a broken message
+	defer func() {
+		close(tssJobChan)
+		jobWg.Wait()
+	}()
 	for _, msg := range bulkMsg {
+		if !isValidRouting(msg.Routing) {
+			t.logger.Error().Msg("the wired msg has no routing")
+			return errors.New("invalid bulk msg")
+		}
 		data, ok := partyInfo.PartyMap.Load(msg.MsgIdentifier)
 		if !ok {
 			t.logger.Error().Msg("cannot find the party to this wired msg")
@@ -306,8 +397,6 @@ func (t *TssCommon) updateLocal(wireMsg *messages.WireMessage) error {
 		job := newJob(localMsgParty, msg.WiredBulkMsgs, round.MsgIdentifier, partyID, msg.Routing.IsBroadcast)
 		tssJobChan <- job
 	}
-	close(tssJobChan)
-	jobWg.Wait()
 	return nil
 }
 
@@ -334,7 +423,14 @@ func (t *TssCommon) ProcessOneMessage(wrappedMsg *messages.WrappedMessage, peerI
 	if nil == wrappedMsg {
 		return errors.New("invalid wireMessage")
 	}
+	_, span := tracing.StartRemote(t.TraceContext(), wrappedMsg.TraceContext, "tss.process_message",
+		tracing.MsgIDKey.String(wrappedMsg.MsgID), tracing.MessageTypeKey.String(wrappedMsg.MessageType.String()), tracing.PeerKey.String(peerID))
+	err := t.processOneMessage(wrappedMsg, peerID)
+	tracing.End(span, err)
+	return err
+}
 
+func (t *TssCommon) processOneMessage(wrappedMsg *messages.WrappedMessage, peerID string) error {
 	switch wrappedMsg.MessageType {
 	case messages.TSSKeyGenMsg, messages.TSSKeySignMsg:
 		var wireMsg messages.WireMessage
@@ -364,8 +460,12 @@ func (t *TssCommon) ProcessOneMessage(wrappedMsg *messages.WrappedMessage, peerI
 			if t.finishedPeers[peerID] {
 				return fmt.Errorf("duplicated notification from peer %s ignored", peerID)
 			}
+			partyInfo := t.getPartyInfo()
+			if partyInfo == nil {
+				return errors.New("can't process task done msg, local party is not ready")
+			}
 			t.finishedPeers[peerID] = true
-			if len(t.finishedPeers) == len(t.partyInfo.PartyIDMap)-1 {
+			if len(t.finishedPeers) == len(partyInfo.PartyIDMap)-1 {
 				t.logger.Debug().Msg("we get the confirm of the nodes that generate the signature")
 				close(t.taskDone)
 			}
@@ -436,6 +536,15 @@ func (t *TssCommon) hashCheck(localCacheItem *LocalCacheItem, threshold int) err
 	if err != nil {
 		return err
 	}
+	// the parties confirmed so far are split on the hash, we wait for the others before we decide on it, and the
+	// data owner is blamed if they are still split once all of them have confirmed
+	if isHashTied(localCacheItem.ConfirmedList, hash) {
+		if len(localCacheItem.ConfirmedList) < len(t.getPartyInfo().PartyIDMap)-1 {
+			t.logger.Debug().Msg("the confirmed hashes are tied, wait for the other parties")
+			return blame.ErrNotEnoughPeer
+		}
+		return blame.ErrHashInconsistency
+	}
 	if targetHashValue == hash {
 		t.logger.Debug().Msgf("hash check complete for messageID: %v", t.msgID)
 		return nil
@@ -571,8 +680,10 @@ func (t *TssCommon) applyShare(localCacheItem *LocalCacheItem, threshold int, ke
 		if errors.Is(err, blame.ErrNotEnoughPeer) {
 			return nil
 		}
+		t.metrics.HashCheckFailure(hashCheckFailReason(err))
 		if errors.Is(err, blame.ErrNotMajority) {
 			t.logger.Error().Err(err).Msg("we send request to get the message match with majority")
+			localCacheItem.MinorityMsg = localCacheItem.Msg
 			localCacheItem.Msg = nil
 			return t.requestShareFromPeer(localCacheItem, threshold, key, msgType)
 		}
@@ -584,9 +695,14 @@ func (t *TssCommon) applyShare(localCacheItem *LocalCacheItem, threshold int, ke
 		}
 		blameNode := blame.NewNode(blamePk, localCacheItem.Msg.Message, localCacheItem.Msg.Sig)
 		t.blameMgr.GetBlame().SetBlame(blame.HashCheckFail, []blame.Node{blameNode}, unicast)
+		t.blameMgr.AddEvidence(blame.NewEvidence(t.msgID, localCacheItem.Msg.RoundInfo, blame.HashCheckFail, blameNode))
 		return blame.ErrHashCheck
 	}
 
+	if localCacheItem.MinorityMsg != nil {
+		t.equivocationBlame(localCacheItem.MinorityMsg, localCacheItem.Msg)
+	}
+	t.metrics.ObservePhase(monitor.PhaseHashCheck, time.Since(localCacheItem.created), true)
 	t.blameMgr.GetRoundMgr().Set(key, localCacheItem.Msg)
 	if err := t.updateLocal(localCacheItem.Msg); nil != err {
 		return fmt.Errorf("fail to update the message to local party: %w", err)
@@ -597,6 +713,28 @@ func (t *TssCommon) applyShare(localCacheItem *LocalCacheItem, threshold int, ke
 	return nil
 }
 
+// equivocationBlame blames the owner of the broadcast message that has signed the message we received and the
+// different one the majority confirmed for the same round. The ceremony goes on with the message of the majority,
+// and both the signed messages are kept as the evidence
+func (t *TssCommon) equivocationBlame(received, confirmed *messages.WireMessage) {
+	if bytes.Equal(received.Message, confirmed.Message) {
+		return
+	}
+	blamePk, err := t.blameMgr.TssWrongShareBlame(confirmed)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the equivocation blame node")
+		return
+	}
+	t.logger.Warn().Msgf("the node(%s) has signed different broadcast messages for round %s", blamePk, confirmed.RoundInfo)
+	receivedNode := blame.NewNode(blamePk, received.Message, received.Sig)
+	confirmedNode := blame.NewNode(blamePk, confirmed.Message, confirmed.Sig)
+	t.blameMgr.GetBlame().SetBlame(blame.Equivocation, []blame.Node{receivedNode}, false)
+	t.blameMgr.AddEvidence(
+		blame.NewEvidence(t.msgID, received.RoundInfo, blame.Equivocation, receivedNode),
+		blame.NewEvidence(t.msgID, confirmed.RoundInfo, blame.Equivocation, confirmedNode),
+	)
+}
+
 func (t *TssCommon) requestShareFromPeer(localCacheItem *LocalCacheItem, threshold int, key string, msgType messages.THORChainTSSMessageType) error {
 	targetHash, err := t.getMsgHash(localCacheItem, threshold)
 	if err != nil {
@@ -735,12 +873,15 @@ func (t *TssCommon) processTSSMsg(wireMsg *messages.WireMessage, msgType message
 	t.logger.Debug().Msg("process wire message")
 	defer t.logger.Debug().Msg("finish process wire message")
 
-	if wireMsg == nil || wireMsg.Routing == nil || wireMsg.Routing.From == nil {
+	if wireMsg == nil || !isValidRouting(wireMsg.Routing) {
 		t.logger.Warn().Msg("received msg invalid")
 		return errors.New("invalid wireMsg")
 	}
-	partyIDMap := t.getPartyInfo().PartyIDMap
-	dataOwner, ok := partyIDMap[wireMsg.Routing.From.Id]
+	partyInfo := t.getPartyInfo()
+	if partyInfo == nil {
+		return errors.New("can't process tss msg, local party is not ready")
+	}
+	dataOwner, ok := partyInfo.PartyIDMap[wireMsg.Routing.From.Id]
 	if !ok {
 		t.logger.Error().Msg("error in find the data owner")
 		return errors.New("error in find the data owner")
@@ -768,7 +909,6 @@ func (t *TssCommon) processTSSMsg(wireMsg *messages.WireMessage, msgType message
 		}
 	}
 
-	partyInfo := t.getPartyInfo()
 	key := wireMsg.GetCacheKey()
 	msgHash, err := conversion.BytesToHashString(wireMsg.Message)
 	if err != nil {
@@ -840,6 +980,15 @@ func (t *TssCommon) removeKey(key string) {
 	delete(t.unConfirmedMessages, key)
 }
 
+// TakeDeferredMsgs returns the messages kept for the round after the tss-lib rounds, and clears them
+func (t *TssCommon) TakeDeferredMsgs() []*p2p.Message {
+	t.deferredMsgsLock.Lock()
+	defer t.deferredMsgsLock.Unlock()
+	msgs := t.deferredMsgs
+	t.deferredMsgs = nil
+	return msgs
+}
+
 func (t *TssCommon) ProcessInboundMessages(finishChan chan struct{}, wg *sync.WaitGroup) {
 	t.logger.Debug().Msg("start processing inbound messages")
 	defer wg.Done()
@@ -857,6 +1006,14 @@ func (t *TssCommon) ProcessInboundMessages(finishChan chan struct{}, wg *sync.Wa
 				t.logger.Error().Err(err).Msg("fail to unmarshal wrapped message bytes")
 				continue
 			}
+			// the signature share is for the round after the tss-lib rounds, the faster signers may send it
+			// before we finish, so we keep it for the round rather than drop it
+			if wrappedMsg.MessageType == messages.TSSPresignShare {
+				t.deferredMsgsLock.Lock()
+				t.deferredMsgs = append(t.deferredMsgs, m)
+				t.deferredMsgsLock.Unlock()
+				continue
+			}
 
 			err := t.ProcessOneMessage(&wrappedMsg, m.PeerID.String())
 			if err != nil {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
 )
 
 func Contains(s []*btss.PartyID, e *btss.PartyID) bool {
@@ -38,7 +39,12 @@ func Contains(s []*btss.PartyID, e *btss.PartyID) bool {
 	return false
 }
 
+// MsgToHashInt converts the hash of the message to the integer we sign, the message longer than the hash is
+// rejected instead of being truncated
 func MsgToHashInt(msg []byte) (*big.Int, error) {
+	if len(msg) > sha256.Size {
+		return nil, fmt.Errorf("message of %d bytes is longer than the %d bytes hash, it should be hashed first", len(msg), sha256.Size)
+	}
 	return hashToInt(msg, btcec.S256()), nil
 }
 
@@ -89,6 +95,11 @@ func generateSignature(msg []byte, msgID string, privKey tcrypto.PrivKey) ([]byt
 	return privKey.Sign(dataForSigning.Bytes())
 }
 
+// isValidRouting checks the routing decoded from the peer has the sender party
+func isValidRouting(routing *btss.MessageRouting) bool {
+	return routing != nil && routing.From != nil && routing.From.MessageWrapper_PartyID != nil
+}
+
 func verifySignature(pubKey tcrypto.PubKey, message, sig []byte, msgID string) bool {
 	var dataForSign bytes.Buffer
 	dataForSign.Write(message)
@@ -107,7 +118,8 @@ func getHighestFreq(confirmedList map[string]string) (string, int, error) {
 	maxFreq := -1
 	var data string
 	for key, counter := range freq {
-		if counter > maxFreq {
+		// the tie is broken by the hash, so that all the parties decide the same regardless of the map order
+		if counter > maxFreq || (counter == maxFreq && key < data) {
 			maxFreq = counter
 			data = key
 		}
@@ -115,6 +127,20 @@ func getHighestFreq(confirmedList map[string]string) (string, int, error) {
 	return data, maxFreq, nil
 }
 
+// isHashTied returns true if another hash is confirmed by as many parties as the given one
+func isHashTied(confirmedList map[string]string, hash string) bool {
+	freq := make(map[string]int, len(confirmedList))
+	for _, n := range confirmedList {
+		freq[n]++
+	}
+	for key, counter := range freq {
+		if key != hash && counter == freq[hash] {
+			return true
+		}
+	}
+	return false
+}
+
 // due to the nature of tss, we may find the invalid share of the previous round only
 // when we get the shares from the peers in the current round. So, when we identify
 // an error in this round, we check whether the previous round is the unicast
@@ -140,6 +166,15 @@ func GetMsgRound(msg []byte, partyID *btss.PartyID, isBroadcast bool) (blame.Rou
 	if err != nil {
 		return blame.RoundInfo{}, err
 	}
+	return GetRoundFromMsg(parsedMsg)
+}
+
+// GetRoundFromMsg returns the round of the tss message
+func GetRoundFromMsg(msg btss.Message) (blame.RoundInfo, error) {
+	parsedMsg, ok := msg.(btss.ParsedMessage)
+	if !ok {
+		return blame.RoundInfo{}, errors.New("unknown round")
+	}
 	switch parsedMsg.Content().(type) {
 	case *keygen.KGRound1Message:
 		return blame.RoundInfo{
@@ -240,6 +275,11 @@ func (t *TssCommon) NotifyTaskDone() error {
 }
 
 func (t *TssCommon) processRequestMsgFromPeer(peersID []peer.ID, msg *messages.TssControl, requester bool) error {
+	if requester {
+		t.metrics.ShareRequest(monitor.DirectionSent)
+	} else {
+		t.metrics.ShareRequest(monitor.DirectionReceived)
+	}
 	// we need to send msg to the peer
 	if !requester {
 		if msg == nil {
@@ -270,3 +310,17 @@ func (t *TssCommon) processRequestMsgFromPeer(peersID []peer.ID, msg *messages.T
 	})
 	return nil
 }
+
+// hashCheckFailReason returns the reason label of the hash check failure metric
+func hashCheckFailReason(err error) string {
+	switch {
+	case errors.Is(err, blame.ErrNotMajority):
+		return "not_majority"
+	case errors.Is(err, blame.ErrHashInconsistency):
+		return "inconsistent"
+	case errors.Is(err, blame.ErrHashFromOwner):
+		return "hash_from_owner"
+	default:
+		return "unknown"
+	}
+}
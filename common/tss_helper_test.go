@@ -40,6 +40,18 @@ func (t *tssHelpSuite) TestGetHashToBroadcast(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(val, Equals, "aa")
 	c.Assert(freq, Equals, 3)
+	c.Assert(isHashTied(testMap, "aa"), Equals, false)
+	c.Assert(isHashTied(testMap, "bb"), Equals, false)
+
+	testMap["9"] = "bb"
+	for i := 0; i < 10; i++ {
+		val, freq, err = getHighestFreq(testMap)
+		c.Assert(err, IsNil)
+		c.Assert(val, Equals, "aa")
+		c.Assert(freq, Equals, 3)
+	}
+	c.Assert(isHashTied(testMap, "aa"), Equals, true)
+	c.Assert(isHashTied(testMap, "bb"), Equals, true)
 }
 
 func (t *tssHelpSuite) TestMsgSignAndVerification(c *C) {
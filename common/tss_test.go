@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/tendermint/tendermint/crypto/ed25519"
+	"math/big"
 	"strings"
 	"sync"
 	"testing"
@@ -16,7 +16,10 @@ import (
 	btss "github.com/binance-chain/tss-lib/tss"
 	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
 	tcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 	. "gopkg.in/check.v1"
 
 	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
@@ -106,6 +109,11 @@ func (t *TssTestSuite) TestMsgToHashInt(c *C) {
 	result, err := MsgToHashInt(input)
 	c.Assert(err, IsNil)
 	c.Assert(result, NotNil)
+	result, err = MsgToHashInt(bytes.Repeat([]byte{0xff}, 32))
+	c.Assert(err, IsNil)
+	c.Assert(result.BitLen(), Equals, 256)
+	_, err = MsgToHashInt(bytes.Repeat([]byte{0xff}, 33))
+	c.Assert(err, NotNil)
 }
 
 func (t *TssTestSuite) TestContains(c *C) {
@@ -187,6 +195,16 @@ func fabricateTssMsg(c *C, privKey tcrypto.PrivKey, partyID *btss.PartyID, round
 	return &wrappedMsg, sig
 }
 
+// fabricateKeygenRound1Msg returns the wire bytes of the keygen round 1 message, so the evidence of the fabricated
+// tss message is of a real round
+func fabricateKeygenRound1Msg(c *C) string {
+	content, err := anypb.New(&btsskeygen.KGRound1Message{})
+	c.Assert(err, IsNil)
+	buf, err := proto.Marshal(content)
+	c.Assert(err, IsNil)
+	return string(buf)
+}
+
 func fabricateVerMsg(c *C, hash, hashKey string) *messages.WrappedMessage {
 	broadcastConfirmMsg := &messages.BroadcastConfirmMessage{
 		P2PID: "",
@@ -252,8 +270,8 @@ func setupProcessVerMsgEnv(c *C, privKey tcrypto.PrivKey, keyPool []string, part
 }
 
 func (t *TssTestSuite) testDropMsgOwner(c *C, privKey tcrypto.PrivKey, tssCommonStruct *TssCommon, senderID *btss.PartyID, peerPartiesID []*btss.PartyID) {
-	testMsg := "testDropMsgOwner"
-	roundInfo := "round testDropMsgOwner"
+	testMsg := fabricateKeygenRound1Msg(c)
+	roundInfo := messages.KEYGEN1
 	msgHash, err := conversion.BytesToHashString([]byte(testMsg))
 	c.Assert(err, IsNil)
 	msgKey := fmt.Sprintf("%s-%s", senderID.Id, roundInfo)
@@ -283,6 +301,16 @@ func (t *TssTestSuite) testDropMsgOwner(c *C, privKey tcrypto.PrivKey, tssCommon
 		}
 	}
 	c.Assert(found, Equals, true)
+	// the evidence of the blame can be verified without the tss session
+	found = false
+	for _, el := range tssCommonStruct.blameMgr.GetEvidences() {
+		if bytes.Equal(el.Signature, expectedSignature) {
+			c.Assert(el.Round, Equals, roundInfo)
+			c.Assert(blame.VerifyEvidence(el), IsNil)
+			found = true
+		}
+	}
+	c.Assert(found, Equals, true)
 }
 
 func (t *TssTestSuite) testProcessControlMsg(c *C, tssCommonStruct *TssCommon) {
@@ -434,6 +462,34 @@ func (t *TssTestSuite) TestProcessVerMessage(c *C) {
 	t.testProcessTaskDone(c, tssCommonStruct)
 }
 
+func (t *TssTestSuite) TestHashCheckTie(c *C) {
+	tssCommonStruct, _, partiesID := setupProcessVerMsgEnv(c, t.privKey, testBlamePubKeys, 4)
+	sender := findSender(partiesID)
+	var peers []string
+	for _, el := range partiesID {
+		if el.Id != sender.Id {
+			peers = append(peers, tssCommonStruct.PartyIDtoP2PID[el.Id].String())
+		}
+	}
+	newItem := func(hashes ...string) *LocalCacheItem {
+		item := NewLocalCacheItem(&messages.WireMessage{Routing: &btss.MessageRouting{From: sender}}, "aa")
+		for i, hash := range hashes {
+			item.UpdateConfirmList(peers[i], hash)
+		}
+		return item
+	}
+	// the tie is not decided before all the parties confirm
+	err := tssCommonStruct.hashCheck(newItem("aa", "bb"), 2)
+	c.Assert(errors.Is(err, blame.ErrNotEnoughPeer), Equals, true)
+	err = tssCommonStruct.hashCheck(newItem("aa", "bb", "bb"), 2)
+	c.Assert(errors.Is(err, blame.ErrNotMajority), Equals, true)
+	err = tssCommonStruct.hashCheck(newItem("aa", "bb", "aa"), 2)
+	c.Assert(err, IsNil)
+	// the parties are still split once all of them confirmed
+	err = tssCommonStruct.hashCheck(newItem("aa", "bb", "cc"), 2)
+	c.Assert(errors.Is(err, blame.ErrHashInconsistency), Equals, true)
+}
+
 func (t *TssTestSuite) TestTssCommon(c *C) {
 	edsk := ed25519.GenPrivKey()
 	peerID, err := conversion.GetPeerIDFromEd25519PubKey(edsk.PubKey().Bytes())
@@ -489,6 +545,8 @@ func (t *TssTestSuite) TestProcessInvalidMsgBlame(c *C) {
 	tssCommonStruct.processInvalidMsgBlame(wiredMsg.RoundInfo, blame.RoundInfo{RoundMsg: roundInfo}, fakeErr)
 	blameResult := tssCommonStruct.GetBlameMgr().GetBlame()
 	c.Assert(blameResult.BlameNodes, HasLen, 3)
+	// we only have the evidence of the culprits whose message we have recorded
+	c.Assert(tssCommonStruct.GetBlameMgr().GetEvidences(), HasLen, 2)
 
 	routingInfo := btss.MessageRouting{
 		From:                    sender,
@@ -507,3 +565,19 @@ func (t *TssTestSuite) TestProcessInvalidMsgBlame(c *C) {
 	// for the last one, since we do not store the msg before hand, it should return no record of this party
 	c.Assert(blameResult.BlameNodes[2].BlameData, HasLen, 0)
 }
+
+func (t *TssTestSuite) TestProcessInvalidMsgBlameUnknownCulprit(c *C) {
+	tssCommonStruct, peerPartiesID, _ := setupProcessVerMsgEnv(c, t.privKey, testBlamePubKeys, 4)
+	// the culprit is not in our party map, we still blame it with the key it carries
+	unknown := btss.NewPartyID("unknown", "", new(big.Int).SetBytes(peerPartiesID[0].Key))
+	unknown.Index = peerPartiesID[0].Index
+	expectedPubKey, err := conversion.PartyIDtoPubKey(peerPartiesID[0])
+	c.Assert(err, IsNil)
+
+	fakeErr := btss.NewError(errors.New("test error"), "test task", 1, nil, unknown)
+	c.Assert(tssCommonStruct.processInvalidMsgBlame("round testMessage", blame.RoundInfo{RoundMsg: "round testMessage"}, fakeErr), NotNil)
+	blameResult := tssCommonStruct.GetBlameMgr().GetBlame()
+	c.Assert(blameResult.FailReason, Equals, blame.TssBrokenMsg)
+	c.Assert(blameResult.BlameNodes, HasLen, 1)
+	c.Assert(blameResult.BlameNodes[0].Pubkey, Equals, expectedPubKey)
+}
@@ -2,6 +2,8 @@ package common
 
 import (
 	"time"
+
+	maddr "github.com/multiformats/go-multiaddr"
 )
 
 type TssConfig struct {
@@ -9,10 +11,65 @@ type TssConfig struct {
 	PartyTimeout time.Duration
 	// KeyGenTimeoutSeconds defines how long do we wait the keygen parties to pass messages along
 	KeyGenTimeout time.Duration
+	// KeyGenRoundTimeout defines how long do we wait for the other parties in each keygen round, zero disables it
+	KeyGenRoundTimeout time.Duration
+	// KeyGenDeadline defines how long the whole keygen can take, zero disables it
+	KeyGenDeadline time.Duration
 	// KeySignTimeoutSeconds defines how long do we wait keysign
 	KeySignTimeout time.Duration
+	// KeySignRoundTimeout defines how long do we wait for the other parties in each keysign round, zero disables it
+	KeySignRoundTimeout time.Duration
+	// KeySignDeadline defines how long the whole keysign can take, zero disables it
+	KeySignDeadline time.Duration
 	// Pre-parameter define the pre-parameter generations timeout
 	PreParamTimeout time.Duration
 	// enable the tss monitor
 	EnableMonitor bool
+	// EnableMDNS enables the mdns discovery of peers in the local network, it should only be used in development
+	EnableMDNS bool
+	// EnableRelay enables the circuit relay and hole punching for the nodes behind NAT
+	EnableRelay bool
+	// RelayService makes the node act as a circuit relay for the other committee members
+	RelayService bool
+	// RelayPeers are the static relays the node reserves the slot on when it is behind NAT
+	RelayPeers []maddr.Multiaddr
+	// BehindNAT makes the node reserve the slots on the relay peers right away, rather than wait for AutoNAT to
+	// find out it is not reachable
+	BehindNAT bool
+	// LeaderSelector is the strategy to choose the join party leader(hash, roundrobin or latency), all the
+	// nodes must use the same one, default to hash. The latency one orders by the RTTs published in an earlier
+	// party and kept in the base folder, so the node that missed that party may pick another leader and join
+	// only after the failover, which costs up to a PartyTimeout
+	LeaderSelector string
+	// EnableBlameAgreement makes the parties exchange their blame after the failed ceremony, and only blame
+	// the nodes blamed by at least threshold parties
+	EnableBlameAgreement bool
+	// BlameAgreementTimeout defines how long do we wait for the blame of the other parties
+	BlameAgreementTimeout time.Duration
+	// BlameHistoryWindow defines how many recent ceremonies of a peer are used to compute its score, default to 100
+	BlameHistoryWindow int
+	// DeprioritizeFailingPeers makes the leader form the party without the peers whose score is below
+	// MinPeerScore, if the other peers are enough
+	DeprioritizeFailingPeers bool
+	// MinPeerScore is the score below which the peer is regarded as chronically failing, default to 0.5
+	MinPeerScore float64
+	// AddressPrefixes maps the name of the cosmos chain to the bech32 human readable part of its account
+	// address, the pool addresses on these chains are returned along with the evm and bitcoin ones
+	AddressPrefixes map[string]string
+	// PubKeyEncoding is the encoding of the pub keys we return on the API(bech32, compressed or uncompressed),
+	// default to bech32. The requests accept the pub keys in all of these encodings
+	PubKeyEncoding string
+	// Bech32Prefix is the account address prefix of the pool addresses and the bech32 pub keys, the pub key
+	// prefix has the "pub" suffix, default to oppy
+	Bech32Prefix string
+	// EnableTranscript records the signed transcript of the messages of each ceremony to the transcripts folder
+	// under the base folder, the transcript can be replayed offline to reproduce the hash check and blame
+	EnableTranscript bool
+	// EnableAudit writes the hash-chained audit log of the requests the node serves to the audit folder under
+	// the base folder
+	EnableAudit bool
+	// AuditMaxFileSize is the size of the audit log file above which the log is rotated, default to 64MiB
+	AuditMaxFileSize int64
+	// AuditMaxFiles is the number of the rotated audit log files we keep, zero keeps all of them
+	AuditMaxFiles int
 }
@@ -0,0 +1,167 @@
+package conversion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cosmos/btcutil/base58"
+	"github.com/cosmos/btcutil/bech32"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// AddressEVM is the EIP-55 checksummed hex address used by Ethereum, BSC and the other EVM chains
+	AddressEVM = "evm"
+	// AddressBitcoinP2PKH is the bitcoin mainnet pay-to-pubkey-hash address
+	AddressBitcoinP2PKH = "bitcoin_p2pkh"
+	// AddressBitcoinP2WPKH is the bitcoin mainnet pay-to-witness-pubkey-hash address
+	AddressBitcoinP2WPKH = "bitcoin_p2wpkh"
+	// AddressBitcoinTestnetP2PKH is the bitcoin testnet pay-to-pubkey-hash address
+	AddressBitcoinTestnetP2PKH = "bitcoin_testnet_p2pkh"
+	// AddressBitcoinTestnetP2WPKH is the bitcoin testnet pay-to-witness-pubkey-hash address
+	AddressBitcoinTestnetP2WPKH = "bitcoin_testnet_p2wpkh"
+	// AddressCosmos is the bech32 account address with the default prefix, the tss server uses its own prefix
+	AddressCosmos = "cosmos"
+)
+
+// AddressDeriver derives the address of a chain from the pool public key
+type AddressDeriver func(pk *btcec.PublicKey) (string, error)
+
+// builtinAddresses are the chains every pool pub key has the address on, besides the cosmos address of the codec
+var builtinAddresses = map[string]AddressDeriver{
+	AddressEVM:                  evmAddress,
+	AddressBitcoinP2PKH:         p2pkhAddress(0x00),
+	AddressBitcoinP2WPKH:        p2wpkhAddress("bc"),
+	AddressBitcoinTestnetP2PKH:  p2pkhAddress(0x6f),
+	AddressBitcoinTestnetP2WPKH: p2wpkhAddress("tb"),
+}
+
+// DeriveAddresses returns the address of the public key on all the built-in chains, the cosmos address has the
+// default prefix
+func DeriveAddresses(pk *btcec.PublicKey) (map[string]string, error) {
+	return DefaultPubKeyCodec.DeriveAddresses(pk)
+}
+
+// WithAddressPrefixes returns the codec that derives the bech32 account address of the cosmos chains as well, the
+// prefixes map the name of the chain to the human readable part of its address
+func (p PubKeyCodec) WithAddressPrefixes(prefixes map[string]string) (PubKeyCodec, error) {
+	addressPrefixes := make(map[string]string, len(p.addressPrefixes)+len(prefixes))
+	for name, hrp := range p.addressPrefixes {
+		addressPrefixes[name] = hrp
+	}
+	for name, hrp := range prefixes {
+		if len(name) == 0 || len(hrp) == 0 || strings.ToLower(hrp) != hrp {
+			return PubKeyCodec{}, fmt.Errorf("invalid bech32 human readable part(%s) of %s", hrp, name)
+		}
+		addressPrefixes[name] = hrp
+	}
+	p.addressPrefixes = addressPrefixes
+	return p, nil
+}
+
+// DeriveAddresses returns the address of the public key on the built-in chains and the cosmos chains of the codec,
+// the cosmos address has the prefix of the codec
+func (p PubKeyCodec) DeriveAddresses(pk *btcec.PublicKey) (map[string]string, error) {
+	if pk == nil {
+		return nil, errors.New("invalid public key")
+	}
+	addresses := make(map[string]string, len(builtinAddresses)+len(p.addressPrefixes))
+	for name, deriver := range builtinAddresses {
+		addr, err := deriver(pk)
+		if err != nil {
+			return nil, fmt.Errorf("fail to derive the %s address: %w", name, err)
+		}
+		addresses[name] = addr
+	}
+	addr, err := bech32Address(p.Prefix(), pk)
+	if err != nil {
+		return nil, fmt.Errorf("fail to derive the %s address: %w", AddressCosmos, err)
+	}
+	addresses[AddressCosmos] = addr
+	for name, hrp := range p.addressPrefixes {
+		addr, err := bech32Address(hrp, pk)
+		if err != nil {
+			return nil, fmt.Errorf("fail to derive the %s address: %w", name, err)
+		}
+		addresses[name] = addr
+	}
+	return addresses, nil
+}
+
+// GetAddresses returns the address of the pool pub key on the built-in chains and the cosmos chains of the codec
+func (p PubKeyCodec) GetAddresses(poolPubKey string) (map[string]string, error) {
+	pubKey, err := ParsePubKey(poolPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse pool pub key(%s): %w", poolPubKey, err)
+	}
+	pk, err := btcec.ParsePubKey(pubKey.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse the secp256k1 pub key: %w", err)
+	}
+	return p.DeriveAddresses(pk)
+}
+
+// GetAddresses returns the address on all the built-in chains of the pool pub key
+func GetAddresses(poolPubKey string) (map[string]string, error) {
+	return DefaultPubKeyCodec.GetAddresses(poolPubKey)
+}
+
+// hash160 is the ripemd160 of the sha256 of the compressed public key
+func hash160(pk *btcec.PublicKey) []byte {
+	sha := sha256.Sum256(pk.SerializeCompressed())
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+func evmAddress(pk *btcec.PublicKey) (string, error) {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(pk.SerializeUncompressed()[1:])
+	addr := hex.EncodeToString(hasher.Sum(nil)[12:])
+
+	// EIP-55 checksum, the letter is upper case if the nibble of the hash of the address is at least 8
+	hasher = sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(addr))
+	checksum := hasher.Sum(nil)
+	ret := []byte(addr)
+	for i, el := range ret {
+		nibble := checksum[i/2] >> 4
+		if i%2 == 1 {
+			nibble = checksum[i/2] & 0x0f
+		}
+		if el > '9' && nibble >= 8 {
+			ret[i] = el - 'a' + 'A'
+		}
+	}
+	return "0x" + string(ret), nil
+}
+
+func p2pkhAddress(version byte) AddressDeriver {
+	return func(pk *btcec.PublicKey) (string, error) {
+		return base58.CheckEncode(hash160(pk), version), nil
+	}
+}
+
+func p2wpkhAddress(hrp string) AddressDeriver {
+	return func(pk *btcec.PublicKey) (string, error) {
+		program, err := bech32.ConvertBits(hash160(pk), 8, 5, true)
+		if err != nil {
+			return "", err
+		}
+		// the witness version 0 goes before the program
+		return bech32.Encode(hrp, append([]byte{0x00}, program...))
+	}
+}
+
+func bech32Address(hrp string, pk *btcec.PublicKey) (string, error) {
+	program, err := bech32.ConvertBits(hash160(pk), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, program)
+}
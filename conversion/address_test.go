@@ -0,0 +1,90 @@
+package conversion
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	cossecp256 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdkbech32 "github.com/cosmos/cosmos-sdk/types/bech32"
+	. "gopkg.in/check.v1"
+)
+
+type AddressTestSuite struct{}
+
+var _ = Suite(&AddressTestSuite{})
+
+// the public key of the private key 1
+func getTestAddressPubKey() *btcec.PublicKey {
+	_, pk := btcec.PrivKeyFromBytes([]byte{0x01})
+	return pk
+}
+
+func (AddressTestSuite) TestDeriveAddresses(c *C) {
+	codec, err := NewPubKeyCodec(PubKeyEncodingBech32, "jolt")
+	c.Assert(err, IsNil)
+	addresses, err := codec.DeriveAddresses(getTestAddressPubKey())
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressEVM], Equals, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	c.Assert(addresses[AddressBitcoinP2PKH], Equals, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	c.Assert(addresses[AddressBitcoinP2WPKH], Equals, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	c.Assert(addresses[AddressBitcoinTestnetP2PKH], Equals, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r")
+	c.Assert(addresses[AddressBitcoinTestnetP2WPKH], Equals, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx")
+	pk := cossecp256.PubKey{Key: getTestAddressPubKey().SerializeCompressed()}
+	expected, err := sdkbech32.ConvertAndEncode("jolt", pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressCosmos], Equals, expected)
+
+	// the package level one uses the default codec
+	addresses, err = DeriveAddresses(getTestAddressPubKey())
+	c.Assert(err, IsNil)
+	expected, err = sdkbech32.ConvertAndEncode(DefaultBech32Prefix, pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressCosmos], Equals, expected)
+
+	_, err = codec.DeriveAddresses(nil)
+	c.Assert(err, NotNil)
+}
+
+func (AddressTestSuite) TestWithAddressPrefixes(c *C) {
+	_, err := DefaultPubKeyCodec.WithAddressPrefixes(map[string]string{"bnb": ""})
+	c.Assert(err, NotNil)
+	_, err = DefaultPubKeyCodec.WithAddressPrefixes(map[string]string{"bnb": "BNB"})
+	c.Assert(err, NotNil)
+	_, err = DefaultPubKeyCodec.WithAddressPrefixes(map[string]string{"": "bnb"})
+	c.Assert(err, NotNil)
+
+	codec, err := NewPubKeyCodec(PubKeyEncodingBech32, "jolt")
+	c.Assert(err, IsNil)
+	codec, err = codec.WithAddressPrefixes(map[string]string{"cosmoshub": "cosmos"})
+	c.Assert(err, IsNil)
+	addresses, err := codec.DeriveAddresses(getTestAddressPubKey())
+	c.Assert(err, IsNil)
+	pk := cossecp256.PubKey{Key: getTestAddressPubKey().SerializeCompressed()}
+	expected, err := sdkbech32.ConvertAndEncode("cosmos", pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(addresses["cosmoshub"], Equals, expected)
+	expected, err = sdkbech32.ConvertAndEncode("jolt", pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressCosmos], Equals, expected)
+
+	// the prefixes of the codec do not leak into the other codecs
+	addresses, err = DeriveAddresses(getTestAddressPubKey())
+	c.Assert(err, IsNil)
+	_, ok := addresses["cosmoshub"]
+	c.Assert(ok, Equals, false)
+}
+
+func (AddressTestSuite) TestGetAddresses(c *C) {
+	codec, err := NewPubKeyCodec(PubKeyEncodingBech32, "jolt")
+	c.Assert(err, IsNil)
+	pk := cossecp256.PubKey{Key: getTestAddressPubKey().SerializeCompressed()}
+	poolPubKey, err := codec.EncodePubKey(&pk)
+	c.Assert(err, IsNil)
+	addresses, err := codec.GetAddresses(poolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressEVM], Equals, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	expected, err := sdkbech32.ConvertAndEncode("jolt", pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(addresses[AddressCosmos], Equals, expected)
+
+	_, err = codec.GetAddresses("invalid")
+	c.Assert(err, NotNil)
+}
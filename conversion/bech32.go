@@ -4,6 +4,9 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// SetupBech32Prefix sets the oppy prefixes to the global sdk config
+//
+// Deprecated: the library no longer reads the global sdk config, use PubKeyCodec to encode the pub keys.
 func SetupBech32Prefix() {
 	config := sdk.GetConfig()
 	config.SetBech32PrefixForAccount("oppy", "oppypub")
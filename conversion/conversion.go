@@ -17,7 +17,6 @@ import (
 	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
 	cossecp256 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
 	crypto2 "github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
 
@@ -64,7 +63,7 @@ func PartyIDtoPubKey(party *btss.PartyID) (string, error) {
 	pk := coskey.PubKey{
 		Key: partyKeyBytes,
 	}
-	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pk)
+	pubKey, err := MarshalPubKey(&pk)
 	if err != nil {
 		return "", err
 	}
@@ -125,7 +124,7 @@ func GetParties(keys []string, localPartyKey string) ([]*btss.PartyID, *btss.Par
 	var unSortedPartiesID []*btss.PartyID
 	sort.Strings(keys)
 	for idx, item := range keys {
-		pk, err := legacybech32.UnmarshalPubKey(legacybech32.AccPK, item)
+		pk, err := ParsePubKey(item)
 		if err != nil {
 			return nil, nil, fmt.Errorf("fail to get account pub key address(%s): %w", item, err)
 		}
@@ -174,7 +173,7 @@ func GetTssPubKey(pubKeyPoint *crypto.ECPoint) (string, sdk.AccAddress, error) {
 		Key: tssPubKey.SerializeCompressed(),
 	}
 
-	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &compressedPubkey)
+	pubKey, err := MarshalPubKey(&compressedPubkey)
 	addr := sdk.AccAddress(compressedPubkey.Address().Bytes())
 	return pubKey, addr, err
 }
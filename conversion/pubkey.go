@@ -0,0 +1,169 @@
+package conversion
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cossecp256 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+const (
+	// PubKeyEncodingBech32 is the legacy amino bech32 pub key with the account pub key prefix
+	PubKeyEncodingBech32 = "bech32"
+	// PubKeyEncodingCompressed is the hex of the compressed secp256k1 pub key, or the raw ed25519 pub key
+	PubKeyEncodingCompressed = "compressed"
+	// PubKeyEncodingUncompressed is the hex of the uncompressed secp256k1 pub key, or the raw ed25519 pub key
+	PubKeyEncodingUncompressed = "uncompressed"
+
+	// DefaultBech32Prefix is the account address prefix, the pub key prefix has the "pub" suffix. The pub keys
+	// in the local state and the messages among the parties are always in bech32 with this prefix
+	DefaultBech32Prefix = "oppy"
+
+	pubKeyBytesLenUncompressed = 65
+)
+
+// PubKeyCodec encodes the pub keys and the addresses with its own encoding and prefix instead of the global sdk
+// config, so the library can share the process with the other chains. The zero value is the DefaultPubKeyCodec
+type PubKeyCodec struct {
+	encoding string
+	prefix   string
+	// addressPrefixes maps the name of the cosmos chain to the human readable part of its account address
+	addressPrefixes map[string]string
+}
+
+// DefaultPubKeyCodec encodes the pub keys in bech32 with the default prefix
+var DefaultPubKeyCodec = PubKeyCodec{
+	encoding: PubKeyEncodingBech32,
+	prefix:   DefaultBech32Prefix,
+}
+
+// NewPubKeyCodec create a new instance of PubKeyCodec, empty encoding and prefix use the default ones
+func NewPubKeyCodec(encoding, prefix string) (PubKeyCodec, error) {
+	if len(encoding) == 0 {
+		encoding = PubKeyEncodingBech32
+	}
+	switch encoding {
+	case PubKeyEncodingBech32, PubKeyEncodingCompressed, PubKeyEncodingUncompressed:
+	default:
+		return PubKeyCodec{}, fmt.Errorf("pub key encoding(%s) is not supported", encoding)
+	}
+	if len(prefix) == 0 {
+		prefix = DefaultBech32Prefix
+	}
+	if strings.ToLower(prefix) != prefix {
+		return PubKeyCodec{}, fmt.Errorf("invalid bech32 prefix(%s)", prefix)
+	}
+	return PubKeyCodec{
+		encoding: encoding,
+		prefix:   prefix,
+	}, nil
+}
+
+// Prefix returns the bech32 account address prefix of the codec
+func (p PubKeyCodec) Prefix() string {
+	if len(p.prefix) == 0 {
+		return DefaultBech32Prefix
+	}
+	return p.prefix
+}
+
+// EncodePubKey returns the pub key in the encoding of the codec
+func (p PubKeyCodec) EncodePubKey(pk cryptotypes.PubKey) (string, error) {
+	if pk == nil {
+		return "", errors.New("invalid pub key")
+	}
+	switch p.encoding {
+	case PubKeyEncodingCompressed:
+		return hex.EncodeToString(pk.Bytes()), nil
+	case PubKeyEncodingUncompressed:
+		if _, ok := pk.(*cossecp256.PubKey); !ok {
+			return hex.EncodeToString(pk.Bytes()), nil
+		}
+		bPk, err := btcec.ParsePubKey(pk.Bytes())
+		if err != nil {
+			return "", fmt.Errorf("fail to parse the secp256k1 pub key: %w", err)
+		}
+		return hex.EncodeToString(bPk.SerializeUncompressed()), nil
+	default:
+		return bech32.ConvertAndEncode(p.Prefix()+"pub", legacy.Cdc.MustMarshal(pk))
+	}
+}
+
+// Encode converts the pub key in any of the supported encodings to the encoding of the codec
+func (p PubKeyCodec) Encode(pubKey string) (string, error) {
+	if len(pubKey) == 0 {
+		return "", nil
+	}
+	pk, err := ParsePubKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	return p.EncodePubKey(pk)
+}
+
+// EncodeAddress returns the bech32 account address with the prefix of the codec
+func (p PubKeyCodec) EncodeAddress(addr []byte) (string, error) {
+	if len(addr) == 0 {
+		return "", nil
+	}
+	return bech32.ConvertAndEncode(p.Prefix(), addr)
+}
+
+// ParsePubKey parses the secp256k1 or ed25519 pub key in bech32 with any prefix, or the hex of the compressed,
+// uncompressed or raw key
+func ParsePubKey(pubKey string) (cryptotypes.PubKey, error) {
+	if buf, err := hex.DecodeString(strings.TrimPrefix(pubKey, "0x")); err == nil {
+		switch len(buf) {
+		case coskey.PubKeySize:
+			return &coskey.PubKey{Key: buf}, nil
+		case btcec.PubKeyBytesLenCompressed, pubKeyBytesLenUncompressed:
+			bPk, err := btcec.ParsePubKey(buf)
+			if err != nil {
+				return nil, fmt.Errorf("fail to parse the secp256k1 pub key(%s): %w", pubKey, err)
+			}
+			return &cossecp256.PubKey{Key: bPk.SerializeCompressed()}, nil
+		}
+	}
+	_, buf, err := bech32.DecodeAndConvert(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode the pub key(%s): %w", pubKey, err)
+	}
+	pk, err := legacy.PubKeyFromBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the pub key(%s): %w", pubKey, err)
+	}
+	return pk, nil
+}
+
+// MarshalPubKey returns the pub key in bech32 with the default prefix, which is the form used among the parties
+func MarshalPubKey(pk cryptotypes.PubKey) (string, error) {
+	return DefaultPubKeyCodec.EncodePubKey(pk)
+}
+
+// NormalizePubKey converts the pub key in any of the supported encodings to the form used among the parties
+func NormalizePubKey(pubKey string) (string, error) {
+	return DefaultPubKeyCodec.Encode(pubKey)
+}
+
+// NormalizePubKeys converts the pub keys in any of the supported encodings to the form used among the parties
+func NormalizePubKeys(pubKeys []string) ([]string, error) {
+	if pubKeys == nil {
+		return nil, nil
+	}
+	ret := make([]string, len(pubKeys))
+	for i, el := range pubKeys {
+		pk, err := NormalizePubKey(el)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = pk
+	}
+	return ret, nil
+}
@@ -0,0 +1,102 @@
+package conversion
+
+import (
+	"encoding/hex"
+	"strings"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cossecp256 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
+	. "gopkg.in/check.v1"
+)
+
+type PubKeyTestSuite struct{}
+
+var _ = Suite(&PubKeyTestSuite{})
+
+func (PubKeyTestSuite) TestNewPubKeyCodec(c *C) {
+	codec, err := NewPubKeyCodec("", "")
+	c.Assert(err, IsNil)
+	c.Assert(codec, DeepEquals, DefaultPubKeyCodec)
+	c.Assert(PubKeyCodec{}.Prefix(), Equals, DefaultBech32Prefix)
+	_, err = NewPubKeyCodec("base58", "")
+	c.Assert(err, NotNil)
+	_, err = NewPubKeyCodec(PubKeyEncodingBech32, "THOR")
+	c.Assert(err, NotNil)
+}
+
+func (PubKeyTestSuite) TestEncodePubKey(c *C) {
+	SetupBech32Prefix()
+	pk := &cossecp256.PubKey{Key: getTestAddressPubKey().SerializeCompressed()}
+	legacyPubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, pk)
+	c.Assert(err, IsNil)
+
+	// the default codec is compatible with the legacy bech32 pub keys
+	pubKey, err := MarshalPubKey(pk)
+	c.Assert(err, IsNil)
+	c.Assert(pubKey, Equals, legacyPubKey)
+
+	thorCodec, err := NewPubKeyCodec(PubKeyEncodingBech32, "thor")
+	c.Assert(err, IsNil)
+	thorPubKey, err := thorCodec.EncodePubKey(pk)
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(thorPubKey, "thorpub1"), Equals, true)
+	addr, err := thorCodec.EncodeAddress(pk.Address())
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(addr, "thor1"), Equals, true)
+	// we never touch the global sdk config
+	c.Assert(sdk.GetConfig().GetBech32AccountPubPrefix(), Equals, "oppypub")
+
+	compressedCodec, err := NewPubKeyCodec(PubKeyEncodingCompressed, "")
+	c.Assert(err, IsNil)
+	compressed, err := compressedCodec.EncodePubKey(pk)
+	c.Assert(err, IsNil)
+	c.Assert(compressed, Equals, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+
+	uncompressedCodec, err := NewPubKeyCodec(PubKeyEncodingUncompressed, "")
+	c.Assert(err, IsNil)
+	uncompressed, err := uncompressedCodec.EncodePubKey(pk)
+	c.Assert(err, IsNil)
+	c.Assert(uncompressed, Equals, hex.EncodeToString(getTestAddressPubKey().SerializeUncompressed()))
+
+	// all the encodings are converted back to the same pub key
+	for _, el := range []string{legacyPubKey, thorPubKey, compressed, "0x" + compressed, uncompressed} {
+		normalized, err := NormalizePubKey(el)
+		c.Assert(err, IsNil)
+		c.Assert(normalized, Equals, legacyPubKey)
+		converted, err := uncompressedCodec.Encode(el)
+		c.Assert(err, IsNil)
+		c.Assert(converted, Equals, uncompressed)
+	}
+}
+
+func (PubKeyTestSuite) TestParsePubKey(c *C) {
+	SetupBech32Prefix()
+	for _, el := range testPubKeys {
+		pk, err := ParsePubKey(el)
+		c.Assert(err, IsNil)
+		_, ok := pk.(*coskey.PubKey)
+		c.Assert(ok, Equals, true)
+
+		// the ed25519 node pub keys have no compressed form
+		raw, err := NewPubKeyCodec(PubKeyEncodingUncompressed, "")
+		c.Assert(err, IsNil)
+		rawPubKey, err := raw.EncodePubKey(pk)
+		c.Assert(err, IsNil)
+		c.Assert(rawPubKey, Equals, hex.EncodeToString(pk.Bytes()))
+		normalized, err := NormalizePubKey(rawPubKey)
+		c.Assert(err, IsNil)
+		c.Assert(normalized, Equals, el)
+	}
+
+	for _, el := range []string{"invalid", "0x1234", "04" + strings.Repeat("00", 64), testPubKeys[0][:len(testPubKeys[0])-1]} {
+		_, err := ParsePubKey(el)
+		c.Assert(err, NotNil)
+	}
+	_, err := NormalizePubKeys([]string{testPubKeys[0], "invalid"})
+	c.Assert(err, NotNil)
+	pubKeys, err := NormalizePubKeys(nil)
+	c.Assert(err, IsNil)
+	c.Assert(pubKeys, IsNil)
+}
@@ -5,7 +5,6 @@ import (
 	"math/rand"
 
 	"github.com/blang/semver"
-	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
 	atypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/tendermint/tendermint/crypto/secp256k1"
@@ -14,7 +13,7 @@ import (
 // GetRandomPubKey for test
 func GetRandomPubKey() string {
 	_, pubKey, _ := atypes.KeyTestPubAddr()
-	bech32PubKey, _ := legacybech32.MarshalPubKey(legacybech32.AccPK, pubKey)
+	bech32PubKey, _ := MarshalPubKey(pubKey)
 	return bech32PubKey
 }
 
@@ -7,10 +7,19 @@ import (
 
 // Response keygen response
 type Response struct {
-	PubKey      string        `json:"pub_key"`
-	PoolAddress string        `json:"pool_address"`
-	Status      common.Status `json:"status"`
-	Blame       blame.Blame   `json:"blame"`
+	PubKey      string            `json:"pub_key"`
+	PoolAddress string            `json:"pool_address"`
+	Addresses   map[string]string `json:"addresses,omitempty"`
+	Status      common.Status     `json:"status"`
+	// Blame can be non-empty even if the keygen succeeds, it then names the node that sent the different broadcast
+	// messages to the peers, and Evidence has both of them
+	Blame      blame.Blame      `json:"blame"`
+	Evidence   []blame.Evidence `json:"evidence,omitempty"`
+	BlameVotes map[string]int   `json:"blame_votes,omitempty"`
+	// Round is the round the keygen fails in
+	Round string `json:"round,omitempty"`
+	// FailedLeaders are the leaders we failed over from in the join party, reported even if the keygen succeeds
+	FailedLeaders []string `json:"failed_leaders,omitempty"`
 }
 
 // NewResponse create a new instance of keygen.Response
@@ -150,6 +150,12 @@ func (tKeyGen *TssKeyGen) processKeyGen(errChan chan struct{},
 	tKeyGen.logger.Debug().Msg("start to read messages from local party")
 	tssConf := tKeyGen.tssCommonStruct.GetConf()
 	blameMgr := tKeyGen.tssCommonStruct.GetBlameMgr()
+	// each round can have its own deadline, and the whole keygen can have a hard deadline, along with the timeout
+	// on no message for KeyGenTimeout
+	roundTimer := common.NewRoundTimer(tssConf.KeyGenRoundTimeout)
+	defer roundTimer.Stop()
+	deadline := common.NewDeadline(tssConf.KeyGenDeadline)
+	defer deadline.Stop()
 	for {
 		select {
 		case <-errChan: // when keyGenParty return
@@ -159,54 +165,23 @@ func (tKeyGen *TssKeyGen) processKeyGen(errChan chan struct{},
 		case <-tKeyGen.stopChan: // when TSS processor receive signal to quit
 			return nil, errors.New("received exit signal")
 
+		case <-roundTimer.C():
+			tKeyGen.logger.Error().Msgf("fail to finish the keygen round with %s", tssConf.KeyGenRoundTimeout.String())
+			return nil, tKeyGen.processTimeout()
+
+		case <-deadline.C():
+			tKeyGen.logger.Error().Msgf("fail to finish the keygen before the deadline %s", tssConf.KeyGenDeadline.String())
+			return nil, tKeyGen.processTimeout()
+
 		case <-time.After(tssConf.KeyGenTimeout):
 			// we bail out after KeyGenTimeoutSeconds
 			tKeyGen.logger.Error().Msgf("fail to generate message with %s", tssConf.KeyGenTimeout.String())
-			lastMsg := blameMgr.GetLastMsg()
-			failReason := blameMgr.GetBlame().FailReason
-			if failReason == "" {
-				failReason = blame.TssTimeout
-			}
-			if lastMsg == nil {
-				tKeyGen.logger.Error().Msg("fail to start the keygen, the last produced message of this node is none")
-				return nil, errors.New("timeout before shared message is generated")
-			}
-			blameNodesUnicast, err := blameMgr.GetUnicastBlame(messages.KEYGEN2aUnicast)
-			if err != nil {
-				tKeyGen.logger.Error().Err(err).Msg("error in get unicast blame")
-			}
-			tKeyGen.tssCommonStruct.P2PPeersLock.RLock()
-			threshold, err := conversion.GetThreshold(len(tKeyGen.tssCommonStruct.P2PPeers) + 1)
-			tKeyGen.tssCommonStruct.P2PPeersLock.RUnlock()
-			if err != nil {
-				tKeyGen.logger.Error().Err(err).Msg("error in get the threshold to generate blame")
-			}
-
-			if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
-				blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
-			}
-			blameNodesBroadcast, err := blameMgr.GetBroadcastBlame(lastMsg.Type())
-			if err != nil {
-				tKeyGen.logger.Error().Err(err).Msg("error in get broadcast blame")
-			}
-			blameMgr.GetBlame().AddBlameNodes(blameNodesBroadcast...)
-
-			// if we cannot find the blame node, we check whether everyone send me the share
-			if len(blameMgr.GetBlame().BlameNodes) == 0 {
-				blameNodesMisingShare, isUnicast, err := blameMgr.TssMissingShareBlame(messages.TSSKEYGENROUNDS)
-				if err != nil {
-					tKeyGen.logger.Error().Err(err).Msg("fail to get the node of missing share ")
-				}
-				if len(blameNodesMisingShare) > 0 && len(blameNodesMisingShare) <= threshold {
-					blameMgr.GetBlame().AddBlameNodes(blameNodesMisingShare...)
-					blameMgr.GetBlame().IsUnicast = isUnicast
-				}
-			}
-			return nil, blame.ErrTssTimeOut
+			return nil, tKeyGen.processTimeout()
 
 		case msg := <-outCh:
 			tKeyGen.logger.Debug().Msgf(">>>>>>>>>>msg: %s", msg.String())
 			blameMgr.SetLastMsg(msg)
+			roundTimer.Update(msg)
 			err := tKeyGen.tssCommonStruct.ProcessOutCh(msg, messages.TSSKeyGenMsg)
 			if err != nil {
 				tKeyGen.logger.Error().Err(err).Msg("fail to process the message")
@@ -236,3 +211,53 @@ func (tKeyGen *TssKeyGen) processKeyGen(errChan chan struct{},
 		}
 	}
 }
+
+// processTimeout blames the parties that cause the keygen timeout
+func (tKeyGen *TssKeyGen) processTimeout() error {
+	blameMgr := tKeyGen.tssCommonStruct.GetBlameMgr()
+	lastMsg := blameMgr.GetLastMsg()
+	failReason := blameMgr.GetBlame().FailReason
+	if failReason == "" {
+		failReason = blame.TssTimeout
+	}
+	if lastMsg == nil {
+		tKeyGen.logger.Error().Msg("fail to start the keygen, the last produced message of this node is none")
+		return errors.New("timeout before shared message is generated")
+	}
+	// we name the parties missing in the round that expired
+	if tKeyGen.tssCommonStruct.BlameMissingRound(lastMsg, messages.TSSKeyGenRoundMsgs, failReason) {
+		return blame.ErrTssTimeOut
+	}
+	blameNodesUnicast, err := blameMgr.GetUnicastBlame(messages.KEYGEN2aUnicast)
+	if err != nil {
+		tKeyGen.logger.Error().Err(err).Msg("error in get unicast blame")
+	}
+	tKeyGen.tssCommonStruct.P2PPeersLock.RLock()
+	threshold, err := conversion.GetThreshold(len(tKeyGen.tssCommonStruct.P2PPeers) + 1)
+	tKeyGen.tssCommonStruct.P2PPeersLock.RUnlock()
+	if err != nil {
+		tKeyGen.logger.Error().Err(err).Msg("error in get the threshold to generate blame")
+	}
+
+	if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
+		blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
+	}
+	blameNodesBroadcast, err := blameMgr.GetBroadcastBlame(lastMsg.Type())
+	if err != nil {
+		tKeyGen.logger.Error().Err(err).Msg("error in get broadcast blame")
+	}
+	blameMgr.GetBlame().AddBlameNodes(blameNodesBroadcast...)
+
+	// if we cannot find the blame node, we check whether everyone send me the share
+	if len(blameMgr.GetBlame().BlameNodes) == 0 {
+		blameNodesMisingShare, isUnicast, err := blameMgr.TssMissingShareBlame(messages.TSSKEYGENROUNDS)
+		if err != nil {
+			tKeyGen.logger.Error().Err(err).Msg("fail to get the node of missing share ")
+		}
+		if len(blameNodesMisingShare) > 0 && len(blameNodesMisingShare) <= threshold {
+			blameMgr.GetBlame().AddBlameNodes(blameNodesMisingShare...)
+			blameMgr.GetBlame().IsUnicast = isUnicast
+		}
+	}
+	return blame.ErrTssTimeOut
+}
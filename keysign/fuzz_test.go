@@ -0,0 +1,118 @@
+package keysign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/binance-chain/tss-lib/ecdsa/signing"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+)
+
+// fuzzStream is the stream of the remote peer that carries the fuzzed bytes, the reply is written to out
+type fuzzStream struct {
+	network.Stream
+	in  *bytes.Buffer
+	out *bytes.Buffer
+}
+
+func (s fuzzStream) Read(buf []byte) (int, error)     { return s.in.Read(buf) }
+func (s fuzzStream) Write(buf []byte) (int, error)    { return s.out.Write(buf) }
+func (s fuzzStream) Close() error                     { return nil }
+func (s fuzzStream) Reset() error                     { return nil }
+func (s fuzzStream) SetReadDeadline(time.Time) error  { return nil }
+func (s fuzzStream) SetWriteDeadline(time.Time) error { return nil }
+func (s fuzzStream) Protocol() protocol.ID            { return signatureNotifierProtocol }
+func (s fuzzStream) Scope() network.StreamScope       { return &network.NullScope{} }
+func (s fuzzStream) Conn() network.Conn               { return fuzzConn{} }
+
+type fuzzConn struct {
+	network.Conn
+}
+
+func (c fuzzConn) RemotePeer() peer.ID { return "fuzz" }
+
+func newFuzzStream(data []byte) network.Stream {
+	in := &bytes.Buffer{}
+	header := make([]byte, p2p.LengthHeader)
+	binary.LittleEndian.PutUint32(header, uint32(len(data)))
+	in.Write(header)
+	in.Write(data)
+	return fuzzStream{in: in, out: &bytes.Buffer{}}
+}
+
+func FuzzSignatureNotifierHandleStream(f *testing.F) {
+	conversion.SetupBech32Prefix()
+	poolPubKey := "oppypub1addwnpepqt5expfkfrk4kaujcyq7pmwu3sgycrzrtx64vdrdknusvx0prs096lf25u6"
+	msg, err := base64.StdEncoding.DecodeString("br8L1Aq3VxJKrl+OQAUhtgtDzkAOTV1hc06qtkdA1dE=")
+	if err != nil {
+		f.Fatal(err)
+	}
+	messageID, err := common.MsgToHashString(msg)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, el := range []string{"sig1.json", "sig_invalid.json"} {
+		content, err := ioutil.ReadFile("../test_data/signature_notify/" + el)
+		if err != nil {
+			f.Fatal(err)
+		}
+		var signature signing.SignatureData
+		if err := json.Unmarshal(content, &signature); err != nil {
+			f.Fatal(err)
+		}
+		sig, err := proto.Marshal(signature.GetSignature())
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, signatures := range [][][]byte{{sig}, {sig, sig}, {{}}} {
+			buf, err := proto.Marshal(&messages.KeysignSignature{
+				ID:            messageID,
+				Signatures:    signatures,
+				KeysignStatus: messages.KeysignSignature_Success,
+			})
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(buf)
+		}
+	}
+	buf, err := proto.Marshal(&messages.KeysignSignature{ID: messageID, KeysignStatus: messages.KeysignSignature_Failed})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf)
+
+	s := &SignatureNotifier{
+		logger:       zerolog.Nop(),
+		notifierLock: &sync.Mutex{},
+		notifiers:    make(map[string]*Notifier),
+		messages:     make(chan *signatureItem),
+		streamMgr:    p2p.NewStreamMgr(),
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		n, err := NewNotifier(messageID, [][]byte{msg}, poolPubKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.notifierLock.Lock()
+		s.notifiers[messageID] = n
+		s.notifierLock.Unlock()
+		s.handleStream(newFuzzStream(data))
+		s.streamMgr.ReleaseStream(messageID)
+	})
+}
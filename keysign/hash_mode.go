@@ -0,0 +1,85 @@
+package keysign
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// HashModePrehashed is the message already hashed by the caller, it must be exactly 32 bytes
+	HashModePrehashed = "prehashed"
+	// HashModeSHA256 signs the sha256 of the message
+	HashModeSHA256 = "sha256"
+	// HashModeKeccak256 signs the keccak256 of the message, as ethereum does
+	HashModeKeccak256 = "keccak256"
+	// HashModeDoubleSHA256 signs the sha256 of the sha256 of the message, as bitcoin does
+	HashModeDoubleSHA256 = "double_sha256"
+)
+
+// ErrInvalidMessage indicates the message cannot be signed in its hash mode
+var ErrInvalidMessage = errors.New("invalid message")
+
+// HashMessage returns the 32 bytes hash of the message we sign in the hash mode, the message without the hash mode is
+// signed as is if it is no longer than the hash
+func HashMessage(msg []byte, mode string) ([]byte, error) {
+	switch mode {
+	case "":
+		if len(msg) == 0 || len(msg) > sha256.Size {
+			return nil, fmt.Errorf("%w: message of %d bytes without the hash mode", ErrInvalidMessage, len(msg))
+		}
+		return msg, nil
+	case HashModePrehashed:
+		if len(msg) != sha256.Size {
+			return nil, fmt.Errorf("%w: prehashed message must be %d bytes, got %d bytes", ErrInvalidMessage, sha256.Size, len(msg))
+		}
+		return msg, nil
+	case HashModeSHA256:
+		h := sha256.Sum256(msg)
+		return h[:], nil
+	case HashModeKeccak256:
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write(msg)
+		return hasher.Sum(nil), nil
+	case HashModeDoubleSHA256:
+		h := sha256.Sum256(msg)
+		h = sha256.Sum256(h[:])
+		return h[:], nil
+	default:
+		return nil, fmt.Errorf("hash mode(%s) is not supported", mode)
+	}
+}
+
+// ApplyHashModes returns the request whose messages are replaced with the hash we sign, so all the parties sign the
+// same hash regardless of how the message is hashed
+func ApplyHashModes(req Request) (Request, error) {
+	if len(req.HashModes) > 1 && len(req.HashModes) != len(req.Messages) {
+		return req, fmt.Errorf("%d hash modes for %d messages", len(req.HashModes), len(req.Messages))
+	}
+	msgs := make([]string, len(req.Messages))
+	for i, el := range req.Messages {
+		msg, err := base64.StdEncoding.DecodeString(el)
+		if err != nil {
+			return req, fmt.Errorf("fail to decode message(%s): %w", el, err)
+		}
+		mode := ""
+		switch len(req.HashModes) {
+		case 0:
+		case 1:
+			mode = req.HashModes[0]
+		default:
+			mode = req.HashModes[i]
+		}
+		hash, err := HashMessage(msg, mode)
+		if err != nil {
+			return req, fmt.Errorf("message %d: %w", i, err)
+		}
+		msgs[i] = base64.StdEncoding.EncodeToString(hash)
+	}
+	req.Messages = msgs
+	req.HashModes = nil
+	return req, nil
+}
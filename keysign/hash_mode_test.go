@@ -0,0 +1,77 @@
+package keysign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+type HashModeTestSuite struct{}
+
+var _ = Suite(&HashModeTestSuite{})
+
+func (HashModeTestSuite) TestHashMessage(c *C) {
+	msg := []byte("abc")
+	expected := map[string]string{
+		HashModeSHA256:       "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		HashModeKeccak256:    "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		HashModeDoubleSHA256: "4f8b42c22dd3729b519ba6f68d2da7cc5b2d606d05daed5ad5128cc03e6c6358",
+	}
+	for mode, el := range expected {
+		h, err := HashMessage(msg, mode)
+		c.Assert(err, IsNil)
+		c.Assert(hex.EncodeToString(h), Equals, el)
+	}
+
+	prehashed := bytes.Repeat([]byte{0x01}, 32)
+	h, err := HashMessage(prehashed, HashModePrehashed)
+	c.Assert(err, IsNil)
+	c.Assert(h, DeepEquals, prehashed)
+	_, err = HashMessage(msg, HashModePrehashed)
+	c.Assert(errors.Is(err, ErrInvalidMessage), Equals, true)
+	_, err = HashMessage(append(prehashed, 0x01), HashModePrehashed)
+	c.Assert(errors.Is(err, ErrInvalidMessage), Equals, true)
+
+	// the message without the hash mode is signed as is, but never truncated
+	h, err = HashMessage(msg, "")
+	c.Assert(err, IsNil)
+	c.Assert(h, DeepEquals, msg)
+	_, err = HashMessage(append(prehashed, 0x01), "")
+	c.Assert(errors.Is(err, ErrInvalidMessage), Equals, true)
+	_, err = HashMessage(msg, "md5")
+	c.Assert(err, NotNil)
+}
+
+func (HashModeTestSuite) TestApplyHashModes(c *C) {
+	msgs := []string{base64.StdEncoding.EncodeToString([]byte("abc")), base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x01}, 32))}
+	req := NewRequest("pool", msgs, 10, nil, "0.14.0")
+	req.HashModes = []string{HashModeSHA256, HashModePrehashed}
+	hashed, err := ApplyHashModes(req)
+	c.Assert(err, IsNil)
+	c.Assert(hashed.HashModes, IsNil)
+	sum, err := HashMessage([]byte("abc"), HashModeSHA256)
+	c.Assert(err, IsNil)
+	c.Assert(hashed.Messages, DeepEquals, []string{base64.StdEncoding.EncodeToString(sum), msgs[1]})
+	// the request of the caller is not changed
+	c.Assert(req.Messages, DeepEquals, msgs)
+
+	// the single hash mode applies to all the messages
+	req.HashModes = []string{HashModeKeccak256}
+	hashed, err = ApplyHashModes(req)
+	c.Assert(err, IsNil)
+	c.Assert(hashed.Messages, HasLen, 2)
+
+	req.HashModes = []string{HashModePrehashed}
+	_, err = ApplyHashModes(req)
+	c.Assert(errors.Is(err, ErrInvalidMessage), Equals, true)
+	req.HashModes = []string{HashModeSHA256, HashModeSHA256, HashModeSHA256}
+	_, err = ApplyHashModes(req)
+	c.Assert(err, NotNil)
+	req.HashModes = nil
+	req.Messages = []string{"invalid base64"}
+	_, err = ApplyHashModes(req)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,59 @@
+package keysign
+
+import (
+	"fmt"
+
+	tsslibcommon "github.com/binance-chain/tss-lib/common"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+)
+
+// TssKeySignIA is the GG20 keysign with identifiable abort in the message dependent round only. It splits the
+// keysign into the message independent presign rounds and the online round, where each signer sends its signature
+// share. The share is checked against the commitments of the signer from the presign rounds, so the signer of the
+// invalid share, or the signer whose share is missing, is always blamed with the signed share as the evidence. The
+// presign rounds are the GG20 rounds of tss-lib with their blame, which may be empty, so this is not CGGMP and the
+// abort is only identifiable once the presignatures are generated.
+type TssKeySignIA struct {
+	*TssKeySign
+}
+
+func NewTssKeySignIA(localP2PID string,
+	conf common.TssConfig,
+	broadcastChan chan *messages.BroadcastMsgChan,
+	stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int,
+) *TssKeySignIA {
+	return &TssKeySignIA{
+		TssKeySign: NewTssKeySign(localP2PID, conf, broadcastChan, stopChan, msgID, privKey, p2pComm, stateManager, msgNum),
+	}
+}
+
+// SignMessage signs with the presignatures the party agreed on in the join party, or runs the presign rounds for
+// the messages first if none is agreed
+func (tKeySign *TssKeySignIA) SignMessage(msgsToSign [][]byte, localStateItem storage.KeygenLocalState, parties []string) ([]*tsslibcommon.ECSignature, error) {
+	if len(tKeySign.presignIDs) != 0 {
+		return tKeySign.TssKeySign.SignMessage(msgsToSign, localStateItem, parties)
+	}
+	partiesID, localPartyID, err := conversion.GetParties(parties, localStateItem.LocalPartyKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to form key sign party: %w", err)
+	}
+	if !common.Contains(partiesID, localPartyID) {
+		tKeySign.logger.Info().Msgf("we are not in this rounds key sign")
+		return nil, nil
+	}
+	states, err := tKeySign.presignData(localStateItem, parties, len(msgsToSign))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(states))
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s-%d", tKeySign.msgID, i)
+	}
+	return tKeySign.finalizeSignatures(msgsToSign, localStateItem, parties, ids, states)
+}
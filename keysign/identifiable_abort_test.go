@@ -0,0 +1,146 @@
+package keysign
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	tsslibcommon "github.com/binance-chain/tss-lib/common"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+)
+
+const iaPoolPubKey = "oppypub1addwnpepqtmru87hylm9q0tcza8p0vze2zvmqk0wr0933qr472hggzw2tp4pvy3756g"
+
+func subscribeKeysign(comm *p2p.Communication, msgID string, channel chan *p2p.Message) func() {
+	topics := []messages.THORChainTSSMessageType{messages.TSSKeySignMsg, messages.TSSKeySignVerMsg, messages.TSSControlMsg, messages.TSSTaskDone, messages.TSSPresignShare}
+	for _, el := range topics {
+		comm.SetSubscribe(el, msgID, channel)
+	}
+	return func() {
+		for _, el := range topics {
+			comm.CancelSubscribe(el, msgID)
+		}
+	}
+}
+
+func (s *TssKeysignTestSuite) newIABackend(c *C, idx int, msgID string) Backend {
+	comm := s.comms[idx]
+	conf := common.TssConfig{
+		KeyGenTimeout:   90 * time.Second,
+		KeySignTimeout:  90 * time.Second,
+		PreParamTimeout: 5 * time.Second,
+	}
+	backend, err := NewBackend(ProtocolGG20IA, comm.GetLocalPeerID(), conf, comm.BroadcastMsgChan, make(chan struct{}), msgID,
+		s.nodePrivKeys[idx], comm, s.stateMgrs[idx], 2)
+	c.Assert(err, IsNil)
+	return backend
+}
+
+func (s *TssKeysignTestSuite) TestIdentifiableAbortSignMessage(c *C) {
+	if testing.Short() {
+		c.Skip("skip the test")
+		return
+	}
+	sort.Strings(testPubKeys)
+	msgsToSign := [][]byte{[]byte("helloworld-gg20-ia"), []byte("t")}
+	wg := sync.WaitGroup{}
+	lock := &sync.Mutex{}
+	keysignResult := make(map[int][]*tsslibcommon.ECSignature)
+	for i := 0; i < s.partyNum; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			backend := s.newIABackend(c, idx, "iaKeysignID")
+			defer subscribeKeysign(s.comms[idx], "iaKeysignID", backend.GetTssKeySignChannels())()
+			localState, err := s.stateMgrs[idx].GetLocalState(iaPoolPubKey)
+			c.Assert(err, IsNil)
+			sig, err := backend.SignMessage(msgsToSign, localState, testPubKeys)
+			c.Assert(err, IsNil)
+			lock.Lock()
+			defer lock.Unlock()
+			keysignResult[idx] = sig
+		}(i)
+	}
+	wg.Wait()
+
+	expected := keysignResult[0]
+	c.Assert(expected, HasLen, 2)
+	for _, item := range keysignResult {
+		c.Assert(item, HasLen, 2)
+		for i, each := range item {
+			c.Assert(each.GetSignature(), DeepEquals, expected[i].GetSignature())
+		}
+	}
+}
+
+func (s *TssKeysignTestSuite) TestIdentifiableAbortBlame(c *C) {
+	if testing.Short() {
+		c.Skip("skip the test")
+		return
+	}
+	sort.Strings(testPubKeys)
+	wg := sync.WaitGroup{}
+	for i := 0; i < s.partyNum; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			comm := s.comms[idx]
+			presignIns := NewTssKeySign(comm.GetLocalPeerID(), common.TssConfig{KeySignTimeout: 90 * time.Second}, comm.BroadcastMsgChan,
+				make(chan struct{}), "iaPresignID", s.nodePrivKeys[idx], comm, s.stateMgrs[idx], 1)
+			defer subscribeKeysign(comm, "iaPresignID", presignIns.GetTssKeySignChannels())()
+			localState, err := s.stateMgrs[idx].GetLocalState(iaPoolPubKey)
+			c.Assert(err, IsNil)
+			presigs, err := presignIns.Presign(localState, testPubKeys, 1)
+			c.Assert(err, IsNil)
+			c.Assert(s.stateMgrs[idx].SavePresignatures(presigs), IsNil)
+		}(i)
+	}
+	wg.Wait()
+
+	// the cheater signs with the presignature it changed, so its signature share does not match its commitments
+	cheater := 3
+	presigs, err := s.stateMgrs[cheater].ConsumePresignatures(iaPoolPubKey, []string{"iaPresignID-0"})
+	c.Assert(err, IsNil)
+	state, err := openPresignature(s.nodePrivKeys[cheater], presigs[0])
+	c.Assert(err, IsNil)
+	kI := new(big.Int).SetBytes(state.GetOneRoundData().GetKI())
+	state.GetOneRoundData().KI = kI.Add(kI, big.NewInt(1)).Bytes()
+	presigs[0].Data, err = sealPresignature(s.nodePrivKeys[cheater], presigs[0].ID, state.GetOneRoundData())
+	c.Assert(err, IsNil)
+	c.Assert(s.stateMgrs[cheater].SavePresignatures(presigs), IsNil)
+	cheaterPubKey, err := conversion.GetPubKeyFromPeerID(s.comms[cheater].GetLocalPeerID())
+	c.Assert(err, IsNil)
+
+	for i := 0; i < s.partyNum; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			backend := s.newIABackend(c, idx, "iaBlameID")
+			defer subscribeKeysign(s.comms[idx], "iaBlameID", backend.GetTssKeySignChannels())()
+			backend.(PresignBackend).SetPresignatures([]string{"iaPresignID-0"})
+			localState, err := s.stateMgrs[idx].GetLocalState(iaPoolPubKey)
+			c.Assert(err, IsNil)
+			_, err = backend.SignMessage([][]byte{[]byte("helloworld-gg20-ia")}, localState, testPubKeys)
+			c.Assert(err, NotNil)
+			if idx == cheater {
+				return
+			}
+			blameMgr := backend.GetTssCommonStruct().GetBlameMgr()
+			blameNodes := blameMgr.GetBlame().BlameNodes
+			c.Assert(blameNodes, HasLen, 1)
+			c.Assert(blameNodes[0].Pubkey, Equals, cheaterPubKey)
+			evidences := blameMgr.GetEvidences()
+			c.Assert(evidences, HasLen, 1)
+			c.Assert(blame.VerifyEvidence(evidences[0]), IsNil)
+		}(i)
+	}
+	wg.Wait()
+}
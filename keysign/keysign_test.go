@@ -67,7 +67,9 @@ func TestPackage(t *testing.T) {
 }
 
 type MockLocalStateManager struct {
-	file string
+	file        string
+	presignLock sync.Mutex
+	presigs     []storage.Presignature
 }
 
 func (m *MockLocalStateManager) SaveLocalState(state storage.KeygenLocalState) error {
@@ -94,6 +96,44 @@ func (s *MockLocalStateManager) RetrieveP2PAddresses() ([]ma.Multiaddr, error) {
 	return nil, os.ErrNotExist
 }
 
+func (s *MockLocalStateManager) SaveCeremonyRecord(record storage.CeremonyRecord) error {
+	return nil
+}
+
+func (s *MockLocalStateManager) GetCeremonyRecords() ([]storage.CeremonyRecord, error) {
+	return nil, nil
+}
+
+func (s *MockLocalStateManager) SavePresignatures(presigs []storage.Presignature) error {
+	s.presignLock.Lock()
+	defer s.presignLock.Unlock()
+	s.presigs = append(s.presigs, presigs...)
+	return nil
+}
+
+func (s *MockLocalStateManager) ConsumePresignatures(poolPubKey string, ids []string) ([]storage.Presignature, error) {
+	s.presignLock.Lock()
+	defer s.presignLock.Unlock()
+	taken, remaining, err := storage.TakePresignatures(s.presigs, poolPubKey, ids)
+	if err != nil {
+		return nil, err
+	}
+	s.presigs = remaining
+	return taken, nil
+}
+
+func (s *MockLocalStateManager) GetPresignatures(poolPubKey string) ([]storage.Presignature, error) {
+	s.presignLock.Lock()
+	defer s.presignLock.Unlock()
+	return append([]storage.Presignature{}, s.presigs...), nil
+}
+
+func (s *MockLocalStateManager) GetPresignatureCount(poolPubKey string) (int, error) {
+	s.presignLock.Lock()
+	defer s.presignLock.Unlock()
+	return len(s.presigs), nil
+}
+
 type TssKeysignTestSuite struct {
 	comms        []*p2p.Communication
 	partyNum     int
@@ -238,6 +278,76 @@ func (s *TssKeysignTestSuite) TestSignMessage(c *C) {
 	}
 }
 
+func (s *TssKeysignTestSuite) TestPresignAndSignMessage(c *C) {
+	if testing.Short() {
+		c.Skip("skip the test")
+		return
+	}
+	sort.Strings(testPubKeys)
+	poolPubKey := "oppypub1addwnpepqtmru87hylm9q0tcza8p0vze2zvmqk0wr0933qr472hggzw2tp4pvy3756g"
+	conf := common.TssConfig{
+		KeyGenTimeout:   90 * time.Second,
+		KeySignTimeout:  90 * time.Second,
+		PreParamTimeout: 5 * time.Second,
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < s.partyNum; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			comm := s.comms[idx]
+			presignIns := NewTssKeySign(comm.GetLocalPeerID(), conf, comm.BroadcastMsgChan, make(chan struct{}), "presignID",
+				s.nodePrivKeys[idx], comm, s.stateMgrs[idx], 2)
+			defer subscribeKeysign(comm, "presignID", presignIns.GetTssKeySignChannels())()
+			localState, err := s.stateMgrs[idx].GetLocalState(poolPubKey)
+			c.Assert(err, IsNil)
+			presigs, err := presignIns.Presign(localState, testPubKeys, 2)
+			c.Assert(err, IsNil)
+			c.Assert(presigs, HasLen, 2)
+			c.Assert(s.stateMgrs[idx].SavePresignatures(presigs), IsNil)
+		}(i)
+	}
+	wg.Wait()
+
+	msgsToSign := [][]byte{[]byte("helloworld-presign"), []byte("t")}
+	lock := &sync.Mutex{}
+	keysignResult := make(map[int][]*tsslibcommon.ECSignature)
+	for i := 0; i < s.partyNum; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			comm := s.comms[idx]
+			keysignIns := NewTssKeySign(comm.GetLocalPeerID(), conf, comm.BroadcastMsgChan, make(chan struct{}), "keysignID",
+				s.nodePrivKeys[idx], comm, s.stateMgrs[idx], 2)
+			defer subscribeKeysign(comm, "keysignID", keysignIns.GetTssKeySignChannels())()
+			localState, err := s.stateMgrs[idx].GetLocalState(poolPubKey)
+			c.Assert(err, IsNil)
+			// the party agrees on the presignatures in the join party
+			keysignIns.SetPresignatures([]string{"presignID-0", "presignID-1"})
+			sig, err := keysignIns.SignMessage(msgsToSign, localState, testPubKeys)
+			c.Assert(err, IsNil)
+			// the presignatures are used only once
+			count, err := s.stateMgrs[idx].GetPresignatureCount(poolPubKey)
+			c.Assert(err, IsNil)
+			c.Assert(count, Equals, 0)
+			lock.Lock()
+			defer lock.Unlock()
+			keysignResult[idx] = sig
+		}(i)
+	}
+	wg.Wait()
+
+	expected := keysignResult[0]
+	c.Assert(expected, HasLen, 2)
+	for _, item := range keysignResult {
+		c.Assert(item, HasLen, 2)
+		for i, each := range item {
+			c.Assert(each.GetSignature(), DeepEquals, expected[i].GetSignature())
+		}
+	}
+}
+
 func observeAndStop(c *C, tssKeySign *TssKeySign, stopChan chan struct{}) {
 	for {
 		select {
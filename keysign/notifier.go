@@ -7,8 +7,9 @@ import (
 	"math/big"
 
 	"github.com/binance-chain/tss-lib/common"
-	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
 	"github.com/tendermint/btcd/btcec"
+
+	"github.com/joltify-finance/tss/conversion"
 )
 
 // Notifier is design to receive keysign signature, success or failure
@@ -44,7 +45,7 @@ func NewNotifier(messageID string, messages [][]byte, poolPubKey string) (*Notif
 // go-tss respect the payload it receives , assume the payload had been hashed already by whoever send it in.
 func (n *Notifier) verifySignature(data *common.ECSignature, msg []byte) (bool, error) {
 	// we should be able to use any of the pubkeys to verify the signature
-	pubKey,err:=legacybech32.UnmarshalPubKey(legacybech32.AccPK,n.poolPubKey)
+	pubKey,err:=conversion.ParsePubKey(n.poolPubKey)
 	if err != nil {
 		return false, fmt.Errorf("fail to get pubkey from bech32 pubkey string(%s):%w", n.poolPubKey, err)
 	}
@@ -63,7 +64,9 @@ func (n *Notifier) ProcessSignature(data []*common.ECSignature) (bool, error) {
 	// when data is nil , which means keysign  failed, there is no signature to be verified in that case
 	// for gg20, it wrap the signature R,S into ECSignature structure
 	if len(data) != 0 {
-
+		if len(data) != len(n.messages) {
+			return false, fmt.Errorf("expect %d signatures, got %d", len(n.messages), len(data))
+		}
 		for i := 0; i < len(data); i++ {
 			eachSig := data[i]
 			msg := n.messages[i]
@@ -0,0 +1,417 @@
+package keysign
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	tsslibcommon "github.com/binance-chain/tss-lib/common"
+	"github.com/binance-chain/tss-lib/ecdsa/signing"
+	btss "github.com/binance-chain/tss-lib/tss"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
+)
+
+const presignMonikerPrefix = "presign:"
+
+// presignShareRound is the round of the signature shares, it is given to the evidence of the signers we blame
+var presignShareRound = messages.TSSPresignShare.String()
+
+// Presign runs the message independent rounds of the keysign num times with the parties. The presignatures are
+// encrypted with the key of this node, and each of them can finish the keysign of one message with these parties.
+func (tKeySign *TssKeySign) Presign(localStateItem storage.KeygenLocalState, parties []string, num int) ([]storage.Presignature, error) {
+	if num <= 0 {
+		return nil, errors.New("invalid number of presignatures")
+	}
+	partiesID, localPartyID, err := conversion.GetParties(parties, localStateItem.LocalPartyKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to form presign party: %w", err)
+	}
+	if !common.Contains(partiesID, localPartyID) {
+		return nil, errors.New("we are not in this rounds presign")
+	}
+	results, err := tKeySign.presignData(localStateItem, parties, num)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := append([]string{}, parties...)
+	sort.Strings(signers)
+	createdAt := time.Now().UTC()
+	presigs := make([]storage.Presignature, len(results))
+	for i, el := range results {
+		id := fmt.Sprintf("%s-%d", tKeySign.msgID, i)
+		data, err := sealPresignature(tKeySign.privKey, id, el.GetOneRoundData())
+		if err != nil {
+			return nil, err
+		}
+		presigs[i] = storage.Presignature{
+			ID:         id,
+			PoolPubKey: localStateItem.PubKey,
+			Signers:    signers,
+			Data:       data,
+			CreatedAt:  createdAt,
+		}
+	}
+	tKeySign.logger.Info().Msgf("%s successfully generate %d presignatures", tKeySign.p2pComm.GetHost().ID().String(), len(presigs))
+	return presigs, nil
+}
+
+// presignData runs the message independent rounds of the keysign num times with the parties
+func (tKeySign *TssKeySign) presignData(localStateItem storage.KeygenLocalState, parties []string, num int) ([]*signing.SignatureData, error) {
+	monikers := make([]string, num)
+	for i := range monikers {
+		monikers[i] = presignMonikerPrefix + strconv.Itoa(i)
+	}
+	results, err := tKeySign.runSigning(monikers, localStateItem, parties, func(_ int, params *btss.Parameters, outCh chan<- btss.Message, endCh chan<- *signing.SignatureData) btss.Party {
+		return signing.NewLocalPartyWithOneRoundSign(params, localStateItem.LocalData, outCh, endCh)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, el := range results {
+		if el.GetOneRoundData().GetBigR() == nil {
+			return nil, errors.New("presign finished without the presignature")
+		}
+	}
+	// R is shared by the signers of the same presignature, we sort by it so that all the signers give the
+	// same ID to the same presignature
+	sort.Slice(results, func(i, j int) bool {
+		a := new(big.Int).SetBytes(results[i].GetOneRoundData().GetBigR().GetX())
+		b := new(big.Int).SetBytes(results[j].GetOneRoundData().GetBigR().GetX())
+		return a.Cmp(b) < 0
+	})
+	return results, nil
+}
+
+// signWithPresignatures finishes the keysign in one round, each signer sends the signature shares it computes
+// with the presignatures, and combines the shares of the others into the signatures
+func (tKeySign *TssKeySign) signWithPresignatures(msgsToSign [][]byte, localStateItem storage.KeygenLocalState, parties []string, presigs []storage.Presignature) ([]*tsslibcommon.ECSignature, error) {
+	if len(presigs) != len(msgsToSign) {
+		return nil, fmt.Errorf("%d presignatures for %d messages", len(presigs), len(msgsToSign))
+	}
+	signers := append([]string{}, parties...)
+	sort.Strings(signers)
+	for _, el := range presigs {
+		if !equalPresignIDs(el.Signers, signers) {
+			return nil, fmt.Errorf("presignature(%s) is not generated by the signers", el.ID)
+		}
+	}
+	ids := make([]string, len(presigs))
+	states := make([]*signing.SignatureData, len(presigs))
+	for i, el := range presigs {
+		ids[i] = el.ID
+		var err error
+		states[i], err = openPresignature(tKeySign.privKey, el)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tKeySign.finalizeSignatures(msgsToSign, localStateItem, parties, ids, states)
+}
+
+// finalizeSignatures runs the online round with the presignature states, the share of each signer is checked
+// against the commitments of the signer in the presignature, so the signer of the invalid share is blamed with
+// the signed share as the evidence
+func (tKeySign *TssKeySign) finalizeSignatures(msgsToSign [][]byte, localStateItem storage.KeygenLocalState, parties []string, ids []string, states []*signing.SignatureData) ([]*tsslibcommon.ECSignature, error) {
+	partiesID, localPartyID, err := conversion.GetParties(parties, localStateItem.LocalPartyKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to form key sign party: %w", err)
+	}
+	blameMgr := tKeySign.tssCommonStruct.GetBlameMgr()
+	partyIDMap := conversion.SetupPartyIDMap(partiesID)
+	err1 := conversion.SetupIDMaps(partyIDMap, tKeySign.tssCommonStruct.PartyIDtoP2PID)
+	err2 := conversion.SetupIDMaps(partyIDMap, blameMgr.PartyIDtoP2PID)
+	if err1 != nil || err2 != nil {
+		return nil, errors.New("error in creating mapping between partyID and P2P ID")
+	}
+	// there is no local party in the online round, we collect the shares without the party info
+	peers := conversion.GetPeersID(tKeySign.tssCommonStruct.PartyIDtoP2PID, tKeySign.tssCommonStruct.GetLocalPeerID())
+	tKeySign.tssCommonStruct.P2PPeersLock.Lock()
+	tKeySign.tssCommonStruct.P2PPeers = peers
+	tKeySign.tssCommonStruct.P2PPeersLock.Unlock()
+
+	ms := make([]*big.Int, len(states))
+	ourSIs := make([]*big.Int, len(states))
+	var share messages.PresignShare
+	for i, el := range states {
+		ms[i], err = common.MsgToHashInt(msgsToSign[i])
+		if err != nil {
+			return nil, fmt.Errorf("fail to convert msg to hash int: %w", err)
+		}
+		ourSIs[i] = signing.FinalizeGetOurSigShare(el, ms[i])
+		share.PresignIDs = append(share.PresignIDs, ids[i])
+		share.Shares = append(share.Shares, ourSIs[i].Bytes())
+	}
+	shareBytes, err := json.Marshal(share)
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the presign share: %w", err)
+	}
+	// the share is signed like the wire message, so that the signer can be blamed with it as the evidence
+	sig, err := signPresignShare(tKeySign.privKey, shareBytes, tKeySign.msgID)
+	if err != nil {
+		return nil, fmt.Errorf("fail to sign the presign share: %w", err)
+	}
+	payload, err := json.Marshal(messages.WireMessage{
+		RoundInfo: presignShareRound,
+		Message:   shareBytes,
+		Sig:       sig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the presign share message: %w", err)
+	}
+	buf, err := json.Marshal(messages.WrappedMessage{
+		MessageType:  messages.TSSPresignShare,
+		MsgID:        tKeySign.msgID,
+		Payload:      payload,
+		TraceContext: tracing.Inject(tKeySign.tssCommonStruct.TraceContext()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the wrapped presign share: %w", err)
+	}
+	tKeySign.p2pComm.Broadcast(peers, buf, tKeySign.msgID)
+
+	otherShares, senders, err := tKeySign.collectPresignShares(share.PresignIDs, partyIDMap)
+	if err != nil {
+		return nil, err
+	}
+
+	pkX, pkY := new(btcec.FieldVal), new(btcec.FieldVal)
+	pkX.SetByteSlice(localStateItem.LocalData.ECDSAPub.X().Bytes())
+	pkY.SetByteSlice(localStateItem.LocalData.ECDSAPub.Y().Bytes())
+	pk := btcec.NewPublicKey(pkX, pkY)
+	signatures := make([]*tsslibcommon.ECSignature, len(states))
+	for i, state := range states {
+		otherSIs := make(map[*btss.PartyID]*big.Int, len(otherShares))
+		for party, shares := range otherShares {
+			otherSIs[party] = new(big.Int).SetBytes(shares[i])
+		}
+		data, _, tssErr := signing.FinalizeGetAndVerifyFinalSig(state, pk, ms[i], localPartyID, ourSIs[i], otherSIs)
+		if tssErr != nil {
+			var nodes []blame.Node
+			for _, el := range tssErr.Culprits() {
+				pubKey, err := conversion.PartyIDtoPubKey(el)
+				if err != nil {
+					tKeySign.logger.Error().Err(err).Msg("fail to get the public key of the culprit")
+					continue
+				}
+				node, ok := senders[pubKey]
+				if !ok {
+					nodes = append(nodes, blame.Node{Pubkey: pubKey})
+					continue
+				}
+				nodes = append(nodes, node)
+				blameMgr.AddEvidence(blame.NewEvidence(tKeySign.msgID, presignShareRound, blame.TssBrokenMsg, node))
+			}
+			blameMgr.GetBlame().SetBlame(blame.TssBrokenMsg, nodes, false)
+			return nil, fmt.Errorf("fail to finalize the signature with the presignature: %w", tssErr.Cause())
+		}
+		signatures[i] = data.GetSignature()
+	}
+
+	tKeySign.logger.Info().Msgf("%s successfully sign the message with the presignatures", tKeySign.p2pComm.GetHost().ID().String())
+	sortSignatures(signatures)
+	return signatures, nil
+}
+
+// collectPresignShares waits for the signature shares of all the other signers, it returns the shares together with
+// the blame node of each signer that carries the signed share, keyed by the public key of the signer
+func (tKeySign *TssKeySign) collectPresignShares(presignIDs []string, partyIDMap map[string]*btss.PartyID) (map[*btss.PartyID][][]byte, map[string]blame.Node, error) {
+	signers := make(map[peer.ID]*btss.PartyID)
+	for id, pID := range tKeySign.tssCommonStruct.PartyIDtoP2PID {
+		party, ok := partyIDMap[id]
+		if !ok || pID.String() == tKeySign.tssCommonStruct.GetLocalPeerID() {
+			continue
+		}
+		signers[pID] = party
+	}
+
+	blameMgr := tKeySign.tssCommonStruct.GetBlameMgr()
+	tssConf := tKeySign.tssCommonStruct.GetConf()
+	// the shares are sent in one round, so we wait for them as long as for any other round
+	timeout := tssConf.KeySignRoundTimeout
+	if timeout == 0 {
+		timeout = tssConf.KeySignTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	shares := make(map[*btss.PartyID][][]byte, len(signers))
+	senders := make(map[string]blame.Node, len(signers))
+	// the shares that arrive before the tss-lib rounds finish are kept by the inbound processing
+	pending := tKeySign.tssCommonStruct.TakeDeferredMsgs()
+	for len(shares) < len(signers) {
+		var msg *p2p.Message
+		if len(pending) != 0 {
+			msg, pending = pending[0], pending[1:]
+		} else {
+			select {
+			case <-tKeySign.stopChan:
+				return nil, nil, errors.New("received exit signal")
+			case <-timer.C:
+				var nodes []blame.Node
+				for _, party := range signers {
+					if _, ok := shares[party]; ok {
+						continue
+					}
+					pubKey, err := conversion.PartyIDtoPubKey(party)
+					if err != nil {
+						tKeySign.logger.Error().Err(err).Msg("fail to get the public key of the signer")
+						continue
+					}
+					nodes = append(nodes, blame.Node{Pubkey: pubKey})
+				}
+				blameMgr.SetFailedRound(presignShareRound)
+				blameMgr.GetBlame().SetBlame(blame.TssTimeout, nodes, false)
+				return nil, nil, blame.ErrTssTimeOut
+			case msg = <-tKeySign.tssCommonStruct.TssMsg:
+			}
+		}
+		party, ok := signers[msg.PeerID]
+		if !ok {
+			tKeySign.logger.Error().Msgf("presign share from %s who is not the signer", msg.PeerID)
+			continue
+		}
+		var wrappedMsg messages.WrappedMessage
+		if err := json.Unmarshal(msg.Payload, &wrappedMsg); err != nil {
+			tKeySign.logger.Error().Err(err).Msg("fail to unmarshal wrapped message bytes")
+			continue
+		}
+		if wrappedMsg.MessageType != messages.TSSPresignShare {
+			continue
+		}
+		_, span := tracing.StartRemote(tKeySign.tssCommonStruct.TraceContext(), wrappedMsg.TraceContext, "tss.process_presign_share",
+			tracing.MsgIDKey.String(wrappedMsg.MsgID), tracing.PeerKey.String(msg.PeerID.String()))
+		node, share, err := tKeySign.openPresignShare(party, wrappedMsg.Payload)
+		tracing.End(span, err)
+		if err != nil {
+			// the share that is not signed by the signer is dropped as if we never received it
+			tKeySign.logger.Error().Err(err).Msgf("fail to verify the presign share from %s", msg.PeerID)
+			continue
+		}
+		if share == nil || !equalPresignIDs(share.PresignIDs, presignIDs) || len(share.Shares) != len(presignIDs) {
+			blameMgr.SetFailedRound(presignShareRound)
+			blameMgr.AddEvidence(blame.NewEvidence(tKeySign.msgID, presignShareRound, blame.TssBrokenMsg, node))
+			blameMgr.GetBlame().SetBlame(blame.TssBrokenMsg, []blame.Node{node}, false)
+			return nil, nil, fmt.Errorf("signer %s signs with the different presignatures", msg.PeerID)
+		}
+		shares[party] = share.Shares
+		senders[node.Pubkey] = node
+	}
+	return shares, senders, nil
+}
+
+// openPresignShare verifies the share is signed by the signer, it returns the blame node of the signer with the
+// signed share, and the share which is nil if the signed payload is not a share
+func (tKeySign *TssKeySign) openPresignShare(party *btss.PartyID, payload []byte) (blame.Node, *messages.PresignShare, error) {
+	var wireMsg messages.WireMessage
+	if err := json.Unmarshal(payload, &wireMsg); err != nil {
+		return blame.Node{}, nil, fmt.Errorf("fail to unmarshal the presign share message: %w", err)
+	}
+	pubKey, err := conversion.PartyIDtoPubKey(party)
+	if err != nil {
+		return blame.Node{}, nil, fmt.Errorf("fail to get the public key of the signer: %w", err)
+	}
+	node := blame.NewNode(pubKey, wireMsg.Message, wireMsg.Sig)
+	if err := blame.VerifyEvidence(blame.NewEvidence(tKeySign.msgID, presignShareRound, blame.TssBrokenMsg, node)); err != nil {
+		return blame.Node{}, nil, err
+	}
+	var share messages.PresignShare
+	if err := json.Unmarshal(wireMsg.Message, &share); err != nil {
+		return node, nil, nil
+	}
+	return node, &share, nil
+}
+
+// signPresignShare signs the share with the msgID the same way as the wire message
+func signPresignShare(privKey tcrypto.PrivKey, share []byte, msgID string) ([]byte, error) {
+	if privKey == nil {
+		return nil, errors.New("no key to sign the presign share")
+	}
+	var dataForSigning bytes.Buffer
+	dataForSigning.Write(share)
+	dataForSigning.WriteString(msgID)
+	return privKey.Sign(dataForSigning.Bytes())
+}
+
+func equalPresignIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newPresignatureCipher creates the cipher of the presignatures with the key derived from the node key
+func newPresignatureCipher(privKey tcrypto.PrivKey) (cipher.AEAD, error) {
+	if privKey == nil {
+		return nil, errors.New("no key to encrypt the presignature")
+	}
+	key := sha256.Sum256(append([]byte("tss-presignature"), privKey.Bytes()...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealPresignature encrypts the presignature, the ID is authenticated so that the data of one presignature
+// cannot be stored as another one
+func sealPresignature(privKey tcrypto.PrivKey, id string, data *signing.SignatureData_OneRoundData) ([]byte, error) {
+	plainText, err := proto.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the presignature: %w", err)
+	}
+	aead, err := newPresignatureCipher(privKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("fail to generate the nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plainText, []byte(id)), nil
+}
+
+// openPresignature decrypts the presignature into the signing data FinalizeGetOurSigShare takes
+func openPresignature(privKey tcrypto.PrivKey, presig storage.Presignature) (*signing.SignatureData, error) {
+	aead, err := newPresignatureCipher(privKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(presig.Data) < aead.NonceSize() {
+		return nil, fmt.Errorf("presignature(%s) is too short", presig.ID)
+	}
+	nonceSize := aead.NonceSize()
+	plainText, err := aead.Open(nil, presig.Data[:nonceSize], presig.Data[nonceSize:], []byte(presig.ID))
+	if err != nil {
+		return nil, fmt.Errorf("fail to decrypt the presignature(%s): %w", presig.ID, err)
+	}
+	var oneRoundData signing.SignatureData_OneRoundData
+	if err := proto.Unmarshal(plainText, &oneRoundData); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the presignature(%s): %w", presig.ID, err)
+	}
+	return &signing.SignatureData{OneRoundData: &oneRoundData}, nil
+}
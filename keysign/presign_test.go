@@ -0,0 +1,75 @@
+package keysign
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/binance-chain/tss-lib/ecdsa/signing"
+	btss "github.com/binance-chain/tss-lib/tss"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/storage"
+)
+
+type PresignTestSuite struct{}
+
+var _ = Suite(&PresignTestSuite{})
+
+func (PresignTestSuite) TestSealPresignature(c *C) {
+	privKey := ed25519.GenPrivKey()
+	data := &signing.SignatureData_OneRoundData{
+		T:       2,
+		KI:      big.NewInt(10).Bytes(),
+		RSigmaI: big.NewInt(20).Bytes(),
+	}
+	sealed, err := sealPresignature(privKey, "presign-0", data)
+	c.Assert(err, IsNil)
+
+	state, err := openPresignature(privKey, storage.Presignature{ID: "presign-0", Data: sealed})
+	c.Assert(err, IsNil)
+	c.Assert(state.GetOneRoundData().GetT(), Equals, int32(2))
+	c.Assert(signing.FinalizeGetOurSigShare(state, big.NewInt(3)).Int64(), Equals, int64(50))
+
+	// the presignature is bound to its ID and to the key of this node
+	_, err = openPresignature(privKey, storage.Presignature{ID: "presign-1", Data: sealed})
+	c.Assert(err, NotNil)
+	_, err = openPresignature(ed25519.GenPrivKey(), storage.Presignature{ID: "presign-0", Data: sealed})
+	c.Assert(err, NotNil)
+	_, err = openPresignature(privKey, storage.Presignature{ID: "presign-0", Data: sealed[:4]})
+	c.Assert(err, NotNil)
+	_, err = sealPresignature(nil, "presign-0", data)
+	c.Assert(err, NotNil)
+}
+
+func (PresignTestSuite) TestOpenPresignShare(c *C) {
+	// the keys are fixed, so that the key of the party never starts with a zero byte
+	privKey := ed25519.GenPrivKeyFromSecret([]byte("signer"))
+	party := btss.NewPartyID("1", "1", new(big.Int).SetBytes(privKey.PubKey().Bytes()))
+	party.Index = 0
+	tKeySign := &TssKeySign{msgID: "keysignID"}
+	shareBytes, err := json.Marshal(messages.PresignShare{PresignIDs: []string{"presign-0"}, Shares: [][]byte{{1}}})
+	c.Assert(err, IsNil)
+	sig, err := signPresignShare(privKey, shareBytes, tKeySign.msgID)
+	c.Assert(err, IsNil)
+	payload, err := json.Marshal(messages.WireMessage{RoundInfo: presignShareRound, Message: shareBytes, Sig: sig})
+	c.Assert(err, IsNil)
+
+	node, share, err := tKeySign.openPresignShare(party, payload)
+	c.Assert(err, IsNil)
+	c.Assert(share.PresignIDs, DeepEquals, []string{"presign-0"})
+	// the blame node carries the evidence of the share the signer sent
+	c.Assert(blame.VerifyEvidence(blame.NewEvidence(tKeySign.msgID, presignShareRound, blame.TssBrokenMsg, node)), IsNil)
+
+	// the share of the other session or the other signer is rejected
+	_, _, err = (&TssKeySign{msgID: "otherID"}).openPresignShare(party, payload)
+	c.Assert(err, NotNil)
+	otherParty := btss.NewPartyID("2", "2", new(big.Int).SetBytes(ed25519.GenPrivKeyFromSecret([]byte("other signer")).PubKey().Bytes()))
+	otherParty.Index = 1
+	_, _, err = tKeySign.openPresignShare(otherParty, payload)
+	c.Assert(err, NotNil)
+	_, err = signPresignShare(nil, shareBytes, tKeySign.msgID)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,108 @@
+package keysign
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	tsslibcommon "github.com/binance-chain/tss-lib/common"
+	"github.com/blang/semver"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+)
+
+const (
+	// ProtocolGG20 is the GG20 protocol provided by tss-lib
+	ProtocolGG20 = "gg20"
+	// ProtocolGG20IA is the GG20 keysign with identifiable abort in the online round only, see TssKeySignIA. It is
+	// not CGGMP, the failures in the presign rounds get the GG20 blame
+	ProtocolGG20IA = "gg20-ia"
+)
+
+// ErrProtocolNotSupported indicates no backend of the requested protocol is registered
+var ErrProtocolNotSupported = errors.New("keysign protocol is not supported")
+
+// Backend is the threshold ECDSA protocol that runs the keysign ceremony, the backends share the p2p,
+// hash check and blame plumbing provided by TssCommon
+type Backend interface {
+	GetTssKeySignChannels() chan *p2p.Message
+	GetTssCommonStruct() *common.TssCommon
+	SignMessage(msgsToSign [][]byte, localStateItem storage.KeygenLocalState, parties []string) ([]*tsslibcommon.ECSignature, error)
+}
+
+// PresignBackend is the backend that finishes the keysign with the presignatures the party agreed on in the
+// join party, it runs the full keysign if none is agreed
+type PresignBackend interface {
+	SetPresignatures(ids []string)
+}
+
+// BackendConstructor creates the backend of a keysign ceremony
+type BackendConstructor func(localP2PID string,
+	conf common.TssConfig,
+	broadcastChan chan *messages.BroadcastMsgChan,
+	stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int) Backend
+
+var (
+	backendsLock = &sync.RWMutex{}
+	backends     = map[string]BackendConstructor{
+		ProtocolGG20: func(localP2PID string,
+			conf common.TssConfig,
+			broadcastChan chan *messages.BroadcastMsgChan,
+			stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int,
+		) Backend {
+			return NewTssKeySign(localP2PID, conf, broadcastChan, stopChan, msgID, privKey, p2pComm, stateManager, msgNum)
+		},
+		ProtocolGG20IA: func(localP2PID string,
+			conf common.TssConfig,
+			broadcastChan chan *messages.BroadcastMsgChan,
+			stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int,
+		) Backend {
+			return NewTssKeySignIA(localP2PID, conf, broadcastChan, stopChan, msgID, privKey, p2pComm, stateManager, msgNum)
+		},
+	}
+)
+
+// RegisterBackend makes the backend of the protocol available to the keysign requests
+func RegisterBackend(protocol string, constructor BackendConstructor) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+	backends[strings.ToLower(protocol)] = constructor
+}
+
+// ProtocolFromVersion returns the protocol selected by the build metadata of the request version, for
+// example 0.14.0+gg20-ia, the version without the build metadata selects gg20
+func ProtocolFromVersion(version string) (string, error) {
+	v, err := semver.Make(version)
+	if err != nil {
+		return "", fmt.Errorf("fail to parse the version(%s): %w", version, err)
+	}
+	switch len(v.Build) {
+	case 0:
+		return ProtocolGG20, nil
+	case 1:
+		return strings.ToLower(v.Build[0]), nil
+	default:
+		return "", fmt.Errorf("version(%s) selects more than one protocol", version)
+	}
+}
+
+// NewBackend creates the backend of the protocol for a keysign ceremony
+func NewBackend(protocol string,
+	localP2PID string,
+	conf common.TssConfig,
+	broadcastChan chan *messages.BroadcastMsgChan,
+	stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int,
+) (Backend, error) {
+	backendsLock.RLock()
+	constructor, ok := backends[protocol]
+	backendsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProtocolNotSupported, protocol)
+	}
+	return constructor(localP2PID, conf, broadcastChan, stopChan, msgID, privKey, p2pComm, stateManager, msgNum), nil
+}
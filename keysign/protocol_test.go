@@ -0,0 +1,62 @@
+package keysign
+
+import (
+	"errors"
+
+	tcrypto "github.com/tendermint/tendermint/crypto"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+)
+
+type ProtocolTestSuite struct{}
+
+var _ = Suite(&ProtocolTestSuite{})
+
+func (ProtocolTestSuite) TestProtocolFromVersion(c *C) {
+	protocol, err := ProtocolFromVersion("0.14.0")
+	c.Assert(err, IsNil)
+	c.Assert(protocol, Equals, ProtocolGG20)
+	protocol, err = ProtocolFromVersion("0.14.0+GG20-IA")
+	c.Assert(err, IsNil)
+	c.Assert(protocol, Equals, ProtocolGG20IA)
+	protocol, err = ProtocolFromVersion("0.14.0+test")
+	c.Assert(err, IsNil)
+	c.Assert(protocol, Equals, "test")
+	_, err = ProtocolFromVersion("0.14.0+gg20.test")
+	c.Assert(err, NotNil)
+	_, err = ProtocolFromVersion("invalid")
+	c.Assert(err, NotNil)
+}
+
+func (ProtocolTestSuite) TestNewBackend(c *C) {
+	backend, err := NewBackend(ProtocolGG20, "", common.TssConfig{}, nil, nil, "msgID", nil, nil, nil, 1)
+	c.Assert(err, IsNil)
+	_, ok := backend.(*TssKeySign)
+	c.Assert(ok, Equals, true)
+	backend, err = NewBackend(ProtocolGG20IA, "", common.TssConfig{}, nil, nil, "msgID", nil, nil, nil, 1)
+	c.Assert(err, IsNil)
+	_, ok = backend.(*TssKeySignIA)
+	c.Assert(ok, Equals, true)
+	_, ok = backend.(PresignBackend)
+	c.Assert(ok, Equals, true)
+
+	_, err = NewBackend("unknown", "", common.TssConfig{}, nil, nil, "msgID", nil, nil, nil, 1)
+	c.Assert(errors.Is(err, ErrProtocolNotSupported), Equals, true)
+
+	RegisterBackend("Test", func(localP2PID string, conf common.TssConfig, broadcastChan chan *messages.BroadcastMsgChan,
+		stopChan chan struct{}, msgID string, privKey tcrypto.PrivKey, p2pComm *p2p.Communication, stateManager storage.LocalStateManager, msgNum int,
+	) Backend {
+		return NewTssKeySign(localP2PID, conf, broadcastChan, stopChan, msgID, privKey, p2pComm, stateManager, msgNum)
+	})
+	defer func() {
+		backendsLock.Lock()
+		delete(backends, "test")
+		backendsLock.Unlock()
+	}()
+	_, err = NewBackend("test", "", common.TssConfig{}, nil, nil, "msgID", nil, nil, nil, 1)
+	c.Assert(err, IsNil)
+}
@@ -7,6 +7,10 @@ type Request struct {
 	SignerPubKeys []string `json:"signer_pub_keys"`
 	BlockHeight   int64    `json:"block_height"`
 	Version       string   `json:"tss_version"`
+	OutputFormats []string `json:"output_formats,omitempty"` // the chain specific formats the signatures are returned in
+	ChainID       int64    `json:"chain_id,omitempty"`       // the EIP-155 chain id of the ethereum format
+	SigHashType   byte     `json:"sighash_type,omitempty"`   // the sighash type of the bitcoin format, SIGHASH_ALL if not set
+	HashModes     []string `json:"hash_modes,omitempty"`     // how each message is hashed before signing, one mode applies to all the messages
 }
 
 func NewRequest(pk string, msgs []string, blockHeight int64, signers []string, version string) Request {
@@ -18,3 +22,22 @@ func NewRequest(pk string, msgs []string, blockHeight int64, signers []string, v
 		Version:       version,
 	}
 }
+
+// PresignRequest request to generate the presignatures of the pool with the signers
+type PresignRequest struct {
+	PoolPubKey    string   `json:"pool_pub_key"`
+	SignerPubKeys []string `json:"signer_pub_keys"` // the presignatures can only be used by exactly these signers
+	Count         int      `json:"count"`
+	BlockHeight   int64    `json:"block_height"`
+	Version       string   `json:"tss_version"`
+}
+
+func NewPresignRequest(pk string, signers []string, count int, blockHeight int64, version string) PresignRequest {
+	return PresignRequest{
+		PoolPubKey:    pk,
+		SignerPubKeys: signers,
+		Count:         count,
+		BlockHeight:   blockHeight,
+		Version:       version,
+	}
+}
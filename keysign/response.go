@@ -11,13 +11,35 @@ type Signature struct {
 	R          string `json:"r"`
 	S          string `json:"s"`
 	RecoveryID string `json:"recovery_id"`
+	DER        string `json:"der,omitempty"`
+	Ethereum   string `json:"ethereum,omitempty"`
+	EthereumV  string `json:"ethereum_v,omitempty"` // v of the ethereum format in decimal
+	Bitcoin    string `json:"bitcoin,omitempty"`
+	Cosmos     string `json:"cosmos,omitempty"`
 }
 
 // Response key sign response
 type Response struct {
 	Signatures []Signature   `json:"signatures"`
 	Status     common.Status `json:"status"`
-	Blame      blame.Blame   `json:"blame"`
+	// Blame can be non-empty even if the keysign succeeds, it then names the node that sent the different broadcast
+	// messages to the peers, and Evidence has both of them
+	Blame      blame.Blame      `json:"blame"`
+	Evidence   []blame.Evidence `json:"evidence,omitempty"`
+	BlameVotes map[string]int   `json:"blame_votes,omitempty"`
+	// Round is the round the keysign fails in
+	Round string `json:"round,omitempty"`
+	// FailedLeaders are the leaders we failed over from in the join party, reported even if the keysign succeeds
+	FailedLeaders []string `json:"failed_leaders,omitempty"`
+}
+
+// PresignResponse presign response
+type PresignResponse struct {
+	Count     int           `json:"count"`      // the presignatures generated by this request
+	PoolDepth int           `json:"pool_depth"` // the presignatures of the pool we have
+	Status    common.Status `json:"status"`
+	Blame     blame.Blame   `json:"blame"`
+	Round     string        `json:"round,omitempty"` // the round the presign fails in
 }
 
 func NewSignature(msg, r, s, recoveryID string) Signature {
@@ -0,0 +1,144 @@
+package keysign
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	becdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+const (
+	// FormatDER is the ASN.1 DER encoded signature
+	FormatDER = "der"
+	// FormatEthereum is the r||s||v signature, v follows EIP-155 if the chain id is set and is big-endian, so the
+	// signature is 65 bytes unless v of the chain id does not fit in one byte
+	FormatEthereum = "ethereum"
+	// FormatBitcoin is the DER encoded signature followed by the sighash type
+	FormatBitcoin = "bitcoin"
+	// FormatCosmos is the 64 bytes r||s compact signature
+	FormatCosmos = "cosmos"
+
+	// SigHashAll is the bitcoin sighash type we use if the request does not set one
+	SigHashAll = 0x01
+)
+
+var (
+	secp256k1N     = btcec.S256().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// ValidateOutputFormats checks all the signature output formats are supported
+func ValidateOutputFormats(formats []string) error {
+	for _, el := range formats {
+		switch el {
+		case FormatDER, FormatEthereum, FormatBitcoin, FormatCosmos:
+		default:
+			return fmt.Errorf("signature output format(%s) is not supported", el)
+		}
+	}
+	return nil
+}
+
+// ValidateEthereumChainID checks the chain id of the ethereum format is not negative
+func ValidateEthereumChainID(chainID int64) error {
+	if chainID < 0 {
+		return fmt.Errorf("chain id(%d) of the ethereum format is negative", chainID)
+	}
+	return nil
+}
+
+// NormalizeLowS returns the signature with s in the lower half of the curve order, flipping the recovery id
+// if we negate s, as the chains reject or malleate the high-S signatures
+func NormalizeLowS(s *big.Int, recoveryID byte) (*big.Int, byte) {
+	if s.Cmp(secp256k1HalfN) <= 0 {
+		return new(big.Int).Set(s), recoveryID
+	}
+	return new(big.Int).Sub(secp256k1N, s), recoveryID ^ 1
+}
+
+// padTo32 returns the big-endian bytes of the value left padded to 32 bytes
+func padTo32(value *big.Int) []byte {
+	buf := make([]byte, 32)
+	return value.FillBytes(buf)
+}
+
+// encodeDER returns the DER encoding of the low-S signature
+func encodeDER(r, s *big.Int) []byte {
+	var rScalar, sScalar btcec.ModNScalar
+	rScalar.SetByteSlice(padTo32(r))
+	sScalar.SetByteSlice(padTo32(s))
+	return becdsa.NewSignature(&rScalar, &sScalar).Serialize()
+}
+
+// ethereumV returns v of the ethereum signature, it is 27+recovery id, or chainID*2+35+recovery id with EIP-155
+func ethereumV(recoveryID byte, chainID int64) (*big.Int, error) {
+	if err := ValidateEthereumChainID(chainID); err != nil {
+		return nil, err
+	}
+	if chainID == 0 {
+		return big.NewInt(int64(recoveryID) + 27), nil
+	}
+	v := new(big.Int).Lsh(big.NewInt(chainID), 1)
+	return v.Add(v, big.NewInt(35+int64(recoveryID))), nil
+}
+
+// encodeEthereum returns r||s||v, where v is big-endian in as many bytes as it needs
+func encodeEthereum(r, s, v *big.Int) []byte {
+	ret := append(padTo32(r), padTo32(s)...)
+	return append(ret, v.Bytes()...)
+}
+
+// ApplyOutputFormats fills the signatures in the output formats of the request, all the formats carry the
+// low-S normalized signature
+func ApplyOutputFormats(signatures []Signature, req Request) error {
+	if len(req.OutputFormats) == 0 {
+		return nil
+	}
+	if err := ValidateOutputFormats(req.OutputFormats); err != nil {
+		return err
+	}
+	sigHashType := req.SigHashType
+	if sigHashType == 0 {
+		sigHashType = SigHashAll
+	}
+	for i := range signatures {
+		rBytes, err := base64.StdEncoding.DecodeString(signatures[i].R)
+		if err != nil {
+			return fmt.Errorf("fail to decode r of the signature: %w", err)
+		}
+		sBytes, err := base64.StdEncoding.DecodeString(signatures[i].S)
+		if err != nil {
+			return fmt.Errorf("fail to decode s of the signature: %w", err)
+		}
+		recovery, err := base64.StdEncoding.DecodeString(signatures[i].RecoveryID)
+		if err != nil {
+			return fmt.Errorf("fail to decode the recovery id of the signature: %w", err)
+		}
+		if len(rBytes) == 0 || len(rBytes) > 32 || len(sBytes) == 0 || len(sBytes) > 32 || len(recovery) != 1 {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(rBytes)
+		s, recoveryID := NormalizeLowS(new(big.Int).SetBytes(sBytes), recovery[0])
+		for _, el := range req.OutputFormats {
+			switch el {
+			case FormatDER:
+				signatures[i].DER = base64.StdEncoding.EncodeToString(encodeDER(r, s))
+			case FormatEthereum:
+				v, err := ethereumV(recoveryID, req.ChainID)
+				if err != nil {
+					return err
+				}
+				signatures[i].Ethereum = base64.StdEncoding.EncodeToString(encodeEthereum(r, s, v))
+				signatures[i].EthereumV = v.String()
+			case FormatBitcoin:
+				signatures[i].Bitcoin = base64.StdEncoding.EncodeToString(append(encodeDER(r, s), sigHashType))
+			case FormatCosmos:
+				signatures[i].Cosmos = base64.StdEncoding.EncodeToString(append(padTo32(r), padTo32(s)...))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package keysign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	becdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	. "gopkg.in/check.v1"
+)
+
+type SignatureFormatTestSuite struct{}
+
+var _ = Suite(&SignatureFormatTestSuite{})
+
+func b64(buf []byte) string {
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func newTestSignature(c *C, r, s string, recoveryID byte) Signature {
+	rInt, ok := new(big.Int).SetString(r, 10)
+	c.Assert(ok, Equals, true)
+	sInt, ok := new(big.Int).SetString(s, 10)
+	c.Assert(ok, Equals, true)
+	return NewSignature("", b64(rInt.Bytes()), b64(sInt.Bytes()), b64([]byte{recoveryID}))
+}
+
+// the example transaction of EIP-155 signed with the key 0x4646...46 on chain 1
+func (SignatureFormatTestSuite) TestEthereumFormat(c *C) {
+	r := "18515461264373351373200002665853028612451056578545711640558177340181847433846"
+	s := "46948507304638947509940763649030358759909902576025900602547168820602576006531"
+	expected, err := hex.DecodeString("28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276" +
+		"67cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83" + "25")
+	c.Assert(err, IsNil)
+
+	// the vector is signed by the key with recovery id 0
+	msgHash, err := hex.DecodeString("daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53")
+	c.Assert(err, IsNil)
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x46}, 32))
+	compact := append([]byte{27}, expected[:64]...)
+	pubKey, _, err := becdsa.RecoverCompact(compact, msgHash)
+	c.Assert(err, IsNil)
+	c.Assert(pubKey.IsEqual(privKey.PubKey()), Equals, true)
+
+	signatures := []Signature{newTestSignature(c, r, s, 0)}
+	req := Request{OutputFormats: []string{FormatEthereum}, ChainID: 1}
+	c.Assert(ApplyOutputFormats(signatures, req), IsNil)
+	c.Assert(signatures[0].Ethereum, Equals, b64(expected))
+	c.Assert(signatures[0].DER, Equals, "")
+
+	// the high-S signature is normalized with the recovery id flipped
+	sInt, _ := new(big.Int).SetString(s, 10)
+	highS := new(big.Int).Sub(btcec.S256().N, sInt)
+	signatures = []Signature{newTestSignature(c, r, highS.String(), 1)}
+	c.Assert(ApplyOutputFormats(signatures, req), IsNil)
+	c.Assert(signatures[0].Ethereum, Equals, b64(expected))
+
+	// without the chain id, v is 27 + recovery id
+	signatures = []Signature{newTestSignature(c, r, s, 0)}
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatEthereum}}), IsNil)
+	expected[64] = 27
+	c.Assert(signatures[0].Ethereum, Equals, b64(expected))
+
+	c.Assert(signatures[0].EthereumV, Equals, "27")
+
+	// v of the larger chain ids is big-endian in more than one byte, 43114*2+35+1 for avalanche
+	signatures = []Signature{newTestSignature(c, r, s, 1)}
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatEthereum}, ChainID: 43114}), IsNil)
+	c.Assert(signatures[0].Ethereum, Equals, b64(append(expected[:64:64], 0x01, 0x50, 0xf8)))
+	c.Assert(signatures[0].EthereumV, Equals, "86264")
+	signatures = []Signature{newTestSignature(c, r, s, 0)}
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatEthereum}, ChainID: 137}), IsNil)
+	c.Assert(signatures[0].Ethereum, Equals, b64(append(expected[:64:64], 0x01, 0x35)))
+	c.Assert(signatures[0].EthereumV, Equals, "309")
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatEthereum}, ChainID: -1}), NotNil)
+	c.Assert(ValidateEthereumChainID(-1), NotNil)
+}
+
+// the signature of the input of the bitcoin transaction in block 170
+func (SignatureFormatTestSuite) TestBitcoinFormat(c *C) {
+	rBytes, err := hex.DecodeString("4e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd41")
+	c.Assert(err, IsNil)
+	sBytes, err := hex.DecodeString("181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d09")
+	c.Assert(err, IsNil)
+	expected, err := hex.DecodeString("304402204e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd41" +
+		"0220181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d09")
+	c.Assert(err, IsNil)
+
+	signatures := []Signature{NewSignature("", b64(rBytes), b64(sBytes), b64([]byte{0}))}
+	req := Request{OutputFormats: []string{FormatDER, FormatBitcoin, FormatCosmos}}
+	c.Assert(ApplyOutputFormats(signatures, req), IsNil)
+	c.Assert(signatures[0].DER, Equals, b64(expected))
+	c.Assert(signatures[0].Bitcoin, Equals, b64(append(expected, SigHashAll)))
+	c.Assert(signatures[0].Cosmos, Equals, b64(append(rBytes, sBytes...)))
+
+	req.SigHashType = 0x81
+	c.Assert(ApplyOutputFormats(signatures, req), IsNil)
+	c.Assert(signatures[0].Bitcoin, Equals, b64(append(expected, 0x81)))
+}
+
+func (SignatureFormatTestSuite) TestDERPadding(c *C) {
+	// r with the high bit set is prefixed with zero, and the short s is padded in the compact format
+	r := append([]byte{0x80}, bytes.Repeat([]byte{0x01}, 31)...)
+	signatures := []Signature{NewSignature("", b64(r), b64([]byte{0x01}), b64([]byte{0}))}
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatDER, FormatCosmos}}), IsNil)
+	expected := append([]byte{0x30, 0x26, 0x02, 0x21, 0x00}, r...)
+	expected = append(expected, 0x02, 0x01, 0x01)
+	c.Assert(signatures[0].DER, Equals, b64(expected))
+	compact := append(append([]byte{}, r...), make([]byte, 31)...)
+	c.Assert(signatures[0].Cosmos, Equals, b64(append(compact, 0x01)))
+}
+
+func (SignatureFormatTestSuite) TestValidateOutputFormats(c *C) {
+	c.Assert(ValidateOutputFormats(nil), IsNil)
+	c.Assert(ValidateOutputFormats([]string{FormatDER, FormatEthereum, FormatBitcoin, FormatCosmos}), IsNil)
+	c.Assert(ValidateOutputFormats([]string{FormatDER, "solana"}), NotNil)
+
+	signatures := []Signature{NewSignature("", "invalid", b64([]byte{1}), b64([]byte{0}))}
+	c.Assert(ApplyOutputFormats(signatures, Request{OutputFormats: []string{FormatDER}}), NotNil)
+	c.Assert(ApplyOutputFormats(signatures, Request{}), IsNil)
+}
@@ -3,6 +3,7 @@ package keysign
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -213,6 +214,31 @@ func (s *SignatureNotifier) removeNotifier(n *Notifier) {
 	delete(s.notifiers, n.MessageID)
 }
 
+// PendingNotifier is the keysign waiting for the signature from the other parties
+type PendingNotifier struct {
+	MsgID      string `json:"msg_id"`
+	PoolPubKey string `json:"pool_pub_key"`
+	Messages   int    `json:"messages"`
+}
+
+// PendingNotifiers returns the keysigns waiting for the signature, in the order of the msgID
+func (s *SignatureNotifier) PendingNotifiers() []PendingNotifier {
+	s.notifierLock.Lock()
+	pending := make([]PendingNotifier, 0, len(s.notifiers))
+	for _, el := range s.notifiers {
+		pending = append(pending, PendingNotifier{
+			MsgID:      el.MessageID,
+			PoolPubKey: el.poolPubKey,
+			Messages:   len(el.messages),
+		})
+	}
+	s.notifierLock.Unlock()
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].MsgID < pending[j].MsgID
+	})
+	return pending
+}
+
 // WaitForSignature wait until keysign finished and signature is available
 func (s *SignatureNotifier) WaitForSignature(messageID string, message [][]byte, poolPubKey string, timeout time.Duration, sigChan chan string) ([]*common.ECSignature, error) {
 	n, err := NewNotifier(messageID, message, poolPubKey)
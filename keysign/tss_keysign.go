@@ -33,6 +33,9 @@ type TssKeySign struct {
 	commStopChan    chan struct{}
 	p2pComm         *p2p.Communication
 	stateManager    storage.LocalStateManager
+	msgID           string
+	privKey         tcrypto.PrivKey
+	presignIDs      []string
 }
 
 func NewTssKeySign(localP2PID string,
@@ -48,6 +51,8 @@ func NewTssKeySign(localP2PID string,
 		commStopChan:    make(chan struct{}),
 		p2pComm:         p2pComm,
 		stateManager:    stateManager,
+		msgID:           msgID,
+		privKey:         privKey,
 	}
 }
 
@@ -59,6 +64,11 @@ func (tKeySign *TssKeySign) GetTssCommonStruct() *common.TssCommon {
 	return tKeySign.tssCommonStruct
 }
 
+// SetPresignatures sets the presignatures the party agreed on in the join party to sign with
+func (tKeySign *TssKeySign) SetPresignatures(ids []string) {
+	tKeySign.presignIDs = ids
+}
+
 func (tKeySign *TssKeySign) startBatchSigning(keySignPartyMap *sync.Map, msgNum int) bool {
 	// start the batch sign
 	var keySignWg sync.WaitGroup
@@ -90,23 +100,75 @@ func (tKeySign *TssKeySign) SignMessage(msgsToSign [][]byte, localStateItem stor
 		tKeySign.logger.Info().Msgf("we are not in this rounds key sign")
 		return nil, nil
 	}
+	// we finish the keysign in one round with the presignatures the party agreed on, all the signers run the
+	// full keysign if there is none, or we fail to consume them
+	if len(tKeySign.presignIDs) != 0 {
+		presigs, err := tKeySign.stateManager.ConsumePresignatures(localStateItem.PubKey, tKeySign.presignIDs)
+		if err == nil {
+			tKeySign.logger.Info().Msgf("sign the messages with %d presignatures", len(presigs))
+			return tKeySign.signWithPresignatures(msgsToSign, localStateItem, parties, presigs)
+		}
+		tKeySign.logger.Error().Err(err).Msg("fail to consume the agreed presignatures, we run the full keysign")
+	}
+
+	var monikers []string
+	var ms []*big.Int
+	for i, val := range msgsToSign {
+		m, err := common.MsgToHashInt(val)
+		if err != nil {
+			return nil, fmt.Errorf("fail to convert msg to hash int: %w", err)
+		}
+		monikers = append(monikers, m.String()+":"+strconv.Itoa(i))
+		ms = append(ms, m)
+	}
+	results, err := tKeySign.runSigning(monikers, localStateItem, parties, func(i int, params *btss.Parameters, outCh chan<- btss.Message, endCh chan<- *signing.SignatureData) btss.Party {
+		return signing.NewLocalParty(ms[i], params, localStateItem.LocalData, outCh, endCh)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tKeySign.logger.Info().Msgf("%s successfully sign the message", tKeySign.p2pComm.GetHost().ID().String())
+	signatures := make([]*tsslibcommon.ECSignature, len(results))
+	for i, el := range results {
+		signatures[i] = el.GetSignature()
+	}
+	sortSignatures(signatures)
+	return signatures, nil
+}
+
+// sortSignatures sorts the signatures by the message in descending order, the same order we sort the messages
+func sortSignatures(signatures []*tsslibcommon.ECSignature) {
+	sort.SliceStable(signatures, func(i, j int) bool {
+		a := new(big.Int).SetBytes(signatures[i].M)
+		b := new(big.Int).SetBytes(signatures[j].M)
+
+		if a.Cmp(b) == -1 {
+			return false
+		}
+		return true
+	})
+}
+
+// runSigning runs a local signing party created by newParty for each of the monikers with the parties, and
+// returns the signing data of all the local parties
+func (tKeySign *TssKeySign) runSigning(monikers []string, localStateItem storage.KeygenLocalState, parties []string, newParty func(i int, params *btss.Parameters, outCh chan<- btss.Message, endCh chan<- *signing.SignatureData) btss.Party) ([]*signing.SignatureData, error) {
+	partiesID, _, err := conversion.GetParties(parties, localStateItem.LocalPartyKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to form key sign party: %w", err)
+	}
 	threshold, err := conversion.GetThreshold(len(localStateItem.ParticipantKeys))
 	if err != nil {
 		return nil, errors.New("fail to get threshold")
 	}
 
 	// tKeySign.logger.Debug().Msgf("local party: %+v", localPartyID)
-	outCh := make(chan btss.Message, 2*len(partiesID)*len(msgsToSign))
-	endCh := make(chan *signing.SignatureData, len(partiesID)*len(msgsToSign))
+	outCh := make(chan btss.Message, 2*len(partiesID)*len(monikers))
+	endCh := make(chan *signing.SignatureData, len(partiesID)*len(monikers))
 	errCh := make(chan struct{})
 
 	keySignPartyMap := new(sync.Map)
-	for i, val := range msgsToSign {
-		m, err := common.MsgToHashInt(val)
-		if err != nil {
-			return nil, fmt.Errorf("fail to convert msg to hash int: %w", err)
-		}
-		moniker := m.String() + ":" + strconv.Itoa(i)
+	for i, moniker := range monikers {
 		partiesID, eachLocalPartyID, err := conversion.GetParties(parties, localStateItem.LocalPartyKey)
 		ctx := btss.NewPeerContext(partiesID)
 		if err != nil {
@@ -115,7 +177,7 @@ func (tKeySign *TssKeySign) SignMessage(msgsToSign [][]byte, localStateItem stor
 		eachLocalPartyID.Moniker = moniker
 		tKeySign.localParties = nil
 		params := btss.NewParameters(ctx, eachLocalPartyID, len(partiesID), threshold)
-		keySignParty := signing.NewLocalParty(m, params, localStateItem.LocalData, outCh, endCh)
+		keySignParty := newParty(i, params, outCh, endCh)
 		keySignPartyMap.Store(moniker, keySignParty)
 	}
 
@@ -143,13 +205,13 @@ func (tKeySign *TssKeySign) SignMessage(msgsToSign [][]byte, localStateItem stor
 	// start the key sign
 	go func() {
 		defer keySignWg.Done()
-		ret := tKeySign.startBatchSigning(keySignPartyMap, len(msgsToSign))
+		ret := tKeySign.startBatchSigning(keySignPartyMap, len(monikers))
 		if !ret {
 			close(errCh)
 		}
 	}()
 	go tKeySign.tssCommonStruct.ProcessInboundMessages(tKeySign.commStopChan, &keySignWg)
-	results, err := tKeySign.processKeySign(len(msgsToSign), errCh, outCh, endCh)
+	results, err := tKeySign.processKeySign(len(monikers), errCh, outCh, endCh)
 	if err != nil {
 		close(tKeySign.commStopChan)
 		return nil, fmt.Errorf("fail to process key sign: %w", err)
@@ -162,28 +224,21 @@ func (tKeySign *TssKeySign) SignMessage(msgsToSign [][]byte, localStateItem stor
 		close(tKeySign.commStopChan)
 	}
 	keySignWg.Wait()
-
-	tKeySign.logger.Info().Msgf("%s successfully sign the message", tKeySign.p2pComm.GetHost().ID().String())
-	sort.SliceStable(results, func(i, j int) bool {
-		a := new(big.Int).SetBytes(results[i].M)
-		b := new(big.Int).SetBytes(results[j].M)
-
-		if a.Cmp(b) == -1 {
-			return false
-		}
-		return true
-	})
-
 	return results, nil
 }
 
-func (tKeySign *TssKeySign) processKeySign(reqNum int, errChan chan struct{}, outCh <-chan btss.Message, endCh <-chan *signing.SignatureData) ([]*tsslibcommon.ECSignature, error) {
+func (tKeySign *TssKeySign) processKeySign(reqNum int, errChan chan struct{}, outCh <-chan btss.Message, endCh <-chan *signing.SignatureData) ([]*signing.SignatureData, error) {
 	defer tKeySign.logger.Debug().Msg("key sign finished")
 	tKeySign.logger.Debug().Msg("start to read messages from local party")
-	var signatures []*tsslibcommon.ECSignature
+	var signatures []*signing.SignatureData
 
 	tssConf := tKeySign.tssCommonStruct.GetConf()
-	blameMgr := tKeySign.tssCommonStruct.GetBlameMgr()
+	// each round can have its own deadline, and the whole keysign can have a hard deadline, along with the timeout
+	// on no message for KeySignTimeout
+	roundTimer := common.NewRoundTimer(tssConf.KeySignRoundTimeout)
+	defer roundTimer.Stop()
+	deadline := common.NewDeadline(tssConf.KeySignDeadline)
+	defer deadline.Stop()
 
 	for {
 		select {
@@ -192,69 +247,27 @@ func (tKeySign *TssKeySign) processKeySign(reqNum int, errChan chan struct{}, ou
 			return nil, errors.New("error channel closed fail to start local party")
 		case <-tKeySign.stopChan: // when TSS processor receive signal to quit
 			return nil, errors.New("received exit signal")
+		case <-roundTimer.C():
+			tKeySign.logger.Error().Msgf("fail to finish the keysign round with %s", tssConf.KeySignRoundTimeout.String())
+			return nil, tKeySign.processTimeout()
+		case <-deadline.C():
+			tKeySign.logger.Error().Msgf("fail to finish the keysign before the deadline %s", tssConf.KeySignDeadline.String())
+			return nil, tKeySign.processTimeout()
 		case <-time.After(tssConf.KeySignTimeout):
 			// we bail out after KeySignTimeoutSeconds
 			tKeySign.logger.Error().Msgf("fail to sign message with %s", tssConf.KeySignTimeout.String())
-			lastMsg := blameMgr.GetLastMsg()
-			failReason := blameMgr.GetBlame().FailReason
-			if failReason == "" {
-				failReason = blame.TssTimeout
-			}
-
-			tKeySign.tssCommonStruct.P2PPeersLock.RLock()
-			threshold, err := conversion.GetThreshold(len(tKeySign.tssCommonStruct.P2PPeers) + 1)
-			tKeySign.tssCommonStruct.P2PPeersLock.RUnlock()
-			if err != nil {
-				tKeySign.logger.Error().Err(err).Msg("error in get the threshold for generate blame")
-			}
-			if !lastMsg.IsBroadcast() {
-				blameNodesUnicast, err := blameMgr.GetUnicastBlame(lastMsg.Type())
-				if err != nil {
-					tKeySign.logger.Error().Err(err).Msg("error in get unicast blame")
-				}
-				if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
-					blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
-				}
-			} else {
-				blameNodesUnicast, err := blameMgr.GetUnicastBlame(conversion.GetPreviousKeySignUicast(lastMsg.Type()))
-				if err != nil {
-					tKeySign.logger.Error().Err(err).Msg("error in get unicast blame")
-				}
-				if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
-					blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
-				}
-			}
-
-			blameNodesBroadcast, err := blameMgr.GetBroadcastBlame(lastMsg.Type())
-			if err != nil {
-				tKeySign.logger.Error().Err(err).Msg("error in get broadcast blame")
-			}
-			blameMgr.GetBlame().AddBlameNodes(blameNodesBroadcast...)
-
-			// if we cannot find the blame node, we check whether everyone send me the share
-			if len(blameMgr.GetBlame().BlameNodes) == 0 {
-				blameNodesMisingShare, isUnicast, err := blameMgr.TssMissingShareBlame(messages.TSSKEYSIGNROUNDS)
-				if err != nil {
-					tKeySign.logger.Error().Err(err).Msg("fail to get the node of missing share ")
-				}
-
-				if len(blameNodesMisingShare) > 0 && len(blameNodesMisingShare) <= threshold {
-					blameMgr.GetBlame().AddBlameNodes(blameNodesMisingShare...)
-					blameMgr.GetBlame().IsUnicast = isUnicast
-				}
-			}
-
-			return nil, blame.ErrTssTimeOut
+			return nil, tKeySign.processTimeout()
 		case msg := <-outCh:
 			tKeySign.logger.Debug().Msgf(">>>>>>>>>>key sign msg: %s", msg.String())
 			tKeySign.tssCommonStruct.GetBlameMgr().SetLastMsg(msg)
+			roundTimer.Update(msg)
 			err := tKeySign.tssCommonStruct.ProcessOutCh(msg, messages.TSSKeySignMsg)
 			if err != nil {
 				return nil, err
 			}
 
 		case msg := <-endCh:
-			signatures = append(signatures, msg.GetSignature())
+			signatures = append(signatures, msg)
 			if len(signatures) == reqNum {
 				tKeySign.logger.Debug().Msg("we have done the key sign")
 				err := tKeySign.tssCommonStruct.NotifyTaskDone()
@@ -271,3 +284,66 @@ func (tKeySign *TssKeySign) processKeySign(reqNum int, errChan chan struct{}, ou
 		}
 	}
 }
+
+// processTimeout blames the parties that cause the keysign timeout
+func (tKeySign *TssKeySign) processTimeout() error {
+	blameMgr := tKeySign.tssCommonStruct.GetBlameMgr()
+	lastMsg := blameMgr.GetLastMsg()
+	failReason := blameMgr.GetBlame().FailReason
+	if failReason == "" {
+		failReason = blame.TssTimeout
+	}
+	if lastMsg == nil {
+		tKeySign.logger.Error().Msg("fail to start the keysign, the last produced message of this node is none")
+		return errors.New("timeout before shared message is generated")
+	}
+	// we name the parties missing in the round that expired
+	if tKeySign.tssCommonStruct.BlameMissingRound(lastMsg, messages.TSSKeySignRoundMsgs, failReason) {
+		return blame.ErrTssTimeOut
+	}
+
+	tKeySign.tssCommonStruct.P2PPeersLock.RLock()
+	threshold, err := conversion.GetThreshold(len(tKeySign.tssCommonStruct.P2PPeers) + 1)
+	tKeySign.tssCommonStruct.P2PPeersLock.RUnlock()
+	if err != nil {
+		tKeySign.logger.Error().Err(err).Msg("error in get the threshold for generate blame")
+	}
+	if !lastMsg.IsBroadcast() {
+		blameNodesUnicast, err := blameMgr.GetUnicastBlame(lastMsg.Type())
+		if err != nil {
+			tKeySign.logger.Error().Err(err).Msg("error in get unicast blame")
+		}
+		if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
+			blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
+		}
+	} else {
+		blameNodesUnicast, err := blameMgr.GetUnicastBlame(conversion.GetPreviousKeySignUicast(lastMsg.Type()))
+		if err != nil {
+			tKeySign.logger.Error().Err(err).Msg("error in get unicast blame")
+		}
+		if len(blameNodesUnicast) > 0 && len(blameNodesUnicast) <= threshold {
+			blameMgr.GetBlame().SetBlame(failReason, blameNodesUnicast, true)
+		}
+	}
+
+	blameNodesBroadcast, err := blameMgr.GetBroadcastBlame(lastMsg.Type())
+	if err != nil {
+		tKeySign.logger.Error().Err(err).Msg("error in get broadcast blame")
+	}
+	blameMgr.GetBlame().AddBlameNodes(blameNodesBroadcast...)
+
+	// if we cannot find the blame node, we check whether everyone send me the share
+	if len(blameMgr.GetBlame().BlameNodes) == 0 {
+		blameNodesMisingShare, isUnicast, err := blameMgr.TssMissingShareBlame(messages.TSSKEYSIGNROUNDS)
+		if err != nil {
+			tKeySign.logger.Error().Err(err).Msg("fail to get the node of missing share ")
+		}
+
+		if len(blameNodesMisingShare) > 0 && len(blameNodesMisingShare) <= threshold {
+			blameMgr.GetBlame().AddBlameNodes(blameNodesMisingShare...)
+			blameMgr.GetBlame().IsUnicast = isUnicast
+		}
+	}
+
+	return blame.ErrTssTimeOut
+}
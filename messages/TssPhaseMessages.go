@@ -16,3 +16,20 @@ const (
 	TSSKEYGENROUNDS  = 4
 	TSSKEYSIGNROUNDS = 8
 )
+
+var (
+	// TSSKeyGenRoundMsgs are the keygen messages indexed by their round
+	TSSKeyGenRoundMsgs = []string{KEYGEN1, KEYGEN2aUnicast, KEYGEN2b, KEYGEN3}
+	// TSSKeySignRoundMsgs are the keysign messages indexed by their round
+	TSSKeySignRoundMsgs = []string{KEYSIGN1aUnicast, KEYSIGN1b, KEYSIGN2Unicast, KEYSIGN3, KEYSIGN4, KEYSIGN5, KEYSIGN6, KEYSIGN7}
+)
+
+// IsUnicastRound returns true if the messages of the round are sent to each party separately
+func IsUnicastRound(roundMsg string) bool {
+	switch roundMsg {
+	case KEYGEN2aUnicast, KEYSIGN1aUnicast, KEYSIGN2Unicast:
+		return true
+	default:
+		return false
+	}
+}
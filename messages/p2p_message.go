@@ -23,6 +23,10 @@ const (
 	TSSControlMsg
 	// TSSTaskDone is the message of Tss process notification
 	TSSTaskDone
+	// TSSBlameVote is the signed blame list the parties exchange to agree on the blame after a failed ceremony
+	TSSBlameVote
+	// TSSPresignShare is the signature share the signers exchange in the online round of the presigned keysign
+	TSSPresignShare
 	// Unknown is the message indicates the undefined message type
 	Unknown
 )
@@ -38,6 +42,14 @@ func (msgType THORChainTSSMessageType) String() string {
 		return "TSSKeyGenVerMsg"
 	case TSSKeySignVerMsg:
 		return "TSSKeySignVerMsg"
+	case TSSControlMsg:
+		return "TSSControlMsg"
+	case TSSTaskDone:
+		return "TSSTaskDone"
+	case TSSBlameVote:
+		return "TSSBlameVote"
+	case TSSPresignShare:
+		return "TSSPresignShare"
 	default:
 		return "Unknown"
 	}
@@ -48,6 +60,8 @@ type WrappedMessage struct {
 	MessageType THORChainTSSMessageType `json:"message_type"`
 	MsgID       string                  `json:"message_id"`
 	Payload     []byte                  `json:"payload"`
+	// TraceContext carries the trace of the sender, so the handling of the message joins the trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
 
 // BroadcastMsgChan is the channel structure for keygen/keysign submit message to p2p network
@@ -86,3 +100,9 @@ type TssControl struct {
 type TssTaskNotifier struct {
 	TaskDone bool `json:"task_done"`
 }
+
+// PresignShare is the signature shares of the messages, each signed with the presignature of the same index
+type PresignShare struct {
+	PresignIDs []string `json:"presign_ids"`
+	Shares     [][]byte `json:"shares"`
+}
@@ -20,6 +20,11 @@ func (THORChainTSSMessageTypeSuite) TestTHORChainTSSMessageType_String(c *C) {
 		TSSKeySignMsg:    "TSSKeySignMsg",
 		TSSKeyGenVerMsg:  "TSSKeyGenVerMsg",
 		TSSKeySignVerMsg: "TSSKeySignVerMsg",
+		TSSControlMsg:    "TSSControlMsg",
+		TSSTaskDone:      "TSSTaskDone",
+		TSSBlameVote:     "TSSBlameVote",
+		TSSPresignShare:  "TSSPresignShare",
+		Unknown:          "Unknown",
 	}
 	for k, v := range m {
 		c.Assert(k.String(), Equals, v)
@@ -2,4 +2,7 @@ package messages
 
 const (
 	NEWJOINPARTYVERSION = "0.14.0"
+	// SIGNEDJOINPARTYVERSION is the version from which the join party messages must be signed, the older
+	// peers send the unsigned messages during the rolling upgrade
+	SIGNEDJOINPARTYVERSION = "0.15.0"
 )
@@ -8,52 +8,205 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// the phases of the keygen and keysign the PhaseObserver is told about
+const (
+	PhaseKeygenJoinParty  = "keygen_join_party"
+	PhaseKeygen           = "keygen"
+	PhaseKeysignJoinParty = "keysign_join_party"
+	PhaseKeysign          = "keysign"
+)
+
+// the phases the PhaseObserver is told about for each message, so the observer sums them up for the ceremony
+const (
+	// PhaseHashCheck is from the first copy or confirmation of the broadcast message we get to its hash check pass
+	PhaseHashCheck = "hash_check"
+	// PhaseJSONFraming is the json encoding of the message we send and the decoding of the message we receive
+	PhaseJSONFraming = "json_framing"
+	// PhaseStreamSetup is the opening of the stream to the peer we send the message to
+	PhaseStreamSetup = "stream_setup"
+)
+
+// the directions of the p2p traffic and the share requests
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// the types of the ceremonies counted by the active ceremony gauge
+const (
+	CeremonyKeygen  = "keygen"
+	CeremonyKeysign = "keysign"
+	CeremonyPresign = "presign"
+)
+
+// PhaseObserver is told the time spent in each phase of the keygen and keysign, for example to benchmark the server
+type PhaseObserver func(phase string, elapsed time.Duration, success bool)
+
+var (
+	// ceremonyBuckets covers 0.1 second to about 3 minutes
+	ceremonyBuckets = prometheus.ExponentialBuckets(0.1, 2, 12)
+	// roundBuckets covers 1 millisecond to about 16 seconds
+	roundBuckets = prometheus.ExponentialBuckets(0.001, 2, 15)
+)
+
 type Metric struct {
 	keygenCounter    *prometheus.CounterVec
 	keysignCounter   *prometheus.CounterVec
 	joinPartyCounter *prometheus.CounterVec
-	keySignTime      prometheus.Gauge
-	keyGenTime       prometheus.Gauge
-	joinPartyTime    *prometheus.GaugeVec
+	keySignTime      *prometheus.HistogramVec
+	keyGenTime       *prometheus.HistogramVec
+	joinPartyTime    *prometheus.HistogramVec
+	roundTime        *prometheus.HistogramVec
+	p2pBytes         *prometheus.CounterVec
+	hashCheckFailure *prometheus.CounterVec
+	shareRequest     *prometheus.CounterVec
+	blameCounter     *prometheus.CounterVec
+	activeCeremony   *prometheus.GaugeVec
+	presignPoolDepth *prometheus.GaugeVec
 	logger           zerolog.Logger
+	observer         PhaseObserver
+}
+
+// SetPhaseObserver sets the observer of the phases, nil removes it
+func (m *Metric) SetPhaseObserver(observer PhaseObserver) {
+	m.observer = observer
+}
+
+func (m Metric) observe(phase string, elapsed time.Duration, success bool) {
+	if m.observer != nil {
+		m.observer(phase, elapsed, success)
+	}
 }
 
-func (m *Metric) UpdateKeyGen(keygenTime time.Duration, success bool) {
+// ObservePhase tells the observer the time spent in the phase of one message
+func (m *Metric) ObservePhase(phase string, elapsed time.Duration, success bool) {
+	if m == nil {
+		return
+	}
+	m.observe(phase, elapsed, success)
+}
+
+// UpdateKeyGen records the keygen of the pool, the pool pub key is empty if the keygen fails
+func (m *Metric) UpdateKeyGen(poolPubKey string, keygenTime time.Duration, success bool) {
+	if m == nil {
+		return
+	}
+	m.observe(PhaseKeygen, keygenTime, success)
 	if success {
-		m.keyGenTime.Set(float64(keygenTime))
+		m.keyGenTime.WithLabelValues(poolPubKey).Observe(keygenTime.Seconds())
 		m.keygenCounter.WithLabelValues("success").Inc()
 	} else {
 		m.keygenCounter.WithLabelValues("failure").Inc()
 	}
 }
 
-func (m *Metric) UpdateKeySign(keysignTime time.Duration, success bool) {
+// UpdateKeySign records the keysign with the pool
+func (m *Metric) UpdateKeySign(poolPubKey string, keysignTime time.Duration, success bool) {
+	if m == nil {
+		return
+	}
+	m.observe(PhaseKeysign, keysignTime, success)
 	if success {
-		m.keySignTime.Set(float64(keysignTime))
+		m.keySignTime.WithLabelValues(poolPubKey).Observe(keysignTime.Seconds())
 		m.keysignCounter.WithLabelValues("success").Inc()
 	} else {
 		m.keysignCounter.WithLabelValues("failure").Inc()
 	}
 }
 
-func (m Metric) KeygenJoinParty(joinpartyTime time.Duration, success bool) {
+// KeygenJoinParty records the join party of the keygen
+func (m *Metric) KeygenJoinParty(joinpartyTime time.Duration, success bool) {
+	if m == nil {
+		return
+	}
+	m.observe(PhaseKeygenJoinParty, joinpartyTime, success)
 	if success {
-		m.joinPartyTime.WithLabelValues("keygen").Set(float64(joinpartyTime))
+		// the pool does not exist before the keygen
+		m.joinPartyTime.WithLabelValues("keygen", "").Observe(joinpartyTime.Seconds())
 		m.joinPartyCounter.WithLabelValues("keygen", "success").Inc()
 	} else {
 		m.joinPartyCounter.WithLabelValues("keygen", "failure").Inc()
 	}
 }
 
-func (m *Metric) KeysignJoinParty(joinpartyTime time.Duration, success bool) {
+// KeysignJoinParty records the join party of the keysign with the pool
+func (m *Metric) KeysignJoinParty(poolPubKey string, joinpartyTime time.Duration, success bool) {
+	if m == nil {
+		return
+	}
+	m.observe(PhaseKeysignJoinParty, joinpartyTime, success)
 	if success {
-		m.joinPartyTime.WithLabelValues("keysign").Set(float64(joinpartyTime))
+		m.joinPartyTime.WithLabelValues("keysign", poolPubKey).Observe(joinpartyTime.Seconds())
 		m.joinPartyCounter.WithLabelValues("keysign", "success").Inc()
 	} else {
 		m.joinPartyCounter.WithLabelValues("keysign", "failure").Inc()
 	}
 }
 
+// UpdatePresignPoolDepth sets the number of presignatures we have for the pool
+func (m *Metric) UpdatePresignPoolDepth(poolPubKey string, depth int) {
+	if m == nil {
+		return
+	}
+	m.presignPoolDepth.WithLabelValues(poolPubKey).Set(float64(depth))
+}
+
+// UpdateRoundTime records the time spent to apply one message of the round to the local party
+func (m *Metric) UpdateRoundTime(round string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.roundTime.WithLabelValues(round).Observe(elapsed.Seconds())
+}
+
+// UpdateP2PBytes counts the bytes of the message sent to or received from the peer
+func (m *Metric) UpdateP2PBytes(direction, peer, msgType string, size int) {
+	if m == nil {
+		return
+	}
+	m.p2pBytes.WithLabelValues(direction, peer, msgType).Add(float64(size))
+}
+
+// HashCheckFailure counts the shares that fail the hash check
+func (m *Metric) HashCheckFailure(reason string) {
+	if m == nil {
+		return
+	}
+	m.hashCheckFailure.WithLabelValues(reason).Inc()
+}
+
+// ShareRequest counts the share requests we send to or receive from the peers
+func (m *Metric) ShareRequest(direction string) {
+	if m == nil {
+		return
+	}
+	m.shareRequest.WithLabelValues(direction).Inc()
+}
+
+// UpdateBlame counts the blames of the peer
+func (m *Metric) UpdateBlame(peer, reason string) {
+	if m == nil {
+		return
+	}
+	m.blameCounter.WithLabelValues(peer, reason).Inc()
+}
+
+// CeremonyStarted increases the number of the active ceremonies of the type
+func (m *Metric) CeremonyStarted(ceremony string) {
+	if m == nil {
+		return
+	}
+	m.activeCeremony.WithLabelValues(ceremony).Inc()
+}
+
+// CeremonyFinished decreases the number of the active ceremonies of the type
+func (m *Metric) CeremonyFinished(ceremony string) {
+	if m == nil {
+		return
+	}
+	m.activeCeremony.WithLabelValues(ceremony).Dec()
+}
+
 func (m *Metric) Enable() {
 	prometheus.MustRegister(m.keygenCounter)
 	prometheus.MustRegister(m.keysignCounter)
@@ -61,6 +214,13 @@ func (m *Metric) Enable() {
 	prometheus.MustRegister(m.keyGenTime)
 	prometheus.MustRegister(m.keySignTime)
 	prometheus.MustRegister(m.joinPartyTime)
+	prometheus.MustRegister(m.roundTime)
+	prometheus.MustRegister(m.p2pBytes)
+	prometheus.MustRegister(m.hashCheckFailure)
+	prometheus.MustRegister(m.shareRequest)
+	prometheus.MustRegister(m.blameCounter)
+	prometheus.MustRegister(m.activeCeremony)
+	prometheus.MustRegister(m.presignPoolDepth)
 }
 
 func NewMetric() *Metric {
@@ -96,32 +256,90 @@ func NewMetric() *Metric {
 			"type", "result",
 		}),
 
-		keyGenTime: prometheus.NewGauge(
-			prometheus.GaugeOpts{
+		keyGenTime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
 				Namespace: "Tss",
 				Subsystem: "Tss",
-				Name:      "keygen_time",
-				Help:      "the time spend for the latest keygen",
-			},
-		),
+				Name:      "keygen_seconds",
+				Help:      "the seconds spent for the successful keygen of the pool",
+				Buckets:   ceremonyBuckets,
+			}, []string{"pool"}),
 
-		keySignTime: prometheus.NewGauge(
-			prometheus.GaugeOpts{
+		keySignTime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
 				Namespace: "Tss",
 				Subsystem: "Tss",
-				Name:      "keysign_time",
-				Help:      "the time spend for the latest keysign",
-			},
-		),
+				Name:      "keysign_seconds",
+				Help:      "the seconds spent for the successful keysign with the pool",
+				Buckets:   ceremonyBuckets,
+			}, []string{"pool"}),
+
+		joinPartyTime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "joinparty_seconds",
+				Help:      "the seconds spent for the successful keysign/keygen join party",
+				Buckets:   ceremonyBuckets,
+			}, []string{"type", "pool"}),
 
-		joinPartyTime: prometheus.NewGaugeVec(
+		roundTime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "round_seconds",
+				Help:      "the seconds spent to apply one message of the round",
+				Buckets:   roundBuckets,
+			}, []string{"round"}),
+
+		p2pBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "p2p_bytes_total",
+				Help:      "the bytes sent to and received from the peers",
+			}, []string{"direction", "peer", "msg_type"}),
+
+		hashCheckFailure: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "hash_check_failure_total",
+				Help:      "the shares failing the hash check",
+			}, []string{"reason"}),
+
+		shareRequest: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "share_request_total",
+				Help:      "the share requests sent to and received from the peers",
+			}, []string{"direction"}),
+
+		blameCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "blame_total",
+				Help:      "the blames of the peers in the failed ceremonies",
+			}, []string{"peer", "reason"}),
+
+		activeCeremony: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: "Tss",
 				Subsystem: "Tss",
-				Name:      "joinparty_time",
-				Help:      "the time spend for the latest keysign/keygen join party",
+				Name:      "active_ceremony",
+				Help:      "the number of the ongoing keygen/keysign/presign",
 			}, []string{"type"}),
 
+		presignPoolDepth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "Tss",
+				Subsystem: "Tss",
+				Name:      "presign_pool_depth",
+				Help:      "the number of presignatures left for the pool",
+			}, []string{"pool"}),
+
 		logger: log.With().Str("module", "tssMonitor").Logger(),
 	}
 	return &metrics
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -20,15 +21,15 @@ func getCounterValue(metric *prometheus.CounterVec, flag string) (float64, error
 func TestMetric_UpdateKeySign(t *testing.T) {
 	metrics := NewMetric()
 	testTime := time.Second
-	metrics.UpdateKeySign(testTime, true)
-	metrics.UpdateKeySign(testTime, true)
-	metrics.UpdateKeySign(testTime, true)
+	metrics.UpdateKeySign("pool1", testTime, true)
+	metrics.UpdateKeySign("pool1", testTime, true)
+	metrics.UpdateKeySign("pool1", testTime, true)
 
-	metrics.UpdateKeySign(testTime, false)
-	metrics.UpdateKeySign(testTime, false)
-	metrics.UpdateKeySign(testTime, false)
-	metrics.UpdateKeySign(testTime, false)
-	metrics.UpdateKeySign(testTime, false)
+	metrics.UpdateKeySign("pool1", testTime, false)
+	metrics.UpdateKeySign("pool1", testTime, false)
+	metrics.UpdateKeySign("pool1", testTime, false)
+	metrics.UpdateKeySign("pool1", testTime, false)
+	metrics.UpdateKeySign("pool1", testTime, false)
 
 	val, err := getCounterValue(metrics.keysignCounter, "success")
 	assert.Nil(t, err)
@@ -38,24 +39,24 @@ func TestMetric_UpdateKeySign(t *testing.T) {
 	assert.Equal(t, float64(5), val)
 
 	m := &dto.Metric{}
-	err = metrics.keySignTime.Write(m)
+	err = metrics.keySignTime.WithLabelValues("pool1").(prometheus.Histogram).Write(m)
 	assert.Nil(t, err)
-	val = m.Gauge.GetValue()
-	assert.Equal(t, float64(time.Second), val)
+	assert.Equal(t, uint64(3), m.Histogram.GetSampleCount())
+	assert.Equal(t, float64(3), m.Histogram.GetSampleSum())
 }
 
 func TestMetric_UpdateKeyGen(t *testing.T) {
 	metrics := NewMetric()
 	testTime := time.Second
-	metrics.UpdateKeyGen(testTime, true)
-	metrics.UpdateKeyGen(testTime, true)
-	metrics.UpdateKeyGen(testTime, true)
+	metrics.UpdateKeyGen("pool1", testTime, true)
+	metrics.UpdateKeyGen("pool1", testTime, true)
+	metrics.UpdateKeyGen("pool1", testTime, true)
 
-	metrics.UpdateKeyGen(testTime, false)
-	metrics.UpdateKeyGen(testTime, false)
-	metrics.UpdateKeyGen(testTime, false)
-	metrics.UpdateKeyGen(testTime, false)
-	metrics.UpdateKeyGen(testTime, false)
+	metrics.UpdateKeyGen("pool1", testTime, false)
+	metrics.UpdateKeyGen("pool1", testTime, false)
+	metrics.UpdateKeyGen("pool1", testTime, false)
+	metrics.UpdateKeyGen("pool1", testTime, false)
+	metrics.UpdateKeyGen("pool1", testTime, false)
 
 	val, err := getCounterValue(metrics.keygenCounter, "success")
 	assert.Nil(t, err)
@@ -63,4 +64,110 @@ func TestMetric_UpdateKeyGen(t *testing.T) {
 	val, err = getCounterValue(metrics.keygenCounter, "failure")
 	assert.Nil(t, err)
 	assert.Equal(t, float64(5), val)
+
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.keyGenTime.WithLabelValues("pool1").(prometheus.Histogram).Write(m))
+	assert.Equal(t, uint64(3), m.Histogram.GetSampleCount())
+}
+
+func TestMetric_JoinParty(t *testing.T) {
+	metrics := NewMetric()
+	metrics.KeygenJoinParty(time.Second, true)
+	metrics.KeysignJoinParty("pool1", 2*time.Second, true)
+	metrics.KeysignJoinParty("pool1", time.Second, false)
+
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.joinPartyTime.WithLabelValues("keygen", "").(prometheus.Histogram).Write(m))
+	assert.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+	assert.Nil(t, metrics.joinPartyTime.WithLabelValues("keysign", "pool1").(prometheus.Histogram).Write(m))
+	assert.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+	assert.Equal(t, float64(2), m.Histogram.GetSampleSum())
+}
+
+func TestMetric_Labelled(t *testing.T) {
+	metrics := NewMetric()
+	metrics.UpdateRoundTime("KGRound1Message", 10*time.Millisecond)
+	metrics.UpdateRoundTime("KGRound1Message", 30*time.Millisecond)
+	metrics.UpdateP2PBytes(DirectionSent, "peer1", "TSSKeyGenMsg", 100)
+	metrics.UpdateP2PBytes(DirectionSent, "peer1", "TSSKeyGenMsg", 50)
+	metrics.UpdateP2PBytes(DirectionReceived, "peer1", "TSSKeyGenMsg", 10)
+	metrics.HashCheckFailure("inconsistent")
+	metrics.ShareRequest(DirectionSent)
+	metrics.ShareRequest(DirectionSent)
+	metrics.UpdateBlame("pubkey1", "hash check failed")
+	metrics.CeremonyStarted(CeremonyKeygen)
+	metrics.CeremonyStarted(CeremonyKeygen)
+	metrics.CeremonyFinished(CeremonyKeygen)
+
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.roundTime.WithLabelValues("KGRound1Message").(prometheus.Histogram).Write(m))
+	assert.Equal(t, uint64(2), m.Histogram.GetSampleCount())
+	assert.InDelta(t, 0.04, m.Histogram.GetSampleSum(), 1e-9)
+	assert.Nil(t, metrics.p2pBytes.WithLabelValues(DirectionSent, "peer1", "TSSKeyGenMsg").Write(m))
+	assert.Equal(t, float64(150), m.Counter.GetValue())
+	assert.Nil(t, metrics.p2pBytes.WithLabelValues(DirectionReceived, "peer1", "TSSKeyGenMsg").Write(m))
+	assert.Equal(t, float64(10), m.Counter.GetValue())
+	val, err := getCounterValue(metrics.hashCheckFailure, "inconsistent")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), val)
+	val, err = getCounterValue(metrics.shareRequest, DirectionSent)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(2), val)
+	assert.Nil(t, metrics.blameCounter.WithLabelValues("pubkey1", "hash check failed").Write(m))
+	assert.Equal(t, float64(1), m.Counter.GetValue())
+	assert.Nil(t, metrics.activeCeremony.WithLabelValues(CeremonyKeygen).Write(m))
+	assert.Equal(t, float64(1), m.Gauge.GetValue())
+}
+
+func TestMetric_Nil(t *testing.T) {
+	var metrics *Metric
+	assert.NotPanics(t, func() {
+		metrics.UpdateRoundTime("round", time.Second)
+		metrics.UpdateP2PBytes(DirectionSent, "peer", "type", 1)
+		metrics.HashCheckFailure("reason")
+		metrics.ShareRequest(DirectionReceived)
+		metrics.UpdateBlame("peer", "reason")
+		metrics.CeremonyStarted(CeremonyKeysign)
+		metrics.CeremonyFinished(CeremonyKeysign)
+		metrics.ObservePhase(PhaseHashCheck, time.Second, true)
+		metrics.UpdateKeyGen("pool", time.Second, true)
+		metrics.UpdateKeySign("pool", time.Second, true)
+		metrics.KeygenJoinParty(time.Second, true)
+		metrics.KeysignJoinParty("pool", time.Second, true)
+		metrics.UpdatePresignPoolDepth("pool", 1)
+	})
+}
+
+func TestMetric_UpdatePresignPoolDepth(t *testing.T) {
+	metrics := NewMetric()
+	metrics.UpdatePresignPoolDepth("pool1", 10)
+	metrics.UpdatePresignPoolDepth("pool2", 3)
+	metrics.UpdatePresignPoolDepth("pool1", 9)
+
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.presignPoolDepth.WithLabelValues("pool1").Write(m))
+	assert.Equal(t, float64(9), m.Gauge.GetValue())
+	assert.Nil(t, metrics.presignPoolDepth.WithLabelValues("pool2").Write(m))
+	assert.Equal(t, float64(3), m.Gauge.GetValue())
+}
+
+func TestMetric_PhaseObserver(t *testing.T) {
+	metrics := NewMetric()
+	var phases []string
+	metrics.SetPhaseObserver(func(phase string, elapsed time.Duration, success bool) {
+		assert.Equal(t, time.Second, elapsed)
+		phases = append(phases, fmt.Sprintf("%s-%v", phase, success))
+	})
+	metrics.KeygenJoinParty(time.Second, true)
+	metrics.UpdateKeyGen("", time.Second, false)
+	metrics.KeysignJoinParty("pool1", time.Second, false)
+	metrics.UpdateKeySign("pool1", time.Second, true)
+	metrics.ObservePhase(PhaseHashCheck, time.Second, true)
+	assert.Equal(t, []string{"keygen_join_party-true", "keygen-false", "keysign_join_party-false", "keysign-true", "hash_check-true"}, phases)
+
+	metrics.SetPhaseObserver(nil)
+	metrics.UpdateKeySign("pool1", time.Second, true)
+	assert.Len(t, phases, 5)
+	var noMetrics *Metric
+	noMetrics.ObservePhase(PhaseHashCheck, time.Second, true)
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
 	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	maddr "github.com/multiformats/go-multiaddr"
@@ -25,6 +26,8 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
+	"github.com/joltify-finance/tss/transcript"
 )
 
 var (
@@ -61,6 +64,15 @@ type Communication struct {
 	externalAddr     maddr.Multiaddr
 	streamMgr        *StreamMgr
 	dht              *dht.IpfsDHT
+	enableMDNS       bool
+	mdnsService      mdns.Service
+	enableRelay      bool
+	relayService     bool
+	relayPeers       []maddr.Multiaddr
+	behindNAT        bool
+	recorder         *transcript.Recorder
+	metrics          *monitor.Metric
+	host             host.Host
 }
 
 // NewCommunication create a new instance of Communication
@@ -92,6 +104,20 @@ func NewCommunication(rendezvous string, bootstrapPeers []maddr.Multiaddr, port
 	}, nil
 }
 
+// NewCommunicationWithHost create a new instance of Communication on the given libp2p host in place of the tcp host,
+// for example the host on the mock network. The host must have the identity of the key given to Start
+func NewCommunicationWithHost(rendezvous string, bootstrapPeers []maddr.Multiaddr, h host.Host) (*Communication, error) {
+	if h == nil {
+		return nil, errors.New("host is nil")
+	}
+	c, err := NewCommunication(rendezvous, bootstrapPeers, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	c.host = h
+	return c, nil
+}
+
 // GetHost return the host
 func (c *Communication) GetHost() host.Host {
 	return c.dht.Host()
@@ -102,6 +128,16 @@ func (c *Communication) GetLocalPeerID() string {
 	return c.dht.Host().ID().String()
 }
 
+// SetRecorder records the messages we received for the ceremonies in their transcripts
+func (c *Communication) SetRecorder(recorder *transcript.Recorder) {
+	c.recorder = recorder
+}
+
+// SetMetrics counts the bytes we send to and receive from the peers in the metrics
+func (c *Communication) SetMetrics(metrics *monitor.Metric) {
+	c.metrics = metrics
+}
+
 // Broadcast message to Peers
 func (c *Communication) Broadcast(peers []peer.ID, msg []byte, msgID string) {
 	if len(peers) == 0 {
@@ -117,25 +153,44 @@ func (c *Communication) broadcastToPeers(peers []peer.ID, msg []byte, msgID stri
 	defer func() {
 		c.logger.Debug().Msgf("finished sending message to peer(%v)", peers)
 	}()
+	var msgType string
+	if c.metrics != nil {
+		msgType = wrappedMessageType(msg)
+	}
 	var wgSend sync.WaitGroup
 	wgSend.Add(len(peers))
 	for _, p := range peers {
 		go func(p peer.ID) {
 			defer wgSend.Done()
-			if err := c.writeToStream(p, msg, msgID); nil != err {
+			if err := c.writeToStream(p, msg, msgID); err != nil {
 				c.logger.Error().Err(err).Msg("fail to write to stream")
+				return
 			}
+			c.metrics.UpdateP2PBytes(monitor.DirectionSent, p.String(), msgType, len(msg))
 		}(p)
 	}
 	wgSend.Wait()
 }
 
+// wrappedMessageType returns the type of the wrapped message we send, it is only used to label the metrics
+func wrappedMessageType(msg []byte) string {
+	var header struct {
+		MessageType messages.THORChainTSSMessageType `json:"message_type"`
+	}
+	if err := json.Unmarshal(msg, &header); err != nil {
+		return "unknown"
+	}
+	return header.MessageType.String()
+}
+
 func (c *Communication) writeToStream(pID peer.ID, msg []byte, msgID string) error {
 	// don't send to ourselves
 	if pID == c.dht.Host().ID() {
 		return nil
 	}
+	start := time.Now()
 	stream, err := c.connectToOnePeer(pID)
+	c.metrics.ObservePhase(monitor.PhaseStreamSetup, time.Since(start), err == nil)
 	if err != nil {
 		return fmt.Errorf("fail to open stream to peer(%s): %w", pID, err)
 	}
@@ -173,12 +228,16 @@ func (c *Communication) readFromStream(stream network.Stream) {
 			return
 		}
 		var wrappedMsg messages.WrappedMessage
-		if err := json.Unmarshal(dataBuf, &wrappedMsg); nil != err {
+		start := time.Now()
+		err = json.Unmarshal(dataBuf, &wrappedMsg)
+		c.metrics.ObservePhase(monitor.PhaseJSONFraming, time.Since(start), err == nil)
+		if nil != err {
 			c.logger.Error().Err(err).Msg("fail to unmarshal wrapped message bytes")
 			c.streamMgr.AddStream("UNKNOWN", stream)
 			return
 		}
 		c.logger.Debug().Msgf(">>>>>>>[%s] %s", wrappedMsg.MessageType, string(wrappedMsg.Payload))
+		c.metrics.UpdateP2PBytes(monitor.DirectionReceived, peerID, wrappedMsg.MessageType.String(), len(dataBuf))
 		c.streamMgr.AddStream(wrappedMsg.MsgID, stream)
 		channel := c.getSubscriber(wrappedMsg.MessageType, wrappedMsg.MsgID)
 		if nil == channel {
@@ -186,6 +245,7 @@ func (c *Communication) readFromStream(stream network.Stream) {
 			c.logger.Debug().Msgf("no MsgID %s found for this message", wrappedMsg.MessageType)
 			return
 		}
+		c.recorder.Record(transcript.DirectionIn, []string{peerID}, wrappedMsg)
 		channel <- &Message{
 			PeerID:  stream.Conn().RemotePeer(),
 			Payload: dataBuf,
@@ -248,6 +308,10 @@ func (c *Communication) bootStrapConnectivityCheck() error {
 		c.logger.Info().Msgf("we have successfully ping pong %d nodes", onlineNodes)
 		return nil
 	}
+	if c.enableMDNS {
+		c.logger.Warn().Msg("fail to ping any bootstrap node, we rely on mdns to discover the peers")
+		return nil
+	}
 	c.logger.Error().Msg("fail to ping any bootstrap node")
 	return errors.New("the node cannot ping any bootstrap node")
 }
@@ -324,14 +388,32 @@ func (c *Communication) startChannel(privKeyBytes []byte) error {
 	//	}
 	//}()
 
-	h, err := libp2p.New(
+	opts := []libp2p.Option{
 		libp2p.ListenAddrs([]maddr.Multiaddr{c.listenAddr}...),
 		libp2p.Identity(p2pPriKey),
 		libp2p.AddrsFactory(addressFactory),
 		libp2p.ResourceManager(mgr),
-	)
+	}
+	relayOpts, err := c.relayOptions()
 	if err != nil {
-		return fmt.Errorf("fail to create p2p host: %w", err)
+		return err
+	}
+	opts = append(opts, relayOpts...)
+
+	h := c.host
+	if h != nil {
+		// the given host does not take the libp2p options
+		if c.enableRelay {
+			return errors.New("the relay is not supported by the given host")
+		}
+		if !h.ID().MatchesPrivateKey(p2pPriKey) {
+			return errors.New("the given host does not have the identity of the private key")
+		}
+	} else {
+		h, err = libp2p.New(opts...)
+		if err != nil {
+			return fmt.Errorf("fail to create p2p host: %w", err)
+		}
 	}
 	c.logger.Info().Msgf("Host created, we are: %s, at: %s", h.ID(), h.Addrs())
 	h.SetStreamHandler(TSSProtocolID, c.handleStream)
@@ -349,17 +431,28 @@ func (c *Communication) startChannel(privKeyBytes []byte) error {
 	}
 	c.dht = kademliaDHT
 
+	if c.enableMDNS {
+		if err := c.startMDNS(h); err != nil {
+			return fmt.Errorf("fail to start mdns service: %w", err)
+		}
+		c.logger.Info().Msgf("mdns discovery started with rendezvous %s", c.rendezvous)
+	}
+
 	var connectionErr error
 	for i := 0; i < 5; i++ {
 		connectionErr = c.connectToBootstrapPeers()
-		if connectionErr == nil {
+		// with mdns enabled, the peers in the local network will be found without the bootstrap node
+		if connectionErr == nil || c.enableMDNS {
 			break
 		}
 		c.logger.Error().Msg("cannot connect to any bootstrap node, retry in 5 seconds")
 		time.Sleep(time.Second * 5)
 	}
 	if connectionErr != nil {
-		return fmt.Errorf("fail to connect to bootstrap peer: %w", connectionErr)
+		if !c.enableMDNS {
+			return fmt.Errorf("fail to connect to bootstrap peer: %w", connectionErr)
+		}
+		c.logger.Warn().Err(connectionErr).Msg("fail to connect to bootstrap peer, we rely on mdns to discover the peers")
 	}
 
 	// We use a rendezvous point "meet me here" to announce our location.
@@ -484,6 +577,11 @@ func (c *Communication) Start(priKeyBytes []byte) error {
 // Stop communication
 func (c *Communication) Stop() error {
 	// we need to stop the handler and the p2p services firstly, then terminate the our communication threads
+	if c.mdnsService != nil {
+		if err := c.mdnsService.Close(); err != nil {
+			c.logger.Err(err).Msg("fail to close mdns service")
+		}
+	}
 	if err := c.dht.Host().Close(); err != nil {
 		c.logger.Err(err).Msg("fail to close host network")
 	}
@@ -541,7 +639,9 @@ func (c *Communication) ProcessBroadcast() {
 	for {
 		select {
 		case msg := <-c.BroadcastMsgChan:
+			start := time.Now()
 			wrappedMsgBytes, err := json.Marshal(msg.WrappedMessage)
+			c.metrics.ObservePhase(monitor.PhaseJSONFraming, time.Since(start), err == nil)
 			if err != nil {
 				c.logger.Error().Err(err).Msg("fail to marshal a wrapped message to json bytes")
 				continue
@@ -3,7 +3,11 @@ package p2p
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"os"
+	"time"
+
 	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	maddr "github.com/multiformats/go-multiaddr"
 	. "gopkg.in/check.v1"
@@ -87,3 +91,43 @@ func (CommunicationTestSuite) TestEstablishP2pCommunication(c *C) {
 	ps = comm4.GetHost().Peerstore()
 	c.Assert(checkExist(ps.Addrs(comm.GetHost().ID()), fakeExternalMultiAddr), Equals, true)
 }
+
+// TestMDNSCommunication needs the real multicast on the loopback, which the sandbox and most CI runners do not have,
+// so it runs only if TSS_TEST_MDNS is set
+func (CommunicationTestSuite) TestMDNSCommunication(c *C) {
+	if len(os.Getenv("TSS_TEST_MDNS")) == 0 {
+		c.Skip("set TSS_TEST_MDNS to test the mdns discovery with the real multicast")
+	}
+	sk1, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	c.Assert(err, IsNil)
+	sk1raw, _ := sk1.Raw()
+	sk2, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	c.Assert(err, IsNil)
+	sk2raw, _ := sk2.Raw()
+	id, err := peer.IDFromPrivateKey(sk2)
+	c.Assert(err, IsNil)
+	invalidMultiAddr, err := maddr.NewMultiaddr("/ip4/127.0.0.1/tcp/2229/p2p/" + id.String())
+	c.Assert(err, IsNil)
+
+	// with mdns enabled, we can start even if the bootstrap node is unreachable
+	comm, err := NewCommunication("mdnsTest", []maddr.Multiaddr{invalidMultiAddr}, 2224, "")
+	c.Assert(err, IsNil)
+	comm.EnableMDNS()
+	c.Assert(comm.Start(sk1raw), IsNil)
+	defer comm.Stop()
+	c.Assert(comm.mdnsService, NotNil)
+
+	comm2, err := NewCommunication("mdnsTest", nil, 2225, "")
+	c.Assert(err, IsNil)
+	comm2.EnableMDNS()
+	c.Assert(comm2.Start(sk2raw), IsNil)
+	defer comm2.Stop()
+
+	// the two hosts on this machine find each other with the real mdns queries, the multicast is looped back
+	connected := false
+	for i := 0; i < 100 && !connected; i++ {
+		connected = comm2.GetHost().Network().Connectedness(comm.GetHost().ID()) == network.Connected
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(connected, Equals, true)
+}
@@ -0,0 +1,234 @@
+package p2p
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+const (
+	fuzzMsgID = "fuzz"
+	// maxSeedSize skips the large seeds, the fuzzing engine crawls on them
+	maxSeedSize = 16 * 1024
+)
+
+// fuzzStream is the stream of the remote peer that carries the fuzzed bytes
+type fuzzStream struct {
+	*MockNetworkStream
+	conn network.Conn
+}
+
+func (s fuzzStream) Conn() network.Conn {
+	return s.conn
+}
+
+func (s fuzzStream) Scope() network.StreamScope {
+	return &network.NullScope{}
+}
+
+type fuzzConn struct {
+	network.Conn
+	remote peer.ID
+}
+
+func (c fuzzConn) RemotePeer() peer.ID {
+	return c.remote
+}
+
+// newFuzzStream returns the stream with the data, the data is framed with the length header as the peers send it
+// unless raw is set
+func newFuzzStream(remote peer.ID, data []byte, raw bool) network.Stream {
+	s := NewMockNetworkStream()
+	if !raw {
+		header := make([]byte, LengthHeader)
+		binary.LittleEndian.PutUint32(header, uint32(len(data)))
+		s.Buffer.Write(header)
+	}
+	s.Buffer.Write(data)
+	return fuzzStream{MockNetworkStream: s, conn: fuzzConn{remote: remote}}
+}
+
+func fuzzKey(f *testing.F, seed int64) (crypto.PrivKey, peer.ID) {
+	sk, _, err := crypto.GenerateEd25519Key(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		f.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		f.Fatal(err)
+	}
+	return sk, id
+}
+
+// keygenSharesSeeds returns the wire messages of the keygen shares in test_data that are not larger than maxSeedSize
+func keygenSharesSeeds(f *testing.F) [][]byte {
+	file, err := os.Open(filepath.Join("..", "test_data", "tss_keygen_shares", "shareskeygen0"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer file.Close()
+	var ret [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, MaxPayload), MaxPayload)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > maxSeedSize {
+			continue
+		}
+		ret = append(ret, append([]byte{}, scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		f.Fatal(err)
+	}
+	return ret
+}
+
+func FuzzReadFromStream(f *testing.F) {
+	for _, el := range keygenSharesSeeds(f) {
+		buf, err := json.Marshal(messages.WrappedMessage{
+			MessageType: messages.TSSKeyGenMsg,
+			MsgID:       fuzzMsgID,
+			Payload:     el,
+		})
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf, false)
+	}
+	f.Add([]byte(`{"message_type":5,"message_id":"fuzz","payload":"eyJ0YXNrX2RvbmUiOnRydWV9"}`), false)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff}, true)
+	f.Add([]byte{}, true)
+
+	_, remote := fuzzKey(f, 1)
+	c, err := NewCommunication("fuzz", nil, 0, "")
+	if err != nil {
+		f.Fatal(err)
+	}
+	c.logger = zerolog.Nop()
+	channel := make(chan *Message, 1)
+	for _, el := range []messages.THORChainTSSMessageType{messages.TSSKeyGenMsg, messages.TSSKeyGenVerMsg, messages.TSSTaskDone} {
+		c.SetSubscribe(el, fuzzMsgID, channel)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, raw bool) {
+		c.readFromStream(newFuzzStream(remote, data, raw))
+		select {
+		case msg := <-channel:
+			if msg.PeerID != remote {
+				t.Fatalf("message from %s is delivered as from %s", remote, msg.PeerID)
+			}
+		default:
+		}
+		c.streamMgr.ReleaseStream(fuzzMsgID)
+		c.streamMgr.ReleaseStream("UNKNOWN")
+	})
+}
+
+func FuzzPartyCoordinatorHandleStream(f *testing.F) {
+	buf, err := proto.Marshal(&messages.JoinPartyRequest{ID: fuzzMsgID})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf, false)
+	f.Add([]byte{0x0a, 0xff}, false)
+	f.Add([]byte{0x01}, true)
+
+	_, local := fuzzKey(f, 1)
+	_, remote := fuzzKey(f, 2)
+	pc := &PartyCoordinator{
+		logger:             zerolog.Nop(),
+		peersGroup:         make(map[string]*PeerStatus),
+		joinPartyGroupLock: &sync.RWMutex{},
+		streamMgr:          NewStreamMgr(),
+	}
+	f.Fuzz(func(t *testing.T, data []byte, raw bool) {
+		pc.joinPartyGroupLock.Lock()
+		pc.peersGroup[fuzzMsgID] = NewPeerStatus([]peer.ID{local, remote}, local, "", 1)
+		pc.joinPartyGroupLock.Unlock()
+		pc.HandleStream(newFuzzStream(remote, data, raw))
+		pc.streamMgr.ReleaseStream(fuzzMsgID)
+	})
+}
+
+func FuzzPartyCoordinatorHandleStreamWithLeader(f *testing.F) {
+	sk, remote := fuzzKey(f, 2)
+	_, local := fuzzKey(f, 1)
+	request := &messages.JoinPartyLeaderComm{
+		ID:          fuzzMsgID,
+		MsgType:     "request",
+		BlockHeight: 10,
+	}
+	if err := signJoinPartyMsg(sk, request); err != nil {
+		f.Fatal(err)
+	}
+	for _, el := range []*messages.JoinPartyLeaderComm{request, {ID: fuzzMsgID, MsgType: "request"}, {ID: fuzzMsgID, MsgType: "response"}} {
+		buf, err := proto.Marshal(el)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf, false)
+	}
+	f.Add([]byte{0x12, 0x07}, false)
+
+	pc := &PartyCoordinator{
+		logger:             zerolog.Nop(),
+		peersGroup:         make(map[string]*PeerStatus),
+		joinPartyGroupLock: &sync.RWMutex{},
+		streamMgr:          NewStreamMgr(),
+	}
+	f.Fuzz(func(t *testing.T, data []byte, raw bool) {
+		pc.joinPartyGroupLock.Lock()
+		pc.peersGroup[fuzzMsgID] = NewPeerStatus([]peer.ID{local, remote}, local, local.String(), 1)
+		pc.joinPartyGroupLock.Unlock()
+		pc.HandleStreamWithLeader(newFuzzStream(remote, data, raw))
+		pc.streamMgr.ReleaseStream(fuzzMsgID)
+	})
+}
+
+func FuzzPartyCoordinatorProcessRespMsg(f *testing.F) {
+	sk, leader := fuzzKey(f, 2)
+	_, local := fuzzKey(f, 1)
+	resp := &messages.JoinPartyLeaderComm{
+		ID:      fuzzMsgID,
+		MsgType: "response",
+		Type:    messages.JoinPartyLeaderComm_Success,
+		PeerIDs: []string{local.String(), leader.String()},
+	}
+	if err := signJoinPartyMsg(sk, resp); err != nil {
+		f.Fatal(err)
+	}
+	buf, err := proto.Marshal(resp)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf)
+	f.Add([]byte{0x32, 0x01, 0x00})
+
+	pc := &PartyCoordinator{
+		logger:             zerolog.Nop(),
+		peersGroup:         make(map[string]*PeerStatus),
+		joinPartyGroupLock: &sync.RWMutex{},
+		streamMgr:          NewStreamMgr(),
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg messages.JoinPartyLeaderComm
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return
+		}
+		pc.joinPartyGroupLock.Lock()
+		pc.peersGroup[fuzzMsgID] = NewPeerStatus([]peer.ID{local, leader}, local, leader.String(), 1)
+		pc.joinPartyGroupLock.Unlock()
+		pc.processRespMsg(&msg, newFuzzStream(leader, nil, true))
+	})
+}
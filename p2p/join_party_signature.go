@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+var (
+	ErrInvalidJoinPartySignature = errors.New("invalid join party signature")
+	ErrJoinPartyBlockHeight      = errors.New("join party block height mismatch")
+)
+
+// joinPartyMsgBytes returns the bytes we sign for the join party message, which covers all the fields except
+// the signature itself
+func joinPartyMsgBytes(msg *messages.JoinPartyLeaderComm) ([]byte, error) {
+	unsigned := proto.Clone(msg).(*messages.JoinPartyLeaderComm)
+	unsigned.Signature = nil
+	return proto.MarshalOptions{Deterministic: true}.Marshal(unsigned)
+}
+
+// signJoinPartyMsg signs the join party message with the given node key
+func signJoinPartyMsg(privKey crypto.PrivKey, msg *messages.JoinPartyLeaderComm) error {
+	if privKey == nil {
+		return errors.New("no private key to sign the join party message")
+	}
+	buf, err := joinPartyMsgBytes(msg)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the join party message: %w", err)
+	}
+	sig, err := privKey.Sign(buf)
+	if err != nil {
+		return fmt.Errorf("fail to sign the join party message: %w", err)
+	}
+	msg.Signature = sig
+	return nil
+}
+
+// VerifyJoinPartyMsg checks the join party message is signed by the node key of the given signer, so that
+// the signed leader response can be presented to others as the proof of what the leader has sent
+func VerifyJoinPartyMsg(msg *messages.JoinPartyLeaderComm, signer peer.ID) error {
+	if len(msg.GetSignature()) == 0 {
+		return fmt.Errorf("join party message is not signed: %w", ErrInvalidJoinPartySignature)
+	}
+	pubKey, err := signer.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("fail to extract the public key of %s: %w", signer, err)
+	}
+	buf, err := joinPartyMsgBytes(msg)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the join party message: %w", err)
+	}
+	ok, err := pubKey.Verify(buf, msg.GetSignature())
+	if err != nil {
+		return fmt.Errorf("fail to verify the join party message: %w", err)
+	}
+	if !ok {
+		return ErrInvalidJoinPartySignature
+	}
+	return nil
+}
+
+// verifyJoinPartyMsg checks the join party message of the party against the local request. The unsigned message is
+// accepted only if the party does not require the signature, so that the old peers join the party during the rolling
+// upgrade, while the signed one must be valid and for the block height we are requested.
+func (ps *PeerStatus) verifyJoinPartyMsg(msg *messages.JoinPartyLeaderComm, signer peer.ID) error {
+	if len(msg.GetSignature()) == 0 && !ps.requireSigned {
+		return nil
+	}
+	if err := VerifyJoinPartyMsg(msg, signer); err != nil {
+		return err
+	}
+	if msg.GetBlockHeight() != ps.blockHeight {
+		return fmt.Errorf("block height %d of the message while we have %d: %w", msg.GetBlockHeight(), ps.blockHeight, ErrJoinPartyBlockHeight)
+	}
+	return nil
+}
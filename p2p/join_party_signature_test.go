@@ -0,0 +1,89 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+type JoinPartySignatureTestSuite struct{}
+
+var _ = Suite(&JoinPartySignatureTestSuite{})
+
+func generateTestNodeKey(c *C) (crypto.PrivKey, peer.ID) {
+	sk, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	c.Assert(err, IsNil)
+	id, err := peer.IDFromPrivateKey(sk)
+	c.Assert(err, IsNil)
+	return sk, id
+}
+
+func (s *JoinPartySignatureTestSuite) TestSignAndVerify(c *C) {
+	sk, id := generateTestNodeKey(c)
+	_, otherID := generateTestNodeKey(c)
+	msg := &messages.JoinPartyLeaderComm{
+		ID:          "testMsg",
+		MsgType:     "response",
+		Type:        messages.JoinPartyLeaderComm_Success,
+		PeerIDs:     []string{id.String(), otherID.String()},
+		BlockHeight: 10,
+	}
+	err := VerifyJoinPartyMsg(msg, id)
+	c.Assert(errors.Is(err, ErrInvalidJoinPartySignature), Equals, true)
+
+	c.Assert(signJoinPartyMsg(nil, msg), NotNil)
+	c.Assert(signJoinPartyMsg(sk, msg), IsNil)
+	c.Assert(msg.Signature, NotNil)
+	c.Assert(VerifyJoinPartyMsg(msg, id), IsNil)
+	// the signature is not from the other node
+	c.Assert(VerifyJoinPartyMsg(msg, otherID), Equals, ErrInvalidJoinPartySignature)
+
+	// the leader can not exclude the node without invalidating the signature
+	tampered := &messages.JoinPartyLeaderComm{
+		ID:          msg.ID,
+		MsgType:     msg.MsgType,
+		Type:        msg.Type,
+		PeerIDs:     []string{id.String()},
+		BlockHeight: msg.BlockHeight,
+		Signature:   msg.Signature,
+	}
+	c.Assert(VerifyJoinPartyMsg(tampered, id), Equals, ErrInvalidJoinPartySignature)
+	tampered.PeerIDs = msg.PeerIDs
+	tampered.BlockHeight = 11
+	c.Assert(VerifyJoinPartyMsg(tampered, id), Equals, ErrInvalidJoinPartySignature)
+	tampered.BlockHeight = msg.BlockHeight
+	c.Assert(VerifyJoinPartyMsg(tampered, id), IsNil)
+}
+
+func (s *JoinPartySignatureTestSuite) TestPeerStatusVerify(c *C) {
+	sk, id := generateTestNodeKey(c)
+	ps := NewPeerStatus([]peer.ID{id}, "", id.String(), 1)
+	ps.blockHeight = 10
+	msg := &messages.JoinPartyLeaderComm{
+		ID:          "testMsg",
+		MsgType:     "response",
+		Type:        messages.JoinPartyLeaderComm_Success,
+		PeerIDs:     []string{id.String()},
+		BlockHeight: 10,
+	}
+	// the old peer does not sign the message during the rolling upgrade
+	c.Assert(ps.verifyJoinPartyMsg(msg, id), IsNil)
+	ps.requireSigned = true
+	c.Assert(errors.Is(ps.verifyJoinPartyMsg(msg, id), ErrInvalidJoinPartySignature), Equals, true)
+
+	c.Assert(signJoinPartyMsg(sk, msg), IsNil)
+	c.Assert(ps.verifyJoinPartyMsg(msg, id), IsNil)
+	// the signed message must be valid even if the party does not require it
+	ps.requireSigned = false
+	_, otherID := generateTestNodeKey(c)
+	c.Assert(ps.verifyJoinPartyMsg(msg, otherID), Equals, ErrInvalidJoinPartySignature)
+
+	// the message is signed for another block height
+	ps.blockHeight = 11
+	c.Assert(errors.Is(ps.verifyJoinPartyMsg(msg, id), ErrJoinPartyBlockHeight), Equals, true)
+}
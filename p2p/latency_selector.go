@@ -0,0 +1,282 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	LatencyLeaderSelectorName = "latency"
+
+	// latencyBucket is the granularity in milliseconds we compare the RTTs of the peers, the peers in the same
+	// bucket are ordered by the hash, so that the leader still rotates among the peers that are fast enough
+	latencyBucket = 100
+	// latencyProbeTimeout is the time we wait for the ping to a peer before we regard it as unmeasured
+	latencyProbeTimeout = time.Second
+	// latencyTableDelay is the number of blocks the RTT table is published ahead of the party that uses it, so
+	// that the parties of the committee at the close heights have finished on all the nodes
+	latencyTableDelay = 10
+	// latencyTablesFileName is the file in the base folder the tables are kept in
+	latencyTablesFileName = "latency_tables.json"
+)
+
+// LatencyObserver is implemented by the LeaderSelector that orders the leaders by the RTTs, the coordinator
+// measures and exchanges the RTTs in the join party only for it. The RTTs it observes are the ones the leader
+// publishes in its signed response, so all the members of the party observe the same ones.
+type LatencyObserver interface {
+	ObserveLatencies(msgID string, blockHeight int64, peers []string, rtts map[string]int64)
+}
+
+// latencyTable is the median RTT of each peer published by the leader of the party
+type latencyTable struct {
+	MsgID string           `json:"msg_id"`
+	RTTs  map[string]int64 `json:"rtts"`
+}
+
+// LatencyLeaderSelector prefers the nodes with the lower RTT to lead the party, so that the node that is slow to
+// reach is moved to the end of the order. The RTTs are the median of the ones the members of the party reported
+// to its leader, which the leader publishes in the signed response. For the party at the block height h, all the
+// nodes order by the table of the last party of the committee at or below h-latencyTableDelay. The node that
+// missed that party orders by an older table, and fails over if it picks another leader, as it does when the
+// leader is offline. The tables are kept in the file if it is given, so that the restarted node orders as the
+// others do.
+type LatencyLeaderSelector struct {
+	lock *sync.Mutex
+	// the tables of each committee by the block height of the party
+	tables   map[string]map[int64]latencyTable
+	filePath string
+}
+
+// NewLatencyLeaderSelector create a new instance of LatencyLeaderSelector that keeps the tables in memory only
+func NewLatencyLeaderSelector() *LatencyLeaderSelector {
+	return &LatencyLeaderSelector{
+		lock:   &sync.Mutex{},
+		tables: make(map[string]map[int64]latencyTable),
+	}
+}
+
+// NewPersistentLatencyLeaderSelector create a new instance of LatencyLeaderSelector that loads the tables from the
+// file and saves them to it once it observes a new one
+func NewPersistentLatencyLeaderSelector(filePath string) (*LatencyLeaderSelector, error) {
+	s := NewLatencyLeaderSelector()
+	s.filePath = filePath
+	buf, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("fail to read the latency tables: %w", err)
+	}
+	if err := json.Unmarshal(buf, &s.tables); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the latency tables: %w", err)
+	}
+	return s, nil
+}
+
+func committeeKey(pIDs []string) string {
+	sorted := make([]string, len(pIDs))
+	copy(sorted, pIDs)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// Candidates implement LeaderSelector. Unlike the other selectors, the order does not only depend on the input: the
+// node that has not observed the table the others order by falls back to an older table, or to the hash order if it
+// has none, so it may wait on another leader until the failover, which costs up to a PartyTimeout. The leader choice is therefore not deterministic
+// across the nodes, and the selector is only for the committees whose members rarely miss a party.
+func (s *LatencyLeaderSelector) Candidates(msgID string, blockHeight int64, pIDs []string) ([]string, error) {
+	candidates, err := LeaderCandidates(msgID, blockHeight, pIDs)
+	if err != nil {
+		return nil, err
+	}
+	rtts := s.rtts(pIDs, blockHeight)
+	if rtts == nil {
+		return candidates, nil
+	}
+	buckets := make(map[string]int64, len(candidates))
+	for _, el := range candidates {
+		rtt, ok := rtts[el]
+		if !ok {
+			// the peer that was offline in the last party is regarded as the slowest
+			buckets[el] = math.MaxInt64
+			continue
+		}
+		buckets[el] = rtt / latencyBucket
+	}
+	// the stable sort keeps the hash order for the nodes in the same bucket
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return buckets[candidates[i]] < buckets[candidates[j]]
+	})
+	return candidates, nil
+}
+
+// rtts returns the table the party of the committee at the block height is ordered by, it is nil if we have none
+func (s *LatencyLeaderSelector) rtts(pIDs []string, blockHeight int64) map[string]int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var found int64
+	var rtts map[string]int64
+	for height, table := range s.tables[committeeKey(pIDs)] {
+		if height <= blockHeight-latencyTableDelay && (rtts == nil || height > found) {
+			found = height
+			rtts = table.RTTs
+		}
+	}
+	return rtts
+}
+
+// ObserveLatencies implement LatencyObserver
+func (s *LatencyLeaderSelector) ObserveLatencies(msgID string, blockHeight int64, peers []string, rtts map[string]int64) {
+	if len(rtts) == 0 {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := committeeKey(peers)
+	tables, ok := s.tables[key]
+	if !ok {
+		tables = make(map[int64]latencyTable)
+		s.tables[key] = tables
+	}
+	// the parties at the same height may finish in different order on the nodes, so we keep the same one
+	if existing, ok := tables[blockHeight]; ok && existing.MsgID <= msgID {
+		return
+	}
+	tables[blockHeight] = latencyTable{MsgID: msgID, RTTs: rtts}
+
+	// we keep the tables the parties at the heights from now on may still use
+	var latest int64
+	for height := range tables {
+		if height > latest {
+			latest = height
+		}
+	}
+	var usable int64 = math.MinInt64
+	for height := range tables {
+		if height <= latest-latencyTableDelay && height > usable {
+			usable = height
+		}
+	}
+	for height := range tables {
+		if height < usable {
+			delete(tables, height)
+		}
+	}
+	if err := s.save(); err != nil {
+		log.Error().Err(err).Msg("fail to save the latency tables, the node orders by the older table once restarted")
+	}
+}
+
+// save replaces the file of the tables, it is a no-op if the tables are kept in memory only
+func (s *LatencyLeaderSelector) save() error {
+	if len(s.filePath) == 0 {
+		return nil
+	}
+	buf, err := json.Marshal(s.tables)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the latency tables: %w", err)
+	}
+	tmpFile := s.filePath + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, buf, 0o600); err != nil {
+		return fmt.Errorf("fail to write the latency tables: %w", err)
+	}
+	return os.Rename(tmpFile, s.filePath)
+}
+
+// measureLatencies pings the given peers, and returns the RTTs in milliseconds of the ones that reply in time
+func measureLatencies(h host.Host, peers []peer.ID) map[peer.ID]int64 {
+	rtts := make(map[peer.ID]int64, len(peers))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, el := range peers {
+		if el == h.ID() {
+			continue
+		}
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), latencyProbeTimeout)
+			defer cancel()
+			select {
+			case result := <-ping.Ping(ctx, h, pid):
+				if result.Error != nil {
+					return
+				}
+				// the ping records the RTT in the peer store, we read the EWMA to smooth the measurement
+				lock.Lock()
+				rtts[pid] = h.Peerstore().LatencyEWMA(pid).Milliseconds()
+				lock.Unlock()
+			case <-ctx.Done():
+			}
+		}(el)
+	}
+	wg.Wait()
+	return rtts
+}
+
+// medianLatencies returns the median of the RTTs reported for each of the peers, the lower one of the two in the
+// middle is taken if the number of the reports is even
+func medianLatencies(peers []peer.ID, reports []map[peer.ID]int64) map[peer.ID]int64 {
+	all := make(map[peer.ID][]int64)
+	for _, report := range reports {
+		for _, el := range peers {
+			if rtt, ok := report[el]; ok {
+				all[el] = append(all[el], rtt)
+			}
+		}
+	}
+	medians := make(map[peer.ID]int64, len(all))
+	for pid, rtts := range all {
+		sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+		medians[pid] = rtts[(len(rtts)-1)/2]
+	}
+	return medians
+}
+
+// encodeLatencies puts the RTTs in the order of the peer IDs, so that the signed message is deterministic
+func encodeLatencies(rtts map[peer.ID]int64) ([]string, []int64) {
+	if len(rtts) == 0 {
+		return nil, nil
+	}
+	pIDs := make([]peer.ID, 0, len(rtts))
+	for el := range rtts {
+		pIDs = append(pIDs, el)
+	}
+	sort.Slice(pIDs, func(i, j int) bool { return pIDs[i].String() < pIDs[j].String() })
+	encoded := make([]string, len(pIDs))
+	values := make([]int64, len(pIDs))
+	for i, el := range pIDs {
+		encoded[i] = el.String()
+		values[i] = rtts[el]
+	}
+	return encoded, values
+}
+
+// decodeLatencies returns the RTTs in the message, it is nil if the message has none or they are malformed
+func decodeLatencies(pIDs []string, values []int64) map[peer.ID]int64 {
+	if len(pIDs) == 0 || len(pIDs) != len(values) {
+		return nil
+	}
+	rtts := make(map[peer.ID]int64, len(pIDs))
+	for i, el := range pIDs {
+		pid, err := peer.Decode(el)
+		if err != nil || values[i] < 0 {
+			return nil
+		}
+		rtts[pid] = values[i]
+	}
+	return rtts
+}
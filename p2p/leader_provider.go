@@ -4,14 +4,82 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"sort"
 	"strconv"
 )
 
+const (
+	HashLeaderSelectorName       = "hash"
+	RoundRobinLeaderSelectorName = "roundrobin"
+)
+
+// LeaderSelector decides the order of the nodes to coordinate the join party, the first one is the leader and
+// the next one takes over once the previous leader is not reachable. All the nodes must get the same order
+// with the same input.
+type LeaderSelector interface {
+	Candidates(msgID string, blockHeight int64, pIDs []string) ([]string, error)
+}
+
+// NewLeaderSelector create the leader selector with the given name, empty name gives the hash selector. The selector
+// that keeps the state saves it in the folder, or in memory only if the folder is empty
+func NewLeaderSelector(name, folder string) (LeaderSelector, error) {
+	switch name {
+	case "", HashLeaderSelectorName:
+		return &HashLeaderSelector{}, nil
+	case RoundRobinLeaderSelectorName:
+		return &RoundRobinLeaderSelector{}, nil
+	case LatencyLeaderSelectorName:
+		if len(folder) == 0 {
+			return NewLatencyLeaderSelector(), nil
+		}
+		return NewPersistentLatencyLeaderSelector(filepath.Join(folder, latencyTablesFileName))
+	default:
+		return nil, fmt.Errorf("unknown leader selector %s", name)
+	}
+}
+
+// HashLeaderSelector orders the nodes by sha256(msgID+height+peerID)
+type HashLeaderSelector struct{}
+
+// Candidates implement LeaderSelector
+func (s *HashLeaderSelector) Candidates(msgID string, blockHeight int64, pIDs []string) ([]string, error) {
+	return LeaderCandidates(msgID, blockHeight, pIDs)
+}
+
+// RoundRobinLeaderSelector moves the leader to the next node in the sorted peer list for every block height
+type RoundRobinLeaderSelector struct{}
+
+// Candidates implement LeaderSelector
+func (s *RoundRobinLeaderSelector) Candidates(msgID string, blockHeight int64, pIDs []string) ([]string, error) {
+	if len(pIDs) == 0 || len(msgID) == 0 || blockHeight == 0 {
+		return nil, errors.New("invalid input for finding the leader")
+	}
+	sorted := make([]string, len(pIDs))
+	copy(sorted, pIDs)
+	sort.Strings(sorted)
+	start := int(blockHeight % int64(len(sorted)))
+	if start < 0 {
+		start += len(sorted)
+	}
+	return append(sorted[start:], sorted[:start]...), nil
+}
+
 // LeaderNode use the given input buf to calculate a hash , and consistently choose a node as a master coordinate note
 func LeaderNode(msgID string, blockHeight int64, pIDs []string) (string, error) {
+	candidates, err := LeaderCandidates(msgID, blockHeight, pIDs)
+	if err != nil {
+		return "", err
+	}
+	return candidates[0], nil
+}
+
+// LeaderCandidates returns all the nodes in the order they take over as the leader, the first one is
+// the node returned by LeaderNode, and the next one takes over once the previous leader is not reachable
+func LeaderCandidates(msgID string, blockHeight int64, pIDs []string) ([]string, error) {
 	if len(pIDs) == 0 || len(msgID) == 0 || blockHeight == 0 {
-		return "", errors.New("invalid input for finding the leader")
+		return nil, errors.New("invalid input for finding the leader")
 	}
 	keyStore := make(map[string]string)
 	hashes := make([]string, len(pIDs))
@@ -22,5 +90,9 @@ func LeaderNode(msgID string, blockHeight int64, pIDs []string) (string, error)
 		hashes[i] = encodedSum
 	}
 	sort.Strings(hashes)
-	return keyStore[hashes[0]], nil
+	candidates := make([]string, len(hashes))
+	for i, el := range hashes {
+		candidates[i] = keyStore[el]
+	}
+	return candidates, nil
 }
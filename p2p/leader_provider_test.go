@@ -1,6 +1,8 @@
 package p2p
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	. "gopkg.in/check.v1"
@@ -21,3 +23,167 @@ func (t *LeaderProviderTestSuite) TestLeaderNode(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(ret, Equals, testPeers[1])
 }
+
+func (t *LeaderProviderTestSuite) TestLeaderCandidates(c *C) {
+	testPeers := []string{
+		"16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp", "16Uiu2HAm4TmEzUqy3q3Dv7HvdoSboHk5sFj2FH3npiN5vDbJC6gh",
+		"16Uiu2HAm2FzqoUdS6Y9Esg2EaGcAG5rVe1r6BFNnmmQr2H3bqafa",
+	}
+	ret, err := LeaderCandidates("HelloWorld", 10, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, HasLen, len(testPeers))
+	c.Assert(ret[0], Equals, testPeers[1])
+	c.Assert(ret, DeepEquals, []string{testPeers[1], testPeers[0], testPeers[2]})
+
+	// the order should not depend on the order of the given peers
+	reversed := []string{testPeers[2], testPeers[1], testPeers[0]}
+	ret2, err := LeaderCandidates("HelloWorld", 10, reversed)
+	c.Assert(err, IsNil)
+	c.Assert(ret2, DeepEquals, ret)
+
+	_, err = LeaderCandidates("", 10, testPeers)
+	c.Assert(err, NotNil)
+}
+
+func (t *LeaderProviderTestSuite) TestNewLeaderSelector(c *C) {
+	selector, err := NewLeaderSelector("", "")
+	c.Assert(err, IsNil)
+	c.Assert(selector, FitsTypeOf, &HashLeaderSelector{})
+	selector, err = NewLeaderSelector(RoundRobinLeaderSelectorName, "")
+	c.Assert(err, IsNil)
+	c.Assert(selector, FitsTypeOf, &RoundRobinLeaderSelector{})
+	selector, err = NewLeaderSelector(LatencyLeaderSelectorName, "")
+	c.Assert(err, IsNil)
+	c.Assert(selector, FitsTypeOf, &LatencyLeaderSelector{})
+	_, err = NewLeaderSelector("whatever", "")
+	c.Assert(err, NotNil)
+}
+
+func (t *LeaderProviderTestSuite) TestHashLeaderSelector(c *C) {
+	testPeers := []string{
+		"16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp", "16Uiu2HAm4TmEzUqy3q3Dv7HvdoSboHk5sFj2FH3npiN5vDbJC6gh",
+		"16Uiu2HAm2FzqoUdS6Y9Esg2EaGcAG5rVe1r6BFNnmmQr2H3bqafa",
+	}
+	selector := &HashLeaderSelector{}
+	ret, err := selector.Candidates("HelloWorld", 10, testPeers)
+	c.Assert(err, IsNil)
+	expected, err := LeaderCandidates("HelloWorld", 10, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, expected)
+}
+
+func (t *LeaderProviderTestSuite) TestRoundRobinLeaderSelector(c *C) {
+	testPeers := []string{
+		"16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp", "16Uiu2HAm4TmEzUqy3q3Dv7HvdoSboHk5sFj2FH3npiN5vDbJC6gh",
+		"16Uiu2HAm2FzqoUdS6Y9Esg2EaGcAG5rVe1r6BFNnmmQr2H3bqafa",
+	}
+	selector := &RoundRobinLeaderSelector{}
+	ret, err := selector.Candidates("HelloWorld", 10, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, []string{testPeers[1], testPeers[0], testPeers[2]})
+	ret, err = selector.Candidates("HelloWorld", 11, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, []string{testPeers[0], testPeers[2], testPeers[1]})
+	// the leader does not depend on the msgID
+	ret2, err := selector.Candidates("AnotherMsg", 11, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret2, DeepEquals, ret)
+	// we should not change the given peers
+	c.Assert(testPeers[0], Equals, "16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp")
+
+	_, err = selector.Candidates("HelloWorld", 0, testPeers)
+	c.Assert(err, NotNil)
+}
+
+func (t *LeaderProviderTestSuite) TestLatencyLeaderSelector(c *C) {
+	testPeers := []string{
+		"16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp", "16Uiu2HAm4TmEzUqy3q3Dv7HvdoSboHk5sFj2FH3npiN5vDbJC6gh",
+		"16Uiu2HAm2FzqoUdS6Y9Esg2EaGcAG5rVe1r6BFNnmmQr2H3bqafa",
+	}
+	hashOrder, err := LeaderCandidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	selector := NewLatencyLeaderSelector()
+	// without the table we order by the hash
+	ret, err := selector.Candidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, hashOrder)
+
+	// the hash leader is slow, and the last one is missing in the table
+	selector.ObserveLatencies("msgB", 20, testPeers, map[string]int64{hashOrder[0]: 350, hashOrder[1]: 20})
+	ret, err = selector.Candidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, []string{hashOrder[1], hashOrder[0], hashOrder[2]})
+	// the table is not used until all the parties at its height are done
+	ret, err = selector.Candidates("HelloWorld", 29, testPeers)
+	c.Assert(err, IsNil)
+	expected, err := LeaderCandidates("HelloWorld", 29, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, expected)
+	// the table is per committee
+	ret, err = selector.Candidates("HelloWorld", 30, testPeers[:2])
+	c.Assert(err, IsNil)
+	expected, err = LeaderCandidates("HelloWorld", 30, testPeers[:2])
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, expected)
+
+	// the peers in the same bucket keep the hash order, and of the parties at the same height the one with the
+	// lower msgID wins whichever finishes first
+	selector.ObserveLatencies("msgC", 20, testPeers, map[string]int64{hashOrder[0]: 10, hashOrder[1]: 90, hashOrder[2]: 500})
+	selector.ObserveLatencies("msgA", 20, testPeers, map[string]int64{hashOrder[0]: 10, hashOrder[1]: 90, hashOrder[2]: 30})
+	ret, err = selector.Candidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, hashOrder)
+
+	// the newer table takes over, and the tables no party can use any more are dropped
+	selector.ObserveLatencies("msgD", 25, testPeers, map[string]int64{hashOrder[0]: 10, hashOrder[1]: 10, hashOrder[2]: 10})
+	selector.ObserveLatencies("msgE", 40, testPeers, map[string]int64{hashOrder[0]: 900, hashOrder[1]: 10, hashOrder[2]: 10})
+	c.Assert(selector.tables[committeeKey(testPeers)], HasLen, 2)
+	ret, err = selector.Candidates("HelloWorld", 35, testPeers)
+	c.Assert(err, IsNil)
+	expected, err = LeaderCandidates("HelloWorld", 35, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, expected)
+	ret, err = selector.Candidates("HelloWorld", 50, testPeers)
+	c.Assert(err, IsNil)
+	expected, err = LeaderCandidates("HelloWorld", 50, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret[2], Equals, hashOrder[0])
+	c.Assert(ret[:2], DeepEquals, without(expected, hashOrder[0]))
+}
+
+func (t *LeaderProviderTestSuite) TestPersistentLatencyLeaderSelector(c *C) {
+	testPeers := []string{
+		"16Uiu2HAmACG5DtqmQsHtXg4G2sLS65ttv84e7MrL4kapkjfmhxAp", "16Uiu2HAm4TmEzUqy3q3Dv7HvdoSboHk5sFj2FH3npiN5vDbJC6gh",
+		"16Uiu2HAm2FzqoUdS6Y9Esg2EaGcAG5rVe1r6BFNnmmQr2H3bqafa",
+	}
+	folder := c.MkDir()
+	selector, err := NewLeaderSelector(LatencyLeaderSelectorName, folder)
+	c.Assert(err, IsNil)
+	hashOrder, err := LeaderCandidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	selector.(LatencyObserver).ObserveLatencies("msgA", 20, testPeers, map[string]int64{hashOrder[0]: 350, hashOrder[1]: 20, hashOrder[2]: 20})
+	expected, err := selector.Candidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(expected, Not(DeepEquals), hashOrder)
+
+	// the restarted node orders by the same table
+	restarted, err := NewLeaderSelector(LatencyLeaderSelectorName, folder)
+	c.Assert(err, IsNil)
+	ret, err := restarted.Candidates("HelloWorld", 30, testPeers)
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, expected)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(folder, latencyTablesFileName), []byte("whatever"), 0o600), IsNil)
+	_, err = NewLeaderSelector(LatencyLeaderSelectorName, folder)
+	c.Assert(err, NotNil)
+}
+
+func without(peers []string, removed string) []string {
+	var ret []string
+	for _, el := range peers {
+		if el != removed {
+			ret = append(ret, el)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,49 @@
+package p2p
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	"github.com/rs/zerolog"
+)
+
+// mdnsNotifee connects to the peers found by the mdns service in the local network
+type mdnsNotifee struct {
+	host   host.Host
+	logger zerolog.Logger
+}
+
+// HandlePeerFound is called by the mdns service once a peer with the same rendezvous is found
+func (n *mdnsNotifee) HandlePeerFound(pi peer.AddrInfo) {
+	if pi.ID == n.host.ID() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), TimeoutConnecting)
+	defer cancel()
+	if err := n.host.Connect(ctx, pi); err != nil {
+		n.logger.Error().Err(err).Msgf("fail to connect to the mdns peer %s", pi.ID)
+		return
+	}
+	n.logger.Info().Msgf("connection established with mdns peer: %s", pi.ID)
+}
+
+// EnableMDNS let the communication find the peers with the same rendezvous in the local network,
+// so that the nodes can form the network without the bootstrap peers. It must be called before Start.
+func (c *Communication) EnableMDNS() {
+	c.enableMDNS = true
+}
+
+func (c *Communication) startMDNS(h host.Host) error {
+	notifee := &mdnsNotifee{
+		host:   h,
+		logger: c.logger.With().Str("discovery", "mdns").Logger(),
+	}
+	service := mdns.NewMdnsService(h, c.rendezvous, notifee)
+	if err := service.Start(); err != nil {
+		return err
+	}
+	c.mdnsService = service
+	return nil
+}
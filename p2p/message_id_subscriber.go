@@ -1,6 +1,7 @@
 package p2p
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -63,3 +64,15 @@ func (ms *MessageIDSubscriber) IsEmpty() bool {
 	defer ms.lock.Unlock()
 	return len(ms.subscribers) == 0
 }
+
+// MsgIDs returns the message ids subscribed, in order
+func (ms *MessageIDSubscriber) MsgIDs() []string {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	msgIDs := make([]string, 0, len(ms.subscribers))
+	for msgID := range ms.subscribers {
+		msgIDs = append(msgIDs, msgID)
+	}
+	sort.Strings(msgIDs)
+	return msgIDs
+}
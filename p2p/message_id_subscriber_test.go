@@ -16,6 +16,9 @@ func (MessageIDSubscriberTestSuit) TestMessageIDSubscriber(c *C) {
 	channel1 := ms.GetSubscriber("hello")
 	c.Assert(channel1, NotNil)
 	c.Assert(ms.IsEmpty(), Equals, false)
+	ms.Subscribe("hello0", make(chan *Message))
+	c.Assert(ms.MsgIDs(), DeepEquals, []string{"hello", "hello0"})
+	ms.UnSubscribe("hello0")
 	ms.UnSubscribe("hello")
 	channel2 := ms.GetSubscriber("hello")
 	c.Assert(channel2, IsNil)
@@ -36,6 +36,8 @@ type PartyCoordinator struct {
 	joinPartyGroupLock *sync.RWMutex
 	streamMgr          *StreamMgr
 	wg                 *sync.WaitGroup
+	leaderSelector     LeaderSelector
+	unreliablePeers    func() []peer.ID
 }
 
 // NewPartyCoordinator create a new instance of PartyCoordinator
@@ -53,12 +55,25 @@ func NewPartyCoordinator(host host.Host, timeout time.Duration) *PartyCoordinato
 		joinPartyGroupLock: &sync.RWMutex{},
 		streamMgr:          NewStreamMgr(),
 		wg:                 &sync.WaitGroup{},
+		leaderSelector:     &HashLeaderSelector{},
 	}
 	host.SetStreamHandler(joinPartyProtocol, pc.HandleStream)
 	host.SetStreamHandler(joinPartyProtocolWithLeader, pc.HandleStreamWithLeader)
 	return pc
 }
 
+// SetLeaderSelector set the strategy to choose the leader of the join party, all the nodes in the party
+// must use the same strategy
+func (pc *PartyCoordinator) SetLeaderSelector(selector LeaderSelector) {
+	pc.leaderSelector = selector
+}
+
+// SetUnreliablePeers set the function that tells the chronically failing peers, as the leader, we put them
+// on standby and form the party with the others if they are enough
+func (pc *PartyCoordinator) SetUnreliablePeers(unreliablePeers func() []peer.ID) {
+	pc.unreliablePeers = unreliablePeers
+}
+
 // Stop the PartyCoordinator rune
 func (pc *PartyCoordinator) Stop() {
 	defer pc.logger.Info().Msg("stop party coordinator")
@@ -83,6 +98,10 @@ func (pc *PartyCoordinator) processRespMsg(respMsg *messages.JoinPartyLeaderComm
 		pc.logger.Info().Msgf("message ID from peer(%s) can not be found", remotePeer)
 		return
 	}
+	if err := peerGroup.verifyJoinPartyMsg(respMsg, stream.Conn().RemotePeer()); err != nil {
+		pc.logger.Error().Err(err).Msgf("fail to verify the response from peer(%s)", remotePeer)
+		return
+	}
 	peerGroup.leaderSetLock.RLock()
 	leader := peerGroup.leader
 	peerGroup.leaderSetLock.RUnlock()
@@ -104,6 +123,12 @@ func (pc *PartyCoordinator) processReqMsg(requestMsg *messages.JoinPartyLeaderCo
 		return errors.New("party not ready")
 	}
 	remotePeer := stream.Conn().RemotePeer()
+	if err := peerGroup.verifyJoinPartyMsg(requestMsg, remotePeer); err != nil {
+		pc.logger.Error().Err(err).Msgf("fail to verify the request from peer(%s)", remotePeer)
+		return err
+	}
+	peerGroup.setPresignOffer(remotePeer, requestMsg.GetPresignIDs())
+	peerGroup.setLatencyReport(remotePeer, decodeLatencies(requestMsg.GetLatencyPeerIDs(), requestMsg.GetLatencyRTTs()))
 	partyFormed, err := peerGroup.updatePeer(remotePeer, stream)
 	if err != nil {
 		pc.logger.Error().Err(err).Msg("receive msg from unknown peer")
@@ -226,7 +251,7 @@ func (pc *PartyCoordinator) RemoveJoinPartyGroups(messageID string) {
 
 }
 
-func (pc *PartyCoordinator) createJoinPartyGroups(messageID, leader string, peers []string, threshold int) (*PeerStatus, error) {
+func (pc *PartyCoordinator) createJoinPartyGroups(messageID, leader string, peers []string, threshold int, blockHeight int64, requireSigned bool) (*PeerStatus, error) {
 	pIDs, err := pc.getPeerIDs(peers)
 	if err != nil {
 		pc.logger.Error().Err(err).Msg("fail to parse peer id")
@@ -235,6 +260,8 @@ func (pc *PartyCoordinator) createJoinPartyGroups(messageID, leader string, peer
 	pc.joinPartyGroupLock.Lock()
 	defer pc.joinPartyGroupLock.Unlock()
 	peerStatus := NewPeerStatus(pIDs, pc.host.ID(), leader, threshold)
+	peerStatus.blockHeight = blockHeight
+	peerStatus.requireSigned = requireSigned
 	pc.peersGroup[messageID] = peerStatus
 	return peerStatus, nil
 }
@@ -253,6 +280,10 @@ func (pc *PartyCoordinator) getPeerIDs(ids []string) ([]peer.ID, error) {
 
 func (pc *PartyCoordinator) sendResponseToAll(msg *messages.JoinPartyLeaderComm, peers []peer.ID, p *sync.Map) {
 	msg.MsgType = "response"
+	if err := signJoinPartyMsg(pc.host.Peerstore().PrivKey(pc.host.ID()), msg); err != nil {
+		pc.logger.Error().Err(err).Msg("fail to sign the response")
+		return
+	}
 	msgSend, err := proto.Marshal(msg)
 	if err != nil {
 		pc.logger.Error().Msg("fail to marshal the message")
@@ -293,6 +324,10 @@ func (pc *PartyCoordinator) sendResponseToAll(msg *messages.JoinPartyLeaderComm,
 
 func (pc *PartyCoordinator) sendRequestToLeader(msg *messages.JoinPartyLeaderComm, leader peer.ID) (bool, error) {
 	msg.MsgType = "request"
+	if err := signJoinPartyMsg(pc.host.Peerstore().PrivKey(pc.host.ID()), msg); err != nil {
+		pc.logger.Error().Err(err).Msg("fail to sign the request")
+		return true, err
+	}
 	msgSend, err := proto.Marshal(msg)
 	if err != nil {
 		pc.logger.Error().Msg("fail to marshal the message")
@@ -437,8 +472,8 @@ func (pc *PartyCoordinator) sendMsgToPeer(msgBuf []byte, msgID string, remotePee
 	return "", nil
 }
 
-func (pc *PartyCoordinator) joinPartyMember(msgID string, leader string, threshold int, sigChan chan string) ([]peer.ID, error) {
-	peerGroup, err := pc.createJoinPartyGroups(msgID, leader, []string{leader}, threshold)
+func (pc *PartyCoordinator) joinPartyMember(msgID string, blockHeight int64, leader string, peers []string, threshold int, requireSigned bool, presign *PresignAgreement, sigChan chan string) ([]peer.ID, error) {
+	peerGroup, err := pc.createJoinPartyGroups(msgID, leader, []string{leader}, threshold, blockHeight, requireSigned)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create join party:%w", err)
 	}
@@ -451,14 +486,18 @@ func (pc *PartyCoordinator) joinPartyMember(msgID string, leader string, thresho
 	peerGroup.leader = leader
 	peerGroup.leaderSetLock.Unlock()
 	msg := messages.JoinPartyLeaderComm{
-		ID: msgID,
+		ID:          msgID,
+		BlockHeight: blockHeight,
+		PresignIDs:  presign.offer(),
 	}
+	latencies := pc.measureLatencies(peers)
 
 	rand.Seed(time.Now().UnixNano())
 	min := 300
 	max := 2000
 	randDelay := rand.Intn(max-min+1) + min
 	time.Sleep(time.Millisecond * time.Duration(randDelay))
+	msg.LatencyPeerIDs, msg.LatencyRTTs = encodeLatencies(<-latencies)
 	var wg sync.WaitGroup
 	done := make(chan struct{})
 	wg.Add(1)
@@ -531,14 +570,16 @@ func (pc *PartyCoordinator) joinPartyMember(msgID string, leader string, thresho
 	}
 
 	if leaderResp.Type == messages.JoinPartyLeaderComm_Success {
+		presign.setPicked(leaderResp.GetPresignIDs())
+		pc.observeLatencies(msgID, blockHeight, peers, decodeLatencies(leaderResp.GetLatencyPeerIDs(), leaderResp.GetLatencyRTTs()))
 		return pIDs, nil
 	}
 	pc.logger.Error().Msg("leader response with join party timeout")
 	return pIDs, ErrJoinPartyTimeout
 }
 
-func (pc *PartyCoordinator) joinPartyLeader(msgID string, peers []string, threshold int, sigChan chan string) ([]peer.ID, error) {
-	peerGroup, err := pc.createJoinPartyGroups(msgID, pc.host.ID().String(), peers, threshold)
+func (pc *PartyCoordinator) joinPartyLeader(msgID string, blockHeight int64, peers []string, threshold int, requireSigned bool, presign *PresignAgreement, sigChan chan string) ([]peer.ID, error) {
+	peerGroup, err := pc.createJoinPartyGroups(msgID, pc.host.ID().String(), peers, threshold, blockHeight, requireSigned)
 	if err != nil {
 		pc.logger.Error().Err(err).Msg("fail to create the join party group")
 		return nil, err
@@ -546,19 +587,30 @@ func (pc *PartyCoordinator) joinPartyLeader(msgID string, peers []string, thresh
 	peerGroup.peerStatusLock.Lock()
 	peerGroup.leader = pc.host.ID().String()
 	peerGroup.peerStatusLock.Unlock()
+	standbyTimeout := pc.deprioritizePeers(peerGroup)
+	latencies := pc.measureLatencies(peers)
 
 	var sigNotify string
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		// the standby peers must not extend the time we wait for the party
+		timeout := time.After(pc.timeout)
 		for {
 			select {
 			case <-peerGroup.notify:
 				pc.logger.Debug().Msg("we have enough participants")
 				return
 
-			case <-time.After(pc.timeout):
+			case <-standbyTimeout:
+				standbyTimeout = nil
+				if peerGroup.admitStandbyPeers() {
+					pc.logger.Debug().Msg("we have enough participants with the standby peers")
+					return
+				}
+
+			case <-timeout:
 				// timeout
 				pc.logger.Error().Msg("leader waits for peers timeout")
 				return
@@ -581,9 +633,10 @@ func (pc *PartyCoordinator) joinPartyLeader(msgID string, peers []string, thresh
 	}
 
 	msg := messages.JoinPartyLeaderComm{
-		ID:      msgID,
-		Type:    messages.JoinPartyLeaderComm_Success,
-		PeerIDs: tssNodes,
+		ID:          msgID,
+		Type:        messages.JoinPartyLeaderComm_Success,
+		PeerIDs:     tssNodes,
+		BlockHeight: blockHeight,
 	}
 	// we put ourselves(leader) in the online list, so need threshold +1
 	if len(onlinePeers) < threshold+1 {
@@ -592,24 +645,131 @@ func (pc *PartyCoordinator) joinPartyLeader(msgID string, peers []string, thresh
 		pc.sendResponseToAll(&msg, nil, peerGroup.streams)
 		return onlinePeers, ErrJoinPartyTimeout
 	}
+	offers := peerGroup.getPresignOffers()
+	offers[pc.host.ID()] = presign.offer()
+	msg.PresignIDs = presign.pick(onlinePeers, offers)
+	presign.setPicked(msg.PresignIDs)
+	var rtts map[peer.ID]int64
+	if own := <-latencies; own != nil {
+		committee, err := pc.getPeerIDs(peers)
+		if err != nil {
+			return nil, err
+		}
+		rtts = medianLatencies(committee, append(peerGroup.getLatencyReports(onlinePeers), own))
+		msg.LatencyPeerIDs, msg.LatencyRTTs = encodeLatencies(rtts)
+	}
 	// we notify all the peers who to run keygen/keysign
 	// if a nodes is not in the list, it means he is not selected by the leader to run the tss
 	pc.sendResponseToAll(&msg, nil, peerGroup.streams)
+	pc.observeLatencies(msgID, blockHeight, peers, rtts)
 	return onlinePeers, nil
 }
 
-func (pc *PartyCoordinator) JoinPartyWithLeader(msgID string, blockHeight int64, peers []string, threshold int, signChan chan string) ([]peer.ID, string, error) {
-	leader, err := LeaderNode(msgID, blockHeight, peers)
-	if err != nil {
-		return nil, "", err
+// measureLatencies measures the RTTs to the peers in the background if the leader selector orders the leaders by
+// them, the returned channel gives nil otherwise
+func (pc *PartyCoordinator) measureLatencies(peers []string) <-chan map[peer.ID]int64 {
+	ret := make(chan map[peer.ID]int64, 1)
+	if _, ok := pc.leaderSelector.(LatencyObserver); !ok {
+		ret <- nil
+		return ret
 	}
-	if pc.host.ID().String() == leader {
-		onlines, err := pc.joinPartyLeader(msgID, peers, threshold, signChan)
-		return onlines, leader, err
+	go func() {
+		pIDs, err := pc.getPeerIDs(peers)
+		if err != nil {
+			pc.logger.Error().Err(err).Msg("fail to parse the peers to measure the latency")
+			ret <- map[peer.ID]int64{}
+			return
+		}
+		ret <- measureLatencies(pc.host, pIDs)
+	}()
+	return ret
+}
+
+// observeLatencies passes the RTTs the leader published to the leader selector that orders the leaders by them
+func (pc *PartyCoordinator) observeLatencies(msgID string, blockHeight int64, peers []string, rtts map[peer.ID]int64) {
+	observer, ok := pc.leaderSelector.(LatencyObserver)
+	if !ok || len(rtts) == 0 {
+		return
+	}
+	encoded := make(map[string]int64, len(rtts))
+	for el, rtt := range rtts {
+		encoded[el.String()] = rtt
+	}
+	observer.ObserveLatencies(msgID, blockHeight, peers, encoded)
+}
+
+// deprioritizePeers puts the unreliable peers on standby if the others are enough to form the party, the
+// returned channel fires when we give up waiting for the others
+func (pc *PartyCoordinator) deprioritizePeers(peerGroup *PeerStatus) <-chan time.Time {
+	if pc.unreliablePeers == nil {
+		return nil
+	}
+	unreliable := pc.unreliablePeers()
+	if len(unreliable) == 0 {
+		return nil
+	}
+	isUnreliable := make(map[peer.ID]bool, len(unreliable))
+	for _, el := range unreliable {
+		isUnreliable[el] = true
+	}
+	peerGroup.peerStatusLock.Lock()
+	reliable := 0
+	for el := range peerGroup.peersResponse {
+		if !isUnreliable[el] {
+			reliable++
+		}
+	}
+	peerGroup.peerStatusLock.Unlock()
+	if reliable < peerGroup.threshold {
+		return nil
+	}
+	pc.logger.Info().Msgf("we put the unreliable peers %v on standby", unreliable)
+	peerGroup.setStandbyPeers(unreliable)
+	return time.After(pc.timeout / 2)
+}
+
+// LeaderAttempts is the number of the leaders the join party tries among the candidates, each attempt takes up to
+// the party timeout. The party needs threshold+1 nodes to be online, so it is meaningless to try more leaders than
+// the number of nodes that can be offline
+func LeaderAttempts(candidates, threshold int) int {
+	attempts := candidates - threshold
+	if attempts > candidates {
+		attempts = candidates
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	return attempts
+}
+
+// JoinPartyWithLeader join the party coordinated by the leader chosen from the peers. If the leader is not reachable,
+// we fail over to the next candidate given by the leader selector, it returns the online peers, the leader that
+// coordinated the last attempt and the leaders that we failed over from. The join party messages are always signed,
+// while the unsigned ones from the old peers are rejected only if requireSigned is set. If presign is given, the
+// party agrees on the presignatures it signs with as well.
+func (pc *PartyCoordinator) JoinPartyWithLeader(msgID string, blockHeight int64, peers []string, threshold int, requireSigned bool, presign *PresignAgreement, signChan chan string) ([]peer.ID, string, []string, error) {
+	candidates, err := pc.leaderSelector.Candidates(msgID, blockHeight, peers)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	attempts := LeaderAttempts(len(candidates), threshold)
+	var failedLeaders []string
+	for _, leader := range candidates[:attempts] {
+		var onlines []peer.ID
+		presign.setPicked(nil)
+		if pc.host.ID().String() == leader {
+			onlines, err = pc.joinPartyLeader(msgID, blockHeight, peers, threshold, requireSigned, presign, signChan)
+		} else {
+			// now we are just the normal peer
+			onlines, err = pc.joinPartyMember(msgID, blockHeight, leader, peers, threshold, requireSigned, presign, signChan)
+		}
+		if !errors.Is(err, ErrLeaderNotReady) {
+			return onlines, leader, failedLeaders, err
+		}
+		failedLeaders = append(failedLeaders, leader)
+		pc.logger.Warn().Msgf("leader(%s) of msgID(%s) is not reachable, fail over to the next leader", leader, msgID)
 	}
-	// now we are just the normal peer
-	onlines, err := pc.joinPartyMember(msgID, leader, threshold, signChan)
-	return onlines, leader, err
+	return nil, failedLeaders[len(failedLeaders)-1], failedLeaders, ErrLeaderNotReady
 }
 
 // JoinPartyWithRetry this method provide the functionality to join party with retry and back off
@@ -623,7 +783,7 @@ func (pc *PartyCoordinator) JoinPartyWithRetry(msgID string, peers []string) ([]
 		return nil, err
 	}
 
-	peerGroup, err := pc.createJoinPartyGroups(msg.ID, "NONE", peers, 1)
+	peerGroup, err := pc.createJoinPartyGroups(msg.ID, "NONE", peers, 1, 0, false)
 	if err != nil {
 		pc.logger.Error().Err(err).Msg("fail to create the join party group")
 		return nil, err
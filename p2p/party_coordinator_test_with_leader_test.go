@@ -9,7 +9,9 @@ import (
 
 	tnet "github.com/libp2p/go-libp2p-testing/net"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/joltify-finance/tss/conversion"
@@ -20,7 +22,10 @@ func init() {
 }
 
 func setupHosts(t *testing.T, n int) []host.Host {
-	mn := mocknet.New()
+	return setupHostsOnMocknet(t, mocknet.New(), n)
+}
+
+func setupHostsOnMocknet(t *testing.T, mn mocknet.Mocknet, n int) []host.Host {
 	var hosts []host.Host
 	for i := 0; i < n; i++ {
 
@@ -52,7 +57,7 @@ func leaderAppearsLastTest(t *testing.T, msgID string, peers []string, pcs []*Pa
 			// we simulate different nodes join at different time
 			time.Sleep(time.Millisecond * time.Duration(rand.Int()%100))
 			sigChan := make(chan string)
-			onlinePeers, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+			onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 			assert.Nil(t, err)
 			assert.Len(t, onlinePeers, 4)
 		}(el)
@@ -65,7 +70,7 @@ func leaderAppearsLastTest(t *testing.T, msgID string, peers []string, pcs []*Pa
 		defer wg.Done()
 		sigChan := make(chan string)
 		// we simulate different nodes join at different time
-		onlinePeers, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+		onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 		assert.Nil(t, err)
 		assert.Len(t, onlinePeers, 4)
 	}(pcs[0])
@@ -80,7 +85,7 @@ func leaderAppersFirstTest(t *testing.T, msgID string, peers []string, pcs []*Pa
 		defer wg.Done()
 		// we simulate different nodes join at different time
 		sigChan := make(chan string)
-		onlinePeers, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+		onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 		assert.Nil(t, err)
 		assert.Len(t, onlinePeers, 4)
 	}(pcs[0])
@@ -92,7 +97,7 @@ func leaderAppersFirstTest(t *testing.T, msgID string, peers []string, pcs []*Pa
 			// we simulate different nodes join at different time
 			time.Sleep(time.Millisecond * time.Duration(rand.Int()%100))
 			sigChan := make(chan string)
-			onlinePeers, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+			onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 			assert.Nil(t, err)
 			assert.Len(t, onlinePeers, 4)
 		}(el)
@@ -185,7 +190,7 @@ func TestNewPartyCoordinatorTimeOut(t *testing.T) {
 		go func(coordinator *PartyCoordinator) {
 			defer wg.Done()
 			sigChan := make(chan string)
-			_, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+			_, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 			assert.Equal(t, err, ErrLeaderNotReady)
 		}(el)
 
@@ -199,7 +204,7 @@ func TestNewPartyCoordinatorTimeOut(t *testing.T) {
 		go func(coordinator *PartyCoordinator) {
 			defer wg.Done()
 			sigChan := make(chan string)
-			onlinePeers, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, sigChan)
+			onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
 			assert.Equal(t, ErrJoinPartyTimeout, err)
 			var onlinePeersStr []string
 			for _, el := range onlinePeers {
@@ -214,6 +219,103 @@ func TestNewPartyCoordinatorTimeOut(t *testing.T) {
 	wg.Wait()
 }
 
+func TestNewPartyCoordinatorLeaderFailover(t *testing.T) {
+	timeout := time.Second * 4
+	hosts := setupHosts(t, 5)
+	var pcs []*PartyCoordinator
+	var peers []string
+	for _, el := range hosts {
+		pcs = append(pcs, NewPartyCoordinator(el, timeout))
+		peers = append(peers, el.ID().String())
+	}
+	defer func() {
+		for _, el := range pcs {
+			el.Stop()
+		}
+	}()
+
+	msgID := conversion.RandStringBytesMask(64)
+	candidates, err := LeaderCandidates(msgID, 10, peers)
+	assert.Nil(t, err)
+
+	// the first leader is offline, so the rest should fail over to the second candidate
+	wg := sync.WaitGroup{}
+	for _, el := range pcs {
+		if el.host.ID().String() == candidates[0] {
+			continue
+		}
+		wg.Add(1)
+		go func(coordinator *PartyCoordinator) {
+			defer wg.Done()
+			sigChan := make(chan string)
+			onlinePeers, leader, failedLeaders, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
+			assert.Nil(t, err)
+			assert.Len(t, onlinePeers, 4)
+			assert.Equal(t, candidates[1], leader)
+			assert.Equal(t, []string{candidates[0]}, failedLeaders)
+		}(el)
+	}
+	wg.Wait()
+}
+
+func TestNewPartyCoordinatorPresignAgreement(t *testing.T) {
+	timeout := time.Second * 4
+	hosts := setupHosts(t, 4)
+	var pcs []*PartyCoordinator
+	var peers []string
+	for _, el := range hosts {
+		pcs = append(pcs, NewPartyCoordinator(el, timeout))
+		peers = append(peers, el.ID().String())
+	}
+	defer func() {
+		for _, el := range pcs {
+			el.Stop()
+		}
+	}()
+
+	msgID := conversion.RandStringBytesMask(64)
+	leader, err := LeaderNode(msgID, 10, peers)
+	assert.Nil(t, err)
+	// the leader picks the presignatures offered by all the online peers
+	pick := func(onlinePeers []peer.ID, offers map[peer.ID][]string) []string {
+		counts := make(map[string]int)
+		for _, el := range onlinePeers {
+			for _, id := range offers[el] {
+				counts[id]++
+			}
+		}
+		var picked []string
+		for id, count := range counts {
+			if count == len(onlinePeers) {
+				picked = append(picked, id)
+			}
+		}
+		sort.Strings(picked)
+		return picked
+	}
+	wg := sync.WaitGroup{}
+	for i, el := range pcs {
+		offer := []string{"a", "b", "c"}
+		// the leader misses the presignature b and one member misses a
+		if el.host.ID().String() == leader {
+			offer = []string{"a", "c"}
+		} else if i == 0 || (i == 1 && pcs[0].host.ID().String() == leader) {
+			offer = []string{"b", "c"}
+		}
+		presign := &PresignAgreement{Offer: offer, Pick: pick}
+		wg.Add(1)
+		go func(coordinator *PartyCoordinator) {
+			defer wg.Done()
+			sigChan := make(chan string)
+			onlinePeers, _, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, presign, sigChan)
+			assert.Nil(t, err)
+			assert.Len(t, onlinePeers, 4)
+			assert.Equal(t, []string{"c"}, presign.Picked())
+		}(el)
+	}
+	wg.Wait()
+}
+
 func TestGetPeerIDs(t *testing.T) {
 	id1 := tnet.RandIdentityOrFatal(t)
 	mn := mocknet.New()
@@ -242,3 +344,65 @@ func TestGetPeerIDs(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Len(t, r2, 0)
 }
+
+func TestLeaderAttempts(t *testing.T) {
+	assert.Equal(t, 2, LeaderAttempts(5, 3))
+	assert.Equal(t, 1, LeaderAttempts(4, 3))
+	assert.Equal(t, 1, LeaderAttempts(3, 3))
+	assert.Equal(t, 3, LeaderAttempts(3, -1))
+}
+
+func TestNewPartyCoordinatorLatencyLeader(t *testing.T) {
+	mn := mocknet.New()
+	hosts := setupHostsOnMocknet(t, mn, 4)
+	var pcs []*PartyCoordinator
+	var peers []string
+	for _, el := range hosts {
+		ping.NewPingService(el)
+		pc := NewPartyCoordinator(el, time.Second*4)
+		pc.SetLeaderSelector(NewLatencyLeaderSelector())
+		pcs = append(pcs, pc)
+		peers = append(peers, el.ID().String())
+	}
+	defer func() {
+		for _, el := range pcs {
+			el.Stop()
+		}
+	}()
+
+	// the leader of the first party is slow to reach for everyone
+	msgID := conversion.RandStringBytesMask(64)
+	hashOrder, err := LeaderCandidates(msgID, 10, peers)
+	assert.Nil(t, err)
+	slow, err := peer.Decode(hashOrder[0])
+	assert.Nil(t, err)
+	for _, el := range hosts {
+		for _, l := range mn.LinksBetweenPeers(slow, el.ID()) {
+			l.SetOptions(mocknet.LinkOptions{Latency: 300 * time.Millisecond})
+		}
+	}
+	wg := sync.WaitGroup{}
+	for _, el := range pcs {
+		wg.Add(1)
+		go func(coordinator *PartyCoordinator) {
+			defer wg.Done()
+			sigChan := make(chan string)
+			onlinePeers, leader, _, err := coordinator.JoinPartyWithLeader(msgID, 10, peers, 3, true, nil, sigChan)
+			assert.Nil(t, err)
+			assert.Len(t, onlinePeers, 4)
+			assert.Equal(t, hashOrder[0], leader)
+		}(el)
+	}
+	wg.Wait()
+
+	// all the nodes order the leaders of the later parties by the RTTs the leader published
+	nextMsgID := conversion.RandStringBytesMask(64)
+	expected, err := pcs[0].leaderSelector.Candidates(nextMsgID, 20, peers)
+	assert.Nil(t, err)
+	assert.Equal(t, slow.String(), expected[3])
+	for _, el := range pcs[1:] {
+		candidates, err := el.leaderSelector.Candidates(nextMsgID, 20, peers)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, candidates)
+	}
+}
@@ -20,8 +20,17 @@ type PeerStatus struct {
 	leader             string
 	leaderSetLock      *sync.RWMutex
 	threshold          int
+	blockHeight        int64
+	requireSigned      bool
 	reqCount           int
 	streams            *sync.Map
+	// standby peers are only counted once we give up waiting for the others
+	standby         map[peer.ID]bool
+	standbyAdmitted bool
+	// the presignatures offered by the members in their requests
+	presignOffers map[peer.ID][]string
+	// the RTTs the members measure to the peers, reported in their requests
+	latencyReports map[peer.ID]map[peer.ID]int64
 }
 
 func (ps *PeerStatus) getLeaderResponse() *messages.JoinPartyLeaderComm {
@@ -36,6 +45,15 @@ func (ps *PeerStatus) setLeaderResponse(resp *messages.JoinPartyLeaderComm) {
 	ps.leaderResponse = resp
 }
 
+// getLeader returns the leader of the party, the leader is set under either of the locks
+func (ps *PeerStatus) getLeader() string {
+	ps.leaderSetLock.RLock()
+	defer ps.leaderSetLock.RUnlock()
+	ps.peerStatusLock.RLock()
+	defer ps.peerStatusLock.RUnlock()
+	return ps.leader
+}
+
 func NewPeerStatus(peerNodes []peer.ID, myPeerID peer.ID, leader string, threshold int) *PeerStatus {
 	dat := make(map[peer.ID]bool)
 	for _, el := range peerNodes {
@@ -55,6 +73,9 @@ func NewPeerStatus(peerNodes []peer.ID, myPeerID peer.ID, leader string, thresho
 		leaderResponseLock: &sync.RWMutex{},
 		streams:            &sync.Map{},
 		leaderSetLock:      &sync.RWMutex{},
+		standby:            make(map[peer.ID]bool),
+		presignOffers:      make(map[peer.ID][]string),
+		latencyReports:     make(map[peer.ID]map[peer.ID]int64),
 	}
 	return peerStatus
 }
@@ -105,9 +126,13 @@ func (ps *PeerStatus) updatePeer(peerNode peer.ID, stream network.Stream) (bool,
 		return false, nil
 	}
 	if !val {
-		ps.peersResponse[peerNode] = true
 		// we store the stream for the peer to send the response back to peers
 		ps.streams.Store(peerNode, stream)
+		if _, ok := ps.standby[peerNode]; ok && !ps.standbyAdmitted {
+			ps.standby[peerNode] = true
+			return false, nil
+		}
+		ps.peersResponse[peerNode] = true
 		ps.reqCount++
 		if ps.reqCount >= ps.threshold {
 			return true, nil
@@ -115,3 +140,80 @@ func (ps *PeerStatus) updatePeer(peerNode peer.ID, stream network.Stream) (bool,
 	}
 	return false, nil
 }
+
+// setPresignOffer keeps the presignatures the peer offers in its request
+func (ps *PeerStatus) setPresignOffer(peerNode peer.ID, presignIDs []string) {
+	ps.peerStatusLock.Lock()
+	defer ps.peerStatusLock.Unlock()
+	if _, ok := ps.peersResponse[peerNode]; !ok {
+		return
+	}
+	ps.presignOffers[peerNode] = presignIDs
+}
+
+// getPresignOffers returns a copy of the presignatures offered by the peers
+func (ps *PeerStatus) getPresignOffers() map[peer.ID][]string {
+	ps.peerStatusLock.RLock()
+	defer ps.peerStatusLock.RUnlock()
+	offers := make(map[peer.ID][]string, len(ps.presignOffers))
+	for k, v := range ps.presignOffers {
+		offers[k] = v
+	}
+	return offers
+}
+
+// setLatencyReport keeps the RTTs the peer reports in its request
+func (ps *PeerStatus) setLatencyReport(peerNode peer.ID, rtts map[peer.ID]int64) {
+	ps.peerStatusLock.Lock()
+	defer ps.peerStatusLock.Unlock()
+	if _, ok := ps.peersResponse[peerNode]; !ok || len(rtts) == 0 {
+		return
+	}
+	ps.latencyReports[peerNode] = rtts
+}
+
+// getLatencyReports returns the RTTs reported by the given peers
+func (ps *PeerStatus) getLatencyReports(peers []peer.ID) []map[peer.ID]int64 {
+	ps.peerStatusLock.RLock()
+	defer ps.peerStatusLock.RUnlock()
+	var reports []map[peer.ID]int64
+	for _, el := range peers {
+		if report, ok := ps.latencyReports[el]; ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// setStandbyPeers puts the given peers on standby, their requests are not counted until admitStandbyPeers is called
+func (ps *PeerStatus) setStandbyPeers(peers []peer.ID) {
+	ps.peerStatusLock.Lock()
+	defer ps.peerStatusLock.Unlock()
+	for _, el := range peers {
+		if _, ok := ps.peersResponse[el]; ok {
+			ps.standby[el] = false
+		}
+	}
+}
+
+// admitStandbyPeers counts the standby peers that have sent the request, and all the following ones,
+// it returns true if the party is formed by the admitted peers
+func (ps *PeerStatus) admitStandbyPeers() bool {
+	ps.peerStatusLock.Lock()
+	defer ps.peerStatusLock.Unlock()
+	ps.standbyAdmitted = true
+	if ps.reqCount >= ps.threshold {
+		return false
+	}
+	for peerNode, requested := range ps.standby {
+		if !requested || ps.peersResponse[peerNode] {
+			continue
+		}
+		ps.peersResponse[peerNode] = true
+		ps.reqCount++
+		if ps.reqCount >= ps.threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -68,3 +68,37 @@ func (s *PeerStatusTestSuite) TestPeerStatus(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(ret, Equals, false)
 }
+
+func (s *PeerStatusTestSuite) TestStandbyPeers(c *C) {
+	peers := generateRandomPeers(c, 5)
+	peerStatus := NewPeerStatus(peers, peers[0], peers[0].String(), 2)
+	peerStatus.setStandbyPeers([]peer.ID{peers[1], peers[2]})
+
+	// the standby peers are not counted
+	formed, err := peerStatus.updatePeer(peers[1], nil)
+	c.Assert(err, IsNil)
+	c.Assert(formed, Equals, false)
+	formed, err = peerStatus.updatePeer(peers[3], nil)
+	c.Assert(err, IsNil)
+	c.Assert(formed, Equals, false)
+	online, _ := peerStatus.getPeersStatus()
+	c.Assert(online, DeepEquals, []peer.ID{peers[3]})
+
+	// once admitted, the standby peer that has sent the request forms the party
+	c.Assert(peerStatus.admitStandbyPeers(), Equals, true)
+	online, _ = peerStatus.getPeersStatus()
+	c.Assert(online, HasLen, 2)
+
+	peerStatus = NewPeerStatus(peers, peers[0], peers[0].String(), 2)
+	peerStatus.setStandbyPeers([]peer.ID{peers[1]})
+	formed, err = peerStatus.updatePeer(peers[2], nil)
+	c.Assert(err, IsNil)
+	c.Assert(formed, Equals, false)
+	formed, err = peerStatus.updatePeer(peers[3], nil)
+	c.Assert(err, IsNil)
+	c.Assert(formed, Equals, true)
+	// the party is formed without the standby peer
+	c.Assert(peerStatus.admitStandbyPeers(), Equals, false)
+	online, _ = peerStatus.getPeersStatus()
+	c.Assert(online, HasLen, 2)
+}
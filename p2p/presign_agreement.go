@@ -0,0 +1,59 @@
+package p2p
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// PresignAgreement lets the join party agree on the presignatures the keysign is finished with. The members offer
+// the presignatures they hold in the request, the leader picks from the ones offered by all the online peers and
+// sends them in the signed response, so that all the signers either sign with the same presignatures or all run
+// the full keysign when nothing is picked.
+type PresignAgreement struct {
+	// Offer is the ID of the presignatures this node holds
+	Offer []string
+	// Pick returns the presignatures the online peers sign with, from the ones offered by each of them
+	Pick func(onlinePeers []peer.ID, offers map[peer.ID][]string) []string
+
+	lock   sync.Mutex
+	picked []string
+}
+
+// Picked returns the presignatures agreed in the last join party, it is empty if the party runs the full keysign
+func (pa *PresignAgreement) Picked() []string {
+	if pa == nil {
+		return nil
+	}
+	pa.lock.Lock()
+	defer pa.lock.Unlock()
+	return pa.picked
+}
+
+func (pa *PresignAgreement) setPicked(picked []string) {
+	if pa == nil {
+		return
+	}
+	pa.lock.Lock()
+	defer pa.lock.Unlock()
+	pa.picked = picked
+}
+
+func (pa *PresignAgreement) offer() []string {
+	if pa == nil {
+		return nil
+	}
+	return pa.Offer
+}
+
+// pick picks the presignatures as the leader, only the offers of the online peers are considered
+func (pa *PresignAgreement) pick(onlinePeers []peer.ID, offers map[peer.ID][]string) []string {
+	if pa == nil || pa.Pick == nil {
+		return nil
+	}
+	onlineOffers := make(map[peer.ID][]string, len(onlinePeers))
+	for _, el := range onlinePeers {
+		onlineOffers[el] = offers[el]
+	}
+	return pa.Pick(onlinePeers, onlineOffers)
+}
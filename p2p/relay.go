@@ -0,0 +1,49 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/autorelay"
+	maddr "github.com/multiformats/go-multiaddr"
+)
+
+// EnableRelay let the nodes behind NAT reach and be reached by the committee through the circuit relay v2,
+// and upgrade the relayed connections to the direct ones with DCUtR hole punching. relayPeers are the
+// static relays we reserve the slot on, if relayService is true, we act as a relay for the other members.
+// If behindNAT is true, we reserve the slots right away rather than wait for AutoNAT to find out we are not
+// reachable. It must be called before Start.
+func (c *Communication) EnableRelay(relayPeers []maddr.Multiaddr, relayService, behindNAT bool) {
+	c.enableRelay = true
+	c.relayPeers = relayPeers
+	c.relayService = relayService
+	c.behindNAT = behindNAT
+}
+
+func (c *Communication) relayOptions() ([]libp2p.Option, error) {
+	if !c.enableRelay {
+		return nil, nil
+	}
+	opts := []libp2p.Option{
+		libp2p.EnableRelay(),
+		libp2p.EnableHolePunching(),
+	}
+	if len(c.relayPeers) != 0 {
+		relays, err := peer.AddrInfosFromP2pAddrs(c.relayPeers...)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse the relay peers: %w", err)
+		}
+		// autorelay waits for the boot delay if it has less candidates than it wants, while the static relays
+		// are all the candidates we have
+		opts = append(opts, libp2p.EnableAutoRelayWithStaticRelays(relays, autorelay.WithMinCandidates(len(relays))))
+	}
+	if c.behindNAT {
+		opts = append(opts, libp2p.ForceReachabilityPrivate())
+	}
+	if c.relayService {
+		// the relay also helps the peers to find out whether they are behind NAT
+		opts = append(opts, libp2p.EnableRelayService(), libp2p.EnableNATService())
+	}
+	return opts, nil
+}
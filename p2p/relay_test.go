@@ -0,0 +1,140 @@
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+	"github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+	maddr "github.com/multiformats/go-multiaddr"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+type RelayTestSuite struct{}
+
+var _ = Suite(&RelayTestSuite{})
+
+func startRelayedCommunication(c *C, port int, relayAddr maddr.Multiaddr, behindNAT bool) *Communication {
+	sk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	c.Assert(err, IsNil)
+	skRaw, err := sk.Raw()
+	c.Assert(err, IsNil)
+	comm, err := NewCommunication("relayTest", []maddr.Multiaddr{relayAddr}, port, "")
+	c.Assert(err, IsNil)
+	comm.EnableRelay([]maddr.Multiaddr{relayAddr}, false, behindNAT)
+	c.Assert(comm.Start(skRaw), IsNil)
+	return comm
+}
+
+func (RelayTestSuite) TestRelayOptions(c *C) {
+	comm, err := NewCommunication("relayTest", nil, 2230, "")
+	c.Assert(err, IsNil)
+	opts, err := comm.relayOptions()
+	c.Assert(err, IsNil)
+	c.Assert(opts, HasLen, 0)
+
+	invalidRelay, err := maddr.NewMultiaddr("/ip4/127.0.0.1/tcp/2230")
+	c.Assert(err, IsNil)
+	comm.EnableRelay([]maddr.Multiaddr{invalidRelay}, false, false)
+	_, err = comm.relayOptions()
+	c.Assert(err, NotNil)
+
+	comm.EnableRelay(nil, true, false)
+	opts, err = comm.relayOptions()
+	c.Assert(err, IsNil)
+	c.Assert(opts, HasLen, 4)
+
+	comm.EnableRelay(nil, false, true)
+	opts, err = comm.relayOptions()
+	c.Assert(err, IsNil)
+	c.Assert(opts, HasLen, 3)
+
+	// the given host does not take the relay options
+	sk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	c.Assert(err, IsNil)
+	skRaw, err := sk.Raw()
+	c.Assert(err, IsNil)
+	h, err := libp2p.New(libp2p.Identity(sk), libp2p.NoListenAddrs)
+	c.Assert(err, IsNil)
+	defer h.Close()
+	comm, err = NewCommunicationWithHost("relayTest", nil, h)
+	c.Assert(err, IsNil)
+	comm.EnableRelay(nil, false, true)
+	c.Assert(comm.Start(skRaw), ErrorMatches, ".*the relay is not supported by the given host")
+
+	comm, err = NewCommunicationWithHost("relayTest", nil, h)
+	c.Assert(err, IsNil)
+	otherSk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	c.Assert(err, IsNil)
+	otherRaw, err := otherSk.Raw()
+	c.Assert(err, IsNil)
+	c.Assert(comm.Start(otherRaw), ErrorMatches, ".*does not have the identity of the private key")
+}
+
+func (RelayTestSuite) TestCommunicationThroughRelay(c *C) {
+	// we run the relay in process
+	relayHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/2231"))
+	c.Assert(err, IsNil)
+	defer relayHost.Close()
+	r, err := relay.New(relayHost)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	relayInfo := peer.AddrInfo{ID: relayHost.ID(), Addrs: relayHost.Addrs()}
+	relayAddrs, err := peer.AddrInfoToP2pAddrs(&relayInfo)
+	c.Assert(err, IsNil)
+
+	// comm1 is behind NAT, so it reserves the slot on the static relay by itself
+	comm1 := startRelayedCommunication(c, 2232, relayAddrs[0], true)
+	defer comm1.Stop()
+	comm2 := startRelayedCommunication(c, 2233, relayAddrs[0], false)
+	defer comm2.Stop()
+
+	// comm2 only knows how to reach comm1 through the relay, the dial fails until comm1 has the reservation
+	circuitAddr, err := maddr.NewMultiaddr(relayAddrs[0].String() + "/p2p-circuit")
+	c.Assert(err, IsNil)
+	comm1ID := comm1.GetHost().ID()
+	for i := 0; i < 50; i++ {
+		comm2.GetHost().Network().ClosePeer(comm1ID)
+		comm2.GetHost().Peerstore().ClearAddrs(comm1ID)
+		comm2.GetHost().Network().(*swarm.Swarm).Backoff().Clear(comm1ID)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err = comm2.GetHost().Connect(ctx, peer.AddrInfo{ID: comm1ID, Addrs: []maddr.Multiaddr{circuitAddr}})
+		cancel()
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 200)
+	}
+	c.Assert(err, IsNil)
+	relayed := false
+	for _, conn := range comm2.GetHost().Network().ConnsToPeer(comm1.GetHost().ID()) {
+		if _, err := conn.RemoteMultiaddr().ValueForProtocol(maddr.P_CIRCUIT); err == nil {
+			relayed = true
+		}
+	}
+	c.Assert(relayed, Equals, true)
+
+	msgChan := make(chan *Message, 1)
+	comm1.SetSubscribe(messages.TSSKeySignMsg, "relayMsg", msgChan)
+	defer comm1.CancelSubscribe(messages.TSSKeySignMsg, "relayMsg")
+	buf, err := json.Marshal(messages.WrappedMessage{
+		MessageType: messages.TSSKeySignMsg,
+		MsgID:       "relayMsg",
+		Payload:     []byte("hello"),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(comm2.writeToStream(comm1.GetHost().ID(), buf, "relayMsg"), IsNil)
+	select {
+	case msg := <-msgChan:
+		c.Assert(msg.PeerID, Equals, comm2.GetHost().ID())
+	case <-time.After(time.Second * 5):
+		c.Fatal("fail to receive the message through the relay")
+	}
+}
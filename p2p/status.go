@@ -0,0 +1,90 @@
+package p2p
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ConnectedPeer is the peer the host is connected to
+type ConnectedPeer struct {
+	PeerID string   `json:"peer_id"`
+	Addrs  []string `json:"addrs"`
+	// RTT is the moving average of the latency to the peer, zero if it is not measured yet
+	RTT time.Duration `json:"rtt"`
+}
+
+// JoinPartyGroup is the party we are forming for the ceremony
+type JoinPartyGroup struct {
+	MsgID     string   `json:"msg_id"`
+	Leader    string   `json:"leader"`
+	Threshold int      `json:"threshold"`
+	Online    []string `json:"online"`
+	Offline   []string `json:"offline"`
+}
+
+// ConnectedPeers returns the peers the host is connected to, with the remote address of each connection
+func (c *Communication) ConnectedPeers() []ConnectedPeer {
+	h := c.GetHost()
+	pIDs := h.Network().Peers()
+	peers := make([]ConnectedPeer, 0, len(pIDs))
+	for _, pID := range pIDs {
+		connected := ConnectedPeer{
+			PeerID: pID.String(),
+			RTT:    h.Peerstore().LatencyEWMA(pID),
+		}
+		for _, conn := range h.Network().ConnsToPeer(pID) {
+			connected.Addrs = append(connected.Addrs, conn.RemoteMultiaddr().String())
+		}
+		peers = append(peers, connected)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].PeerID < peers[j].PeerID
+	})
+	return peers
+}
+
+// Subscriptions returns the message ids subscribed on each topic
+func (c *Communication) Subscriptions() map[string][]string {
+	c.subscriberLocker.Lock()
+	defer c.subscriberLocker.Unlock()
+	subscriptions := make(map[string][]string)
+	for topic, el := range c.subscribers {
+		if msgIDs := el.MsgIDs(); len(msgIDs) != 0 {
+			subscriptions[topic.String()] = msgIDs
+		}
+	}
+	return subscriptions
+}
+
+// JoinPartyGroups returns the parties being formed
+func (pc *PartyCoordinator) JoinPartyGroups() []JoinPartyGroup {
+	pc.joinPartyGroupLock.RLock()
+	defer pc.joinPartyGroupLock.RUnlock()
+	groups := make([]JoinPartyGroup, 0, len(pc.peersGroup))
+	for msgID, el := range pc.peersGroup {
+		online, offline := el.getPeersStatus()
+		groups = append(groups, JoinPartyGroup{
+			MsgID:     msgID,
+			Leader:    el.getLeader(),
+			Threshold: el.threshold,
+			Online:    peerIDStrings(online),
+			Offline:   peerIDStrings(offline),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].MsgID < groups[j].MsgID
+	})
+	return groups
+}
+
+// peerIDStrings returns the peer ids in order
+func peerIDStrings(pIDs []peer.ID) []string {
+	result := make([]string, len(pIDs))
+	for i, el := range pIDs {
+		result[i] = el.String()
+	}
+	sort.Strings(result)
+	return result
+}
@@ -19,6 +19,7 @@ type Config struct {
 	Port             int
 	BootstrapPeers   addrList
 	ExternalIP       string
+	RelayPeers       addrList
 }
 
 // String implement fmt.Stringer
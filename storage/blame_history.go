@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const blameHistoryFileName = "blame_history.jsonl"
+
+// maxBlameHistorySize is the size the blame history file grows to before we rotate it, only the last rotated
+// file is kept, so the history on the disk is bounded to twice of it
+var maxBlameHistorySize int64 = 16 << 20
+
+// CeremonyRecord is the outcome of a keygen/keysign ceremony we keep to evaluate the reliability of the peers
+type CeremonyRecord struct {
+	MsgID        string        `json:"msg_id"`
+	Type         string        `json:"type"`
+	Participants []string      `json:"participants"`
+	Success      bool          `json:"success"`
+	FailReason   string        `json:"fail_reason,omitempty"`
+	BlameNodes   []string      `json:"blame_nodes,omitempty"`
+	Round        string        `json:"round,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// PeerScore is the reliability of the peer over the recent ceremonies it participated in
+type PeerScore struct {
+	Pubkey     string  `json:"pubkey"`
+	Ceremonies int     `json:"ceremonies"`
+	Blamed     int     `json:"blamed"`
+	Score      float64 `json:"score"`
+}
+
+// SaveCeremonyRecord append the ceremony record to the blame history file
+func (fsm *FileStateMgr) SaveCeremonyRecord(record CeremonyRecord) error {
+	if len(fsm.folder) < 1 {
+		return errors.New("base file path is invalid")
+	}
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("fail to marshal CeremonyRecord to json: %w", err)
+	}
+	fsm.writeLock.Lock()
+	defer fsm.writeLock.Unlock()
+	filePathName := filepath.Join(fsm.folder, blameHistoryFileName)
+	if info, err := os.Stat(filePathName); err == nil && info.Size()+int64(len(buf))+1 > maxBlameHistorySize {
+		if err := os.Rename(filePathName, filePathName+".1"); err != nil {
+			return fmt.Errorf("fail to rotate the blame history file: %w", err)
+		}
+	}
+	f, err := os.OpenFile(filePathName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o655)
+	if err != nil {
+		return fmt.Errorf("fail to open the blame history file: %w", err)
+	}
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("fail to write the blame history file: %w", err)
+	}
+	return f.Close()
+}
+
+// GetCeremonyRecords read all the ceremony records from the rotated and the current blame history file, the oldest first
+func (fsm *FileStateMgr) GetCeremonyRecords() ([]CeremonyRecord, error) {
+	if len(fsm.folder) < 1 {
+		return nil, errors.New("base file path is invalid")
+	}
+	filePathName := filepath.Join(fsm.folder, blameHistoryFileName)
+	fsm.writeLock.RLock()
+	defer fsm.writeLock.RUnlock()
+	var records []CeremonyRecord
+	for _, el := range []string{filePathName + ".1", filePathName} {
+		input, err := ioutil.ReadFile(el)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		fileRecords, err := parseCeremonyRecords(input)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	return records, nil
+}
+
+func parseCeremonyRecords(input []byte) ([]CeremonyRecord, error) {
+	var records []CeremonyRecord
+	for _, line := range bytes.Split(input, []byte("\n")) {
+		// we skip the empty entry
+		if len(line) == 0 {
+			continue
+		}
+		var record CeremonyRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("invalid record in blame history: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ComputePeerScores calculate the score of each peer over the last window ceremonies it participated in,
+// the score is the ratio of the ceremonies the peer is not blamed for. If window is not positive, all the
+// records are used. The scores are sorted by the pubkey.
+func ComputePeerScores(records []CeremonyRecord, window int) []PeerScore {
+	board := NewPeerScoreBoard(window)
+	for _, el := range records {
+		board.Add(el)
+	}
+	return board.Scores()
+}
+
+// PeerScoreBoard keeps whether each peer is blamed in the last window ceremonies it participated in, so that
+// the scores are available without reading the blame history
+type PeerScoreBoard struct {
+	window   int
+	lock     sync.Mutex
+	outcomes map[string][]bool
+}
+
+// NewPeerScoreBoard create a new instance of PeerScoreBoard, all the ceremonies are kept if window is not positive
+func NewPeerScoreBoard(window int) *PeerScoreBoard {
+	return &PeerScoreBoard{
+		window:   window,
+		outcomes: make(map[string][]bool),
+	}
+}
+
+// Add the outcome of the ceremony to the board, the records must be added the oldest first
+func (b *PeerScoreBoard) Add(record CeremonyRecord) {
+	blamed := make(map[string]bool, len(record.BlameNodes))
+	for _, el := range record.BlameNodes {
+		blamed[el] = true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, el := range record.Participants {
+		outcomes := append(b.outcomes[el], blamed[el])
+		if b.window > 0 && len(outcomes) > b.window {
+			outcomes = outcomes[len(outcomes)-b.window:]
+		}
+		b.outcomes[el] = outcomes
+	}
+}
+
+// Scores returns the score of the peers sorted by the pubkey
+func (b *PeerScoreBoard) Scores() []PeerScore {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	ret := make([]PeerScore, 0, len(b.outcomes))
+	for k, outcomes := range b.outcomes {
+		score := PeerScore{Pubkey: k, Ceremonies: len(outcomes)}
+		for _, el := range outcomes {
+			if el {
+				score.Blamed++
+			}
+		}
+		score.Score = float64(score.Ceremonies-score.Blamed) / float64(score.Ceremonies)
+		ret = append(ret, score)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Pubkey < ret[j].Pubkey
+	})
+	return ret
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type BlameHistoryTestSuite struct{}
+
+var _ = Suite(&BlameHistoryTestSuite{})
+
+func (s *BlameHistoryTestSuite) TestCeremonyRecords(c *C) {
+	f := filepath.Join(os.TempDir(), "blame_history_test")
+	defer func() {
+		c.Assert(os.RemoveAll(f), IsNil)
+	}()
+	fsm, err := NewFileStateMgr(f)
+	c.Assert(err, IsNil)
+	records, err := fsm.GetCeremonyRecords()
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+
+	record1 := CeremonyRecord{
+		MsgID:        "msg1",
+		Type:         "keygen",
+		Participants: []string{"A", "B", "C"},
+		Success:      true,
+		Duration:     time.Second,
+		Timestamp:    time.Now().UTC().Round(0),
+	}
+	record2 := CeremonyRecord{
+		MsgID:        "msg2",
+		Type:         "keysign",
+		Participants: []string{"A", "B", "C"},
+		FailReason:   "Tss timeout",
+		BlameNodes:   []string{"C"},
+		Round:        "KGRound1Message",
+		Duration:     2 * time.Second,
+		Timestamp:    time.Now().UTC().Round(0),
+	}
+	c.Assert(fsm.SaveCeremonyRecord(record1), IsNil)
+	c.Assert(fsm.SaveCeremonyRecord(record2), IsNil)
+	records, err = fsm.GetCeremonyRecords()
+	c.Assert(err, IsNil)
+	c.Assert(records, DeepEquals, []CeremonyRecord{record1, record2})
+
+	fsm, err = NewFileStateMgr("")
+	c.Assert(err, IsNil)
+	c.Assert(fsm.SaveCeremonyRecord(record1), NotNil)
+}
+
+func (s *BlameHistoryTestSuite) TestRotation(c *C) {
+	defer func(size int64) {
+		maxBlameHistorySize = size
+	}(maxBlameHistorySize)
+	// each file holds two records
+	maxBlameHistorySize = 250
+	fsm, err := NewFileStateMgr(c.MkDir())
+	c.Assert(err, IsNil)
+	for _, el := range []string{"msg1", "msg2", "msg3", "msg4", "msg5"} {
+		c.Assert(fsm.SaveCeremonyRecord(CeremonyRecord{MsgID: el, Participants: []string{"A"}}), IsNil)
+	}
+	records, err := fsm.GetCeremonyRecords()
+	c.Assert(err, IsNil)
+	var msgIDs []string
+	for _, el := range records {
+		msgIDs = append(msgIDs, el.MsgID)
+	}
+	// the oldest file is dropped once the rotated one is rotated again
+	c.Assert(msgIDs, DeepEquals, []string{"msg3", "msg4", "msg5"})
+}
+
+func (s *BlameHistoryTestSuite) TestComputePeerScores(c *C) {
+	records := []CeremonyRecord{
+		{Participants: []string{"A", "B", "C"}, BlameNodes: []string{"C"}},
+		{Participants: []string{"A", "B", "C"}, BlameNodes: []string{"C", "B"}},
+		{Participants: []string{"A", "B"}, Success: true},
+		{Participants: []string{"A", "C"}, Success: true},
+	}
+	scores := ComputePeerScores(records, 0)
+	c.Assert(scores, DeepEquals, []PeerScore{
+		{Pubkey: "A", Ceremonies: 4, Blamed: 0, Score: 1},
+		{Pubkey: "B", Ceremonies: 3, Blamed: 1, Score: 2.0 / 3},
+		{Pubkey: "C", Ceremonies: 3, Blamed: 2, Score: 1.0 / 3},
+	})
+
+	// only the latest two ceremonies of each peer are counted
+	scores = ComputePeerScores(records, 2)
+	c.Assert(scores, DeepEquals, []PeerScore{
+		{Pubkey: "A", Ceremonies: 2, Blamed: 0, Score: 1},
+		{Pubkey: "B", Ceremonies: 2, Blamed: 1, Score: 0.5},
+		{Pubkey: "C", Ceremonies: 2, Blamed: 1, Score: 0.5},
+	})
+	c.Assert(ComputePeerScores(nil, 2), HasLen, 0)
+}
+
+func (s *BlameHistoryTestSuite) TestPeerScoreBoard(c *C) {
+	board := NewPeerScoreBoard(2)
+	c.Assert(board.Scores(), HasLen, 0)
+	board.Add(CeremonyRecord{Participants: []string{"A", "B"}, BlameNodes: []string{"B"}})
+	board.Add(CeremonyRecord{Participants: []string{"A", "B"}, BlameNodes: []string{"B"}})
+	c.Assert(board.Scores(), DeepEquals, []PeerScore{
+		{Pubkey: "A", Ceremonies: 2, Blamed: 0, Score: 1},
+		{Pubkey: "B", Ceremonies: 2, Blamed: 2, Score: 0},
+	})
+	// the oldest ceremony of B falls out of the window
+	board.Add(CeremonyRecord{Participants: []string{"B"}, Success: true})
+	c.Assert(board.Scores()[1], DeepEquals, PeerScore{Pubkey: "B", Ceremonies: 2, Blamed: 1, Score: 0.5})
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	healthCheckFileName = ".health_check"
+	localStatePrefix    = "localstate-"
+	localStateSuffix    = ".json"
+)
+
+func (fsm *FileStateMgr) baseFolder() string {
+	if len(fsm.folder) == 0 {
+		return "."
+	}
+	return fsm.folder
+}
+
+// CheckHealth checks the state folder is still readable and writable, it writes the probe file and reads it back
+func (fsm *FileStateMgr) CheckHealth() error {
+	probe := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	filePathName := filepath.Join(fsm.baseFolder(), healthCheckFileName)
+	if err := ioutil.WriteFile(filePathName, probe, 0o600); err != nil {
+		return fmt.Errorf("state folder is not writable: %w", err)
+	}
+	buf, err := ioutil.ReadFile(filePathName)
+	if err != nil {
+		return fmt.Errorf("state folder is not readable: %w", err)
+	}
+	if !bytes.Equal(buf, probe) {
+		return errors.New("state folder returns the corrupted content")
+	}
+	return nil
+}
+
+// GetPoolPubKeys returns the pub keys of the pools we keep the local state of, the newest pool first by the time
+// its local state is saved
+func (fsm *FileStateMgr) GetPoolPubKeys() ([]string, error) {
+	entries, err := os.ReadDir(fsm.baseFolder())
+	if err != nil {
+		return nil, fmt.Errorf("fail to read the state folder: %w", err)
+	}
+	type pool struct {
+		pubKey  string
+		modTime time.Time
+	}
+	var pools []pool
+	for _, el := range entries {
+		name := el.Name()
+		if el.IsDir() || !strings.HasPrefix(name, localStatePrefix) || !strings.HasSuffix(name, localStateSuffix) {
+			continue
+		}
+		info, err := el.Info()
+		if err != nil {
+			return nil, fmt.Errorf("fail to get the info of the local state(%s): %w", name, err)
+		}
+		pools = append(pools, pool{
+			pubKey:  strings.TrimSuffix(strings.TrimPrefix(name, localStatePrefix), localStateSuffix),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		if !pools[i].modTime.Equal(pools[j].modTime) {
+			return pools[i].modTime.After(pools[j].modTime)
+		}
+		return pools[i].pubKey < pools[j].pubKey
+	})
+	pubKeys := make([]string, len(pools))
+	for i, el := range pools {
+		pubKeys[i] = el.pubKey
+	}
+	return pubKeys, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"os"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/conversion"
+)
+
+type HealthTestSuite struct{}
+
+var _ = Suite(&HealthTestSuite{})
+
+func (s *HealthTestSuite) TestCheckHealth(c *C) {
+	f := c.MkDir()
+	fsm, err := NewFileStateMgr(f)
+	c.Assert(err, IsNil)
+	c.Assert(fsm.CheckHealth(), IsNil)
+	c.Assert(os.RemoveAll(f), IsNil)
+	c.Assert(fsm.CheckHealth(), ErrorMatches, "state folder is not writable.*")
+}
+
+func (s *HealthTestSuite) TestGetPoolPubKeys(c *C) {
+	conversion.SetupBech32Prefix()
+	fsm, err := NewFileStateMgr(c.MkDir())
+	c.Assert(err, IsNil)
+	pubKeys, err := fsm.GetPoolPubKeys()
+	c.Assert(err, IsNil)
+	c.Assert(pubKeys, HasLen, 0)
+
+	pool := conversion.GetRandomPubKey()
+	c.Assert(fsm.SaveLocalState(KeygenLocalState{PubKey: pool}), IsNil)
+	c.Assert(fsm.SaveCeremonyRecord(CeremonyRecord{MsgID: "msg1"}), IsNil)
+	c.Assert(fsm.CheckHealth(), IsNil)
+	pubKeys, err = fsm.GetPoolPubKeys()
+	c.Assert(err, IsNil)
+	c.Assert(pubKeys, DeepEquals, []string{pool})
+
+	// the newest pool comes first
+	newPool := conversion.GetRandomPubKey()
+	c.Assert(fsm.SaveLocalState(KeygenLocalState{PubKey: newPool}), IsNil)
+	oldTime := time.Now().Add(-time.Hour)
+	filePathName, err := fsm.getFilePathName(pool)
+	c.Assert(err, IsNil)
+	c.Assert(os.Chtimes(filePathName, oldTime, oldTime), IsNil)
+	pubKeys, err = fsm.GetPoolPubKeys()
+	c.Assert(err, IsNil)
+	c.Assert(pubKeys, DeepEquals, []string{newPool, pool})
+}
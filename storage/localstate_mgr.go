@@ -33,6 +33,19 @@ type LocalStateManager interface {
 	GetLocalState(pubKey string) (KeygenLocalState, error)
 	SaveAddressBook(addressBook map[peer.ID][]ma.Multiaddr) error
 	RetrieveP2PAddresses() ([]ma.Multiaddr, error)
+	SaveCeremonyRecord(record CeremonyRecord) error
+	GetCeremonyRecords() ([]CeremonyRecord, error)
+	SavePresignatures(presigs []Presignature) error
+	ConsumePresignatures(poolPubKey string, ids []string) ([]Presignature, error)
+	GetPresignatures(poolPubKey string) ([]Presignature, error)
+	GetPresignatureCount(poolPubKey string) (int, error)
+}
+
+// HealthChecker is implemented by the LocalStateManager that can report its health to the readiness checks, the
+// checks it does not implement are skipped
+type HealthChecker interface {
+	GetPoolPubKeys() ([]string, error)
+	CheckHealth() error
 }
 
 // FileStateMgr save the local state to file
@@ -66,7 +79,7 @@ func (fsm *FileStateMgr) getFilePathName(pubKey string) (string, error) {
 		return "", errors.New("invalid pubkey for file name")
 	}
 
-	localFileName := fmt.Sprintf("localstate-%s.json", pubKey)
+	localFileName := localStatePrefix + pubKey + localStateSuffix
 	if len(fsm.folder) > 0 {
 		return filepath.Join(fsm.folder, localFileName), nil
 	}
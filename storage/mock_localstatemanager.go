@@ -24,3 +24,27 @@ func (s *MockLocalStateManager) SaveAddressBook(address map[peer.ID][]ma.Multiad
 func (s *MockLocalStateManager) RetrieveP2PAddresses() ([]ma.Multiaddr, error) {
 	return nil, nil
 }
+
+func (s *MockLocalStateManager) SaveCeremonyRecord(record CeremonyRecord) error {
+	return nil
+}
+
+func (s *MockLocalStateManager) GetCeremonyRecords() ([]CeremonyRecord, error) {
+	return nil, nil
+}
+
+func (s *MockLocalStateManager) SavePresignatures(presigs []Presignature) error {
+	return nil
+}
+
+func (s *MockLocalStateManager) ConsumePresignatures(poolPubKey string, ids []string) ([]Presignature, error) {
+	return nil, nil
+}
+
+func (s *MockLocalStateManager) GetPresignatures(poolPubKey string) ([]Presignature, error) {
+	return nil, nil
+}
+
+func (s *MockLocalStateManager) GetPresignatureCount(poolPubKey string) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joltify-finance/tss/conversion"
+)
+
+// ErrPresignatureNotFound indicates the presignature to consume is not held by this node
+var ErrPresignatureNotFound = errors.New("presignature not found")
+
+// Presignature is the encrypted output of the message independent keysign rounds of the signers, it can
+// finish the keysign of one message and must never be used again
+type Presignature struct {
+	ID         string    `json:"id"`
+	PoolPubKey string    `json:"pool_pub_key"`
+	Signers    []string  `json:"signers"`
+	Data       []byte    `json:"data"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// signersKey returns the key that identifies the signer set regardless of the order of the signers
+func signersKey(signers []string) string {
+	sorted := append([]string{}, signers...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// SelectPresignatures picks num presignatures of the pool generated by exactly the signers and returns them
+// together with the presignatures left. The presignatures are picked in the order of their ID, so that all the
+// signers pick the same ones. If there are less than num presignatures, nothing is picked.
+func SelectPresignatures(presigs []Presignature, poolPubKey string, signers []string, num int) ([]Presignature, []Presignature) {
+	key := signersKey(signers)
+	var matched []int
+	for i, el := range presigs {
+		if el.PoolPubKey == poolPubKey && signersKey(el.Signers) == key {
+			matched = append(matched, i)
+		}
+	}
+	if num <= 0 || len(matched) < num {
+		return nil, presigs
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return presigs[matched[i]].ID < presigs[matched[j]].ID
+	})
+	picked := make(map[int]bool, num)
+	selected := make([]Presignature, 0, num)
+	for _, idx := range matched[:num] {
+		picked[idx] = true
+		selected = append(selected, presigs[idx])
+	}
+	remaining := make([]Presignature, 0, len(presigs)-num)
+	for i, el := range presigs {
+		if !picked[i] {
+			remaining = append(remaining, el)
+		}
+	}
+	return selected, remaining
+}
+
+// TakePresignatures takes the presignatures of the pool with the given IDs in the same order, together with the
+// presignatures left. If any of them is missing, nothing is taken.
+func TakePresignatures(presigs []Presignature, poolPubKey string, ids []string) ([]Presignature, []Presignature, error) {
+	index := make(map[string]int, len(presigs))
+	for i, el := range presigs {
+		if el.PoolPubKey == poolPubKey {
+			index[el.ID] = i
+		}
+	}
+	picked := make(map[int]bool, len(ids))
+	taken := make([]Presignature, 0, len(ids))
+	for _, id := range ids {
+		idx, ok := index[id]
+		if !ok || picked[idx] {
+			return nil, presigs, fmt.Errorf("%w: %s", ErrPresignatureNotFound, id)
+		}
+		picked[idx] = true
+		taken = append(taken, presigs[idx])
+	}
+	remaining := make([]Presignature, 0, len(presigs)-len(taken))
+	for i, el := range presigs {
+		if !picked[i] {
+			remaining = append(remaining, el)
+		}
+	}
+	return taken, remaining, nil
+}
+
+func (fsm *FileStateMgr) getPresignatureFilePath(poolPubKey string) (string, error) {
+	if len(fsm.folder) < 1 {
+		return "", errors.New("base file path is invalid")
+	}
+	ret, err := conversion.CheckKeyOnCurve(poolPubKey)
+	if err != nil {
+		return "", err
+	}
+	if !ret {
+		return "", errors.New("invalid pubkey for file name")
+	}
+	return filepath.Join(fsm.folder, fmt.Sprintf("presignatures-%s.json", poolPubKey)), nil
+}
+
+func (fsm *FileStateMgr) readPresignatures(filePathName string) ([]Presignature, error) {
+	buf, err := ioutil.ReadFile(filePathName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fail to read from file(%s): %w", filePathName, err)
+	}
+	var presigs []Presignature
+	if err := json.Unmarshal(buf, &presigs); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal the presignatures: %w", err)
+	}
+	return presigs, nil
+}
+
+// writePresignatures replaces the presignature file, so that a consumed presignature never survives a crash
+// in the middle of the write
+func (fsm *FileStateMgr) writePresignatures(filePathName string, presigs []Presignature) error {
+	buf, err := json.Marshal(presigs)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the presignatures to json: %w", err)
+	}
+	tmpFile := filePathName + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, buf, 0o600); err != nil {
+		return fmt.Errorf("fail to write the presignatures: %w", err)
+	}
+	return os.Rename(tmpFile, filePathName)
+}
+
+// SavePresignatures adds the presignatures to the pool they belong to
+func (fsm *FileStateMgr) SavePresignatures(presigs []Presignature) error {
+	byPool := make(map[string][]Presignature)
+	for _, el := range presigs {
+		byPool[el.PoolPubKey] = append(byPool[el.PoolPubKey], el)
+	}
+	fsm.writeLock.Lock()
+	defer fsm.writeLock.Unlock()
+	for poolPubKey, items := range byPool {
+		filePathName, err := fsm.getPresignatureFilePath(poolPubKey)
+		if err != nil {
+			return err
+		}
+		stored, err := fsm.readPresignatures(filePathName)
+		if err != nil {
+			return err
+		}
+		if err := fsm.writePresignatures(filePathName, append(stored, items...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumePresignatures removes the presignatures of the pool with the given IDs and returns them, it fails and
+// removes nothing if any of them is missing
+func (fsm *FileStateMgr) ConsumePresignatures(poolPubKey string, ids []string) ([]Presignature, error) {
+	filePathName, err := fsm.getPresignatureFilePath(poolPubKey)
+	if err != nil {
+		return nil, err
+	}
+	fsm.writeLock.Lock()
+	defer fsm.writeLock.Unlock()
+	stored, err := fsm.readPresignatures(filePathName)
+	if err != nil {
+		return nil, err
+	}
+	taken, remaining, err := TakePresignatures(stored, poolPubKey, ids)
+	if err != nil {
+		return nil, err
+	}
+	// the presignatures are removed before they are used
+	if err := fsm.writePresignatures(filePathName, remaining); err != nil {
+		return nil, err
+	}
+	return taken, nil
+}
+
+// GetPresignatures returns the presignatures held for the pool
+func (fsm *FileStateMgr) GetPresignatures(poolPubKey string) ([]Presignature, error) {
+	filePathName, err := fsm.getPresignatureFilePath(poolPubKey)
+	if err != nil {
+		return nil, err
+	}
+	fsm.writeLock.RLock()
+	defer fsm.writeLock.RUnlock()
+	return fsm.readPresignatures(filePathName)
+}
+
+// GetPresignatureCount returns the number of presignatures left in the pool
+func (fsm *FileStateMgr) GetPresignatureCount(poolPubKey string) (int, error) {
+	filePathName, err := fsm.getPresignatureFilePath(poolPubKey)
+	if err != nil {
+		return 0, err
+	}
+	fsm.writeLock.RLock()
+	defer fsm.writeLock.RUnlock()
+	stored, err := fsm.readPresignatures(filePathName)
+	if err != nil {
+		return 0, err
+	}
+	return len(stored), nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type PresignatureTestSuite struct{}
+
+var _ = Suite(&PresignatureTestSuite{})
+
+const testPoolPubKey = "oppypub1addwnpepqtmru87hylm9q0tcza8p0vze2zvmqk0wr0933qr472hggzw2tp4pvy3756g"
+
+func (s *PresignatureTestSuite) TestSelectPresignatures(c *C) {
+	presigs := []Presignature{
+		{ID: "c", PoolPubKey: testPoolPubKey, Signers: []string{"A", "B"}},
+		{ID: "a", PoolPubKey: testPoolPubKey, Signers: []string{"B", "A"}},
+		{ID: "b", PoolPubKey: testPoolPubKey, Signers: []string{"A", "C"}},
+		{ID: "d", PoolPubKey: "otherPool", Signers: []string{"A", "B"}},
+	}
+	selected, remaining := SelectPresignatures(presigs, testPoolPubKey, []string{"B", "A"}, 2)
+	c.Assert(selected, HasLen, 2)
+	c.Assert(selected[0].ID, Equals, "a")
+	c.Assert(selected[1].ID, Equals, "c")
+	c.Assert(remaining, HasLen, 2)
+	c.Assert(remaining[0].ID, Equals, "b")
+	c.Assert(remaining[1].ID, Equals, "d")
+
+	// we never pick part of the presignatures we need
+	selected, remaining = SelectPresignatures(presigs, testPoolPubKey, []string{"A", "B"}, 3)
+	c.Assert(selected, HasLen, 0)
+	c.Assert(remaining, HasLen, 4)
+	selected, _ = SelectPresignatures(presigs, testPoolPubKey, []string{"A", "B"}, 0)
+	c.Assert(selected, HasLen, 0)
+}
+
+func (s *PresignatureTestSuite) TestTakePresignatures(c *C) {
+	presigs := []Presignature{
+		{ID: "a", PoolPubKey: testPoolPubKey},
+		{ID: "b", PoolPubKey: testPoolPubKey},
+		{ID: "c", PoolPubKey: "otherPool"},
+	}
+	taken, remaining, err := TakePresignatures(presigs, testPoolPubKey, []string{"b", "a"})
+	c.Assert(err, IsNil)
+	c.Assert(taken, HasLen, 2)
+	c.Assert(taken[0].ID, Equals, "b")
+	c.Assert(taken[1].ID, Equals, "a")
+	c.Assert(remaining, HasLen, 1)
+
+	// the presignature of the other pool and the repeated one are not taken
+	_, remaining, err = TakePresignatures(presigs, testPoolPubKey, []string{"a", "c"})
+	c.Assert(errors.Is(err, ErrPresignatureNotFound), Equals, true)
+	c.Assert(remaining, HasLen, 3)
+	_, _, err = TakePresignatures(presigs, testPoolPubKey, []string{"a", "a"})
+	c.Assert(err, NotNil)
+}
+
+func (s *PresignatureTestSuite) TestPresignatures(c *C) {
+	f := filepath.Join(os.TempDir(), "presignature_test")
+	defer func() {
+		c.Assert(os.RemoveAll(f), IsNil)
+	}()
+	fsm, err := NewFileStateMgr(f)
+	c.Assert(err, IsNil)
+	count, err := fsm.GetPresignatureCount(testPoolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 0)
+	c.Assert(fsm.SavePresignatures([]Presignature{{ID: "a", PoolPubKey: "invalid"}}), NotNil)
+
+	c.Assert(fsm.SavePresignatures([]Presignature{
+		{ID: "b", PoolPubKey: testPoolPubKey, Signers: []string{"A", "B"}, Data: []byte("b")},
+		{ID: "a", PoolPubKey: testPoolPubKey, Signers: []string{"A", "B"}, Data: []byte("a")},
+	}), IsNil)
+	c.Assert(fsm.SavePresignatures([]Presignature{
+		{ID: "c", PoolPubKey: testPoolPubKey, Signers: []string{"A", "B"}, Data: []byte("c")},
+	}), IsNil)
+	count, err = fsm.GetPresignatureCount(testPoolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 3)
+
+	presigs, err := fsm.GetPresignatures(testPoolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(presigs, HasLen, 3)
+
+	// nothing is removed if any of the presignatures is missing
+	presigs, err = fsm.ConsumePresignatures(testPoolPubKey, []string{"a", "d"})
+	c.Assert(errors.Is(err, ErrPresignatureNotFound), Equals, true)
+	c.Assert(presigs, HasLen, 0)
+	presigs, err = fsm.ConsumePresignatures(testPoolPubKey, []string{"b", "a"})
+	c.Assert(err, IsNil)
+	c.Assert(presigs, HasLen, 2)
+	c.Assert(presigs[0].Data, DeepEquals, []byte("b"))
+	c.Assert(presigs[1].Data, DeepEquals, []byte("a"))
+
+	// the presignature can only be used once
+	presigs, err = fsm.ConsumePresignatures(testPoolPubKey, []string{"a"})
+	c.Assert(err, NotNil)
+	c.Assert(presigs, HasLen, 0)
+	presigs, err = fsm.ConsumePresignatures(testPoolPubKey, []string{"c"})
+	c.Assert(err, IsNil)
+	c.Assert(presigs, HasLen, 1)
+	c.Assert(presigs[0].ID, Equals, "c")
+	count, err = fsm.GetPresignatureCount(testPoolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 0)
+}
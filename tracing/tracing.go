@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/joltify-finance/tss"
+
+// the attributes of the spans
+const (
+	MsgIDKey       = attribute.Key("tss.msg_id")
+	PoolPubKeyKey  = attribute.Key("tss.pool_pub_key")
+	RoundKey       = attribute.Key("tss.round")
+	PeerKey        = attribute.Key("tss.peer")
+	MessageTypeKey = attribute.Key("tss.message_type")
+	FailReasonKey  = attribute.Key("tss.fail_reason")
+	LeaderKey      = attribute.Key("tss.leader")
+)
+
+// the trace context is carried in the messages in the w3c format, whatever propagator is set globally
+var propagator = propagation.TraceContext{}
+
+// Init sends the spans to the OTLP collector listening on the endpoint with grpc, the returned function flushes the
+// spans and stops the exporter
+func Init(ctx context.Context, endpoint, serviceName string, insecure bool) (func(context.Context) error, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create the otlp exporter: %w", err)
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// Start starts the span as the child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartRemote starts the span handling the message of the peer, the span joins the trace of the peer if the message
+// carries its trace context, and it is linked to the span in ctx
+func StartRemote(ctx context.Context, carrier map[string]string, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithAttributes(attrs...)}
+	if local := trace.SpanContextFromContext(ctx); local.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: local}))
+	}
+	return otel.Tracer(instrumentationName).Start(Extract(ctx, carrier), name, opts...)
+}
+
+// End records the error on the span before it ends
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Fail marks the span failed for the reason, for the ceremony that fails without the error
+func Fail(span trace.Span, reason string) {
+	span.SetAttributes(FailReasonKey.String(reason))
+	span.SetStatus(codes.Error, reason)
+}
+
+// Inject returns the trace context of the span in ctx to carry in the message, nil if there is no span
+func Inject(ctx context.Context) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier
+}
+
+// Extract returns ctx with the span of the trace context carried in the message as the parent
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// ExtractHeader returns ctx with the span of the trace context in the http header as the parent
+func ExtractHeader(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Ceremonies keeps the contexts of the spans of the ongoing ceremonies, so the phases of the ceremony start their
+// spans in its trace with the msgID. All the methods work on the nil Ceremonies, the phases are then traced alone
+type Ceremonies struct {
+	lock     *sync.Mutex
+	contexts map[string]context.Context
+}
+
+// NewCeremonies creates a new instance of Ceremonies
+func NewCeremonies() *Ceremonies {
+	return &Ceremonies{
+		lock:     &sync.Mutex{},
+		contexts: make(map[string]context.Context),
+	}
+}
+
+// Start starts the span of the ceremony, the caller ends the span with Finish
+func (c *Ceremonies) Start(ctx context.Context, name, msgID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := Start(ctx, name, append(attrs, MsgIDKey.String(msgID))...)
+	if c == nil {
+		return ctx, span
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.contexts[msgID] = ctx
+	return ctx, span
+}
+
+// Context returns the context of the span of the ceremony, the background context if the ceremony is not traced
+func (c *Ceremonies) Context(msgID string) context.Context {
+	if c == nil {
+		return context.Background()
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ctx, ok := c.contexts[msgID]
+	if !ok {
+		return context.Background()
+	}
+	return ctx
+}
+
+// StartPhase starts the span of the phase of the ceremony
+func (c *Ceremonies) StartPhase(msgID, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Start(c.Context(msgID), name, append(attrs, MsgIDKey.String(msgID))...)
+}
+
+// Finish forgets the ceremony and ends its span with the error
+func (c *Ceremonies) Finish(msgID string, span trace.Span, err error) {
+	if c != nil {
+		c.lock.Lock()
+		delete(c.contexts, msgID)
+		c.lock.Unlock()
+	}
+	End(span, err)
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type TracingTestSuite struct {
+	exporter *tracetest.InMemoryExporter
+}
+
+var _ = Suite(&TracingTestSuite{})
+
+func (s *TracingTestSuite) SetUpSuite(c *C) {
+	s.exporter = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter)))
+}
+
+func (s *TracingTestSuite) SetUpTest(c *C) {
+	s.exporter.Reset()
+}
+
+func (s *TracingTestSuite) span(c *C, name string) tracetest.SpanStub {
+	for _, el := range s.exporter.GetSpans() {
+		if el.Name == name {
+			return el
+		}
+	}
+	c.Fatalf("span %s is not exported", name)
+	return tracetest.SpanStub{}
+}
+
+func (s *TracingTestSuite) TestInjectExtract(c *C) {
+	c.Assert(Inject(context.Background()), IsNil)
+	c.Assert(Extract(context.Background(), nil), Equals, context.Background())
+
+	ctx, span := Start(context.Background(), "sender")
+	carrier := Inject(ctx)
+	span.End()
+	c.Assert(carrier["traceparent"], Not(Equals), "")
+	remote := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	c.Assert(remote.IsRemote(), Equals, true)
+	c.Assert(remote.TraceID(), Equals, span.SpanContext().TraceID())
+	c.Assert(remote.SpanID(), Equals, span.SpanContext().SpanID())
+
+	header := http.Header{}
+	header.Set("traceparent", carrier["traceparent"])
+	remote = trace.SpanContextFromContext(ExtractHeader(context.Background(), header))
+	c.Assert(remote.TraceID(), Equals, span.SpanContext().TraceID())
+}
+
+func (s *TracingTestSuite) TestStartRemote(c *C) {
+	senderCtx, sender := Start(context.Background(), "sender")
+	sender.End()
+	localCtx, local := Start(context.Background(), "local")
+	local.End()
+
+	_, span := StartRemote(localCtx, Inject(senderCtx), "receiver", PeerKey.String("peer"))
+	End(span, errors.New("bad message"))
+	received := s.span(c, "receiver")
+	c.Assert(received.SpanContext.TraceID(), Equals, sender.SpanContext().TraceID())
+	c.Assert(received.Parent.SpanID(), Equals, sender.SpanContext().SpanID())
+	c.Assert(received.Links, HasLen, 1)
+	c.Assert(received.Links[0].SpanContext.SpanID(), Equals, local.SpanContext().SpanID())
+	c.Assert(received.Status.Code, Equals, codes.Error)
+	c.Assert(received.Status.Description, Equals, "bad message")
+	c.Assert(received.Events, HasLen, 1)
+
+	// the message without the trace context is handled in the local trace
+	_, span = StartRemote(localCtx, nil, "untraced")
+	span.End()
+	c.Assert(s.span(c, "untraced").Parent.SpanID(), Equals, local.SpanContext().SpanID())
+}
+
+func (s *TracingTestSuite) TestCeremonies(c *C) {
+	ceremonies := NewCeremonies()
+	c.Assert(ceremonies.Context("msg1"), Equals, context.Background())
+	ctx, span := ceremonies.Start(context.Background(), "tss.keysign", "msg1", PoolPubKeyKey.String("pool"))
+	c.Assert(ceremonies.Context("msg1"), Equals, ctx)
+
+	_, phase := ceremonies.StartPhase("msg1", "tss.join_party")
+	phase.End()
+	Fail(span, "timeout")
+	ceremonies.Finish("msg1", span, nil)
+	c.Assert(ceremonies.Context("msg1"), Equals, context.Background())
+
+	root := s.span(c, "tss.keysign")
+	c.Assert(root.Status.Code, Equals, codes.Error)
+	c.Assert(root.Attributes, DeepEquals, []attribute.KeyValue{PoolPubKeyKey.String("pool"), MsgIDKey.String("msg1"), FailReasonKey.String("timeout")})
+	joinParty := s.span(c, "tss.join_party")
+	c.Assert(joinParty.Parent.SpanID(), Equals, root.SpanContext.SpanID())
+	c.Assert(joinParty.Attributes, DeepEquals, []attribute.KeyValue{MsgIDKey.String("msg1")})
+
+	// the phases are traced alone without the ceremonies
+	var nilCeremonies *Ceremonies
+	_, span = nilCeremonies.Start(context.Background(), "tss.keygen", "msg2")
+	c.Assert(nilCeremonies.Context("msg2"), Equals, context.Background())
+	_, phase = nilCeremonies.StartPhase("msg2", "tss.keygen.generate")
+	phase.End()
+	nilCeremonies.Finish("msg2", span, errors.New("fail"))
+	c.Assert(s.span(c, "tss.keygen.generate").Parent.IsValid(), Equals, false)
+	c.Assert(s.span(c, "tss.keygen").Status.Code, Equals, codes.Error)
+}
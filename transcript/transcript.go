@@ -0,0 +1,241 @@
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+)
+
+const (
+	// DirectionIn is the message we received from the peer
+	DirectionIn = "in"
+	// DirectionOut is the message we sent to the peers
+	DirectionOut = "out"
+)
+
+// Entry is a wrapped message we exchanged with the peers in the ceremony
+type Entry struct {
+	Seq       uint64    `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Direction string    `json:"direction"`
+	// Peers is the sender of the inbound message, or the receivers of the outbound message
+	Peers []string `json:"peers"`
+	// Redacted indicates the body of the wire message is removed as it carries the secret share, Digest is the
+	// hash of the removed body
+	Redacted bool                    `json:"redacted,omitempty"`
+	Digest   string                  `json:"digest,omitempty"`
+	Message  messages.WrappedMessage `json:"message"`
+}
+
+// Transcript is the ordered messages of a ceremony, signed by the node that records them
+type Transcript struct {
+	MsgID       string `json:"msg_id"`
+	LocalPeerID string `json:"local_peer_id"`
+	PubKey      string `json:"pub_key"`
+	// Parties are the pub keys of the nodes that run the ceremony
+	Parties   []string `json:"parties"`
+	Entries   []Entry  `json:"entries"`
+	Signature []byte   `json:"signature"`
+}
+
+// signBytes returns the bytes the signature of the transcript covers
+func (t Transcript) signBytes() ([]byte, error) {
+	t.Signature = nil
+	return json.Marshal(t)
+}
+
+// Verify checks the transcript is signed by the node it claims
+func Verify(t Transcript) error {
+	pk, err := conversion.ParsePubKey(t.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid pub key of the transcript: %w", err)
+	}
+	buf, err := t.signBytes()
+	if err != nil {
+		return fmt.Errorf("fail to marshal the transcript: %w", err)
+	}
+	if !pk.VerifySignature(buf, t.Signature) {
+		return errors.New("invalid signature of the transcript")
+	}
+	return nil
+}
+
+// Load reads the transcript from the file and verifies its signature
+func Load(filePathName string) (Transcript, error) {
+	buf, err := ioutil.ReadFile(filePathName)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("fail to read the transcript: %w", err)
+	}
+	var t Transcript
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return Transcript{}, fmt.Errorf("fail to unmarshal the transcript: %w", err)
+	}
+	if err := Verify(t); err != nil {
+		return Transcript{}, err
+	}
+	return t, nil
+}
+
+// Recorder keeps the transcripts of the ongoing ceremonies and writes them to the folder once the ceremony is
+// done. All the methods are no-op on the nil recorder, so the callers need not check whether it is enabled
+type Recorder struct {
+	logger      zerolog.Logger
+	folder      string
+	privKey     tcrypto.PrivKey
+	pubKey      string
+	localPeerID string
+	lock        *sync.Mutex
+	transcripts map[string]*Transcript
+}
+
+// NewRecorder create a new instance of Recorder that writes the transcripts signed by the private key to the folder
+func NewRecorder(folder, localPeerID string, privKey tcrypto.PrivKey) (*Recorder, error) {
+	if privKey == nil {
+		return nil, errors.New("private key is nil")
+	}
+	pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: privKey.PubKey().Bytes()})
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the pub key: %w", err)
+	}
+	if err := os.MkdirAll(folder, 0o700); err != nil {
+		return nil, fmt.Errorf("fail to create the transcript folder: %w", err)
+	}
+	return &Recorder{
+		logger:      log.With().Str("module", "transcript").Logger(),
+		folder:      folder,
+		privKey:     privKey,
+		pubKey:      pubKey,
+		localPeerID: localPeerID,
+		lock:        &sync.Mutex{},
+		transcripts: make(map[string]*Transcript),
+	}, nil
+}
+
+// Start begins the transcript of the ceremony, the messages of the ceremonies not started are not recorded
+func (r *Recorder) Start(msgID string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.transcripts[msgID]; ok {
+		return
+	}
+	r.transcripts[msgID] = &Transcript{
+		MsgID:       msgID,
+		LocalPeerID: r.localPeerID,
+		PubKey:      r.pubKey,
+	}
+}
+
+// SetParties records the pub keys of the nodes that run the ceremony
+func (r *Recorder) SetParties(msgID string, parties []string) {
+	if r == nil {
+		return
+	}
+	sorted := append([]string{}, parties...)
+	sort.Strings(sorted)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if t, ok := r.transcripts[msgID]; ok {
+		t.Parties = sorted
+	}
+}
+
+// Record appends the message to the transcript of its ceremony
+func (r *Recorder) Record(direction string, peers []string, msg messages.WrappedMessage) {
+	if r == nil {
+		return
+	}
+	entry := Entry{
+		Timestamp: time.Now().UTC(),
+		Direction: direction,
+		Peers:     peers,
+		Message:   msg,
+	}
+	redact(&entry)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	t, ok := r.transcripts[msg.MsgID]
+	if !ok {
+		return
+	}
+	entry.Seq = uint64(len(t.Entries))
+	t.Entries = append(t.Entries, entry)
+}
+
+// Finish signs the transcript of the ceremony and writes it to the folder, it returns the file of the transcript
+func (r *Recorder) Finish(msgID string) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	r.lock.Lock()
+	t, ok := r.transcripts[msgID]
+	delete(r.transcripts, msgID)
+	r.lock.Unlock()
+	if !ok {
+		return "", fmt.Errorf("transcript of %s is not started", msgID)
+	}
+	buf, err := t.signBytes()
+	if err != nil {
+		return "", fmt.Errorf("fail to marshal the transcript: %w", err)
+	}
+	t.Signature, err = r.privKey.Sign(buf)
+	if err != nil {
+		return "", fmt.Errorf("fail to sign the transcript: %w", err)
+	}
+	buf, err = json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("fail to marshal the transcript: %w", err)
+	}
+	filePathName := filepath.Join(r.folder, msgID+".json")
+	tmpFile := filePathName + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, buf, 0o600); err != nil {
+		return "", fmt.Errorf("fail to write the transcript: %w", err)
+	}
+	if err := os.Rename(tmpFile, filePathName); err != nil {
+		return "", fmt.Errorf("fail to write the transcript: %w", err)
+	}
+	r.logger.Debug().Msgf("transcript of %s with %d messages is saved", msgID, len(t.Entries))
+	return filePathName, nil
+}
+
+// redact removes the body of the unicast keygen message, which carries the secret share of the receiver in
+// plaintext. The routing and the signature of the message are kept, the other messages are recorded as they are
+func redact(entry *Entry) {
+	if entry.Message.MessageType != messages.TSSKeyGenMsg {
+		return
+	}
+	var wireMsg messages.WireMessage
+	if err := json.Unmarshal(entry.Message.Payload, &wireMsg); err != nil {
+		return
+	}
+	if wireMsg.Routing == nil || wireMsg.Routing.IsBroadcast {
+		return
+	}
+	digest := sha256.Sum256(wireMsg.Message)
+	wireMsg.Message = nil
+	payload, err := json.Marshal(wireMsg)
+	if err != nil {
+		return
+	}
+	entry.Redacted = true
+	entry.Digest = hex.EncodeToString(digest[:])
+	entry.Message.Payload = payload
+}
@@ -0,0 +1,104 @@
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	btss "github.com/binance-chain/tss-lib/tss"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type TranscriptTestSuite struct{}
+
+var _ = Suite(&TranscriptTestSuite{})
+
+func wrapWireMsg(c *C, msgID string, msgType messages.THORChainTSSMessageType, isBroadcast bool, body []byte) messages.WrappedMessage {
+	wireMsg := messages.WireMessage{
+		Routing: &btss.MessageRouting{
+			From:        btss.NewPartyID("1", "", big.NewInt(1)),
+			IsBroadcast: isBroadcast,
+		},
+		RoundInfo: "KGRound2Message1",
+		Message:   body,
+		Sig:       []byte("signature"),
+	}
+	payload, err := json.Marshal(wireMsg)
+	c.Assert(err, IsNil)
+	return messages.WrappedMessage{
+		MessageType: msgType,
+		MsgID:       msgID,
+		Payload:     payload,
+	}
+}
+
+func (TranscriptTestSuite) TestRecorder(c *C) {
+	var nilRecorder *Recorder
+	nilRecorder.Start("msg")
+	nilRecorder.Record(DirectionIn, nil, messages.WrappedMessage{MsgID: "msg"})
+	_, err := nilRecorder.Finish("msg")
+	c.Assert(err, IsNil)
+	_, err = NewRecorder(c.MkDir(), "peer", nil)
+	c.Assert(err, NotNil)
+
+	sk := ed25519.GenPrivKey()
+	r, err := NewRecorder(c.MkDir(), "localPeer", sk)
+	c.Assert(err, IsNil)
+	secret := []byte("secret share")
+	broadcast := wrapWireMsg(c, "msg1", messages.TSSKeyGenMsg, true, []byte("broadcast"))
+	unicast := wrapWireMsg(c, "msg1", messages.TSSKeyGenMsg, false, secret)
+	// the ceremony is not started yet
+	r.Record(DirectionIn, []string{"peer1"}, broadcast)
+	r.Start("msg1")
+	r.SetParties("msg1", []string{"pk2", "pk1"})
+	r.Record(DirectionIn, []string{"peer1"}, broadcast)
+	r.Record(DirectionIn, []string{"peer1"}, unicast)
+	r.Record(DirectionOut, []string{"peer1", "peer2"}, wrapWireMsg(c, "msg1", messages.TSSKeySignMsg, false, []byte("unicast")))
+	// the message of the other ceremony
+	r.Record(DirectionIn, []string{"peer1"}, wrapWireMsg(c, "msg2", messages.TSSKeyGenMsg, true, nil))
+
+	filePathName, err := r.Finish("msg1")
+	c.Assert(err, IsNil)
+	_, err = r.Finish("msg1")
+	c.Assert(err, NotNil)
+
+	t, err := Load(filePathName)
+	c.Assert(err, IsNil)
+	c.Assert(t.MsgID, Equals, "msg1")
+	c.Assert(t.LocalPeerID, Equals, "localPeer")
+	c.Assert(t.Parties, DeepEquals, []string{"pk1", "pk2"})
+	c.Assert(t.Entries, HasLen, 3)
+	for i, el := range t.Entries {
+		c.Assert(el.Seq, Equals, uint64(i))
+	}
+	c.Assert(t.Entries[0].Redacted, Equals, false)
+	c.Assert(t.Entries[0].Message, DeepEquals, broadcast)
+	// the secret share of the keygen is never written
+	c.Assert(t.Entries[1].Redacted, Equals, true)
+	digest := sha256.Sum256(secret)
+	c.Assert(t.Entries[1].Digest, Equals, hex.EncodeToString(digest[:]))
+	var wireMsg messages.WireMessage
+	c.Assert(json.Unmarshal(t.Entries[1].Message.Payload, &wireMsg), IsNil)
+	c.Assert(wireMsg.Message, IsNil)
+	c.Assert(wireMsg.Sig, DeepEquals, []byte("signature"))
+	c.Assert(t.Entries[2].Direction, Equals, DirectionOut)
+	c.Assert(t.Entries[2].Redacted, Equals, false)
+	c.Assert(t.Entries[2].Peers, DeepEquals, []string{"peer1", "peer2"})
+
+	// the transcript cannot be changed without the key of the node
+	t.Entries = t.Entries[1:]
+	c.Assert(Verify(t), NotNil)
+	buf, err := json.Marshal(t)
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(filePathName, buf, 0o600), IsNil)
+	_, err = Load(filePathName)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,79 @@
+package tss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+)
+
+// writeAudit appends the entry to the audit log if it is enabled, the request the node can not account for fails
+func (t *TssServer) writeAudit(entry *audit.Entry) error {
+	if err := t.auditLog.Append(entry); err != nil {
+		return fmt.Errorf("fail to write the audit log of %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// beginAudit writes the entry of the request before the node joins the party, so that the request is on the log
+// even if the node crashes in the party
+func (t *TssServer) beginAudit(entry *audit.Entry) error {
+	if t.auditLog == nil {
+		return nil
+	}
+	entry.Stage = audit.StageRequest
+	if err := t.writeAudit(entry); err != nil {
+		entry.Stage = ""
+		return err
+	}
+	return nil
+}
+
+// auditAdmin writes the entry of the admin action asked by the caller in ctx before the node acts on it
+func (t *TssServer) auditAdmin(ctx context.Context, action string, request interface{}) error {
+	return t.writeAudit(audit.NewEntry(ctx, action, request))
+}
+
+// finishAudit writes the entry of the outcome of the request, the blame is the one returned to the caller. The
+// request rejected before it is recorded is written along with its outcome. The outcome is only known once the node
+// has acted, so the failure to write it does not change what the caller gets, it is logged and fails the audit log
+// check of the readiness instead
+func (t *TssServer) finishAudit(entry *audit.Entry, status common.Status, b blame.Blame, result interface{}, err error) {
+	if t.auditLog == nil {
+		return
+	}
+	outcome := entry
+	if entry.Stage == audit.StageRequest {
+		outcome = entry.Outcome()
+	}
+	outcome.Stage = audit.StageOutcome
+	outcome.Status = statusName(status)
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	if status == common.Success {
+		outcome.SetResult(result)
+	}
+	if status == common.Fail {
+		outcome.FailReason = b.FailReason
+		for _, el := range b.BlameNodes {
+			outcome.BlameNodes = append(outcome.BlameNodes, el.Pubkey)
+		}
+	}
+	if err := t.writeAudit(outcome); err != nil {
+		t.logger.Error().Err(err).Str("msgID", outcome.MsgID).Msg("fail to record the outcome of the request")
+	}
+}
+
+func statusName(status common.Status) string {
+	switch status {
+	case common.Success:
+		return "success"
+	case common.Fail:
+		return "fail"
+	default:
+		return "na"
+	}
+}
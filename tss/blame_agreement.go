@@ -0,0 +1,154 @@
+package tss
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/tracing"
+)
+
+// defaultBlameAgreementTimeout is the time we wait for the blame votes if it is not configured
+const defaultBlameAgreementTimeout = 10 * time.Second
+
+func (t *TssServer) subscribeBlameVotes(msgID string, parties int) chan *p2p.Message {
+	voteChan := make(chan *p2p.Message, parties)
+	t.p2pCommunication.SetSubscribe(messages.TSSBlameVote, msgID, voteChan)
+	return voteChan
+}
+
+func (t *TssServer) cancelBlameVotes(msgID string) {
+	t.p2pCommunication.CancelSubscribe(messages.TSSBlameVote, msgID)
+	t.p2pCommunication.ReleaseStream(msgID)
+}
+
+// blameAgreement exchanges our blame with the other participants of the failed ceremony, and returns the blame
+// of the nodes blamed by at least threshold parties together with the votes of each blamed node. The participants
+// are the nodes expected to vote, which are the signers for the keysign. If we fail to run the agreement, we
+// return our own blame.
+func (t *TssServer) blameAgreement(msgID string, participants []string, threshold int, localBlame blame.Blame, voteChan chan *p2p.Message) (blame.Blame, map[string]int) {
+	logger := t.logger.With().Str("msgID", msgID).Str("module", "blame_agreement").Logger()
+	ctx, span := t.startPhase(msgID, "tss.blame_agreement")
+	defer span.End()
+	vote := blame.NewVote(msgID, t.localNodePubKey, localBlame)
+	if err := vote.Sign(t.privateKey); err != nil {
+		logger.Error().Err(err).Msg("fail to sign the blame vote")
+		return localBlame, nil
+	}
+	payload, err := json.Marshal(vote)
+	if err != nil {
+		logger.Error().Err(err).Msg("fail to marshal the blame vote")
+		return localBlame, nil
+	}
+	buf, err := json.Marshal(messages.WrappedMessage{
+		MessageType:  messages.TSSBlameVote,
+		MsgID:        msgID,
+		Payload:      payload,
+		TraceContext: tracing.Inject(ctx),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("fail to marshal the wrapped blame vote")
+		return localBlame, nil
+	}
+	peersID, err := conversion.GetPeerIDsFromPubKeys(participants)
+	if err != nil {
+		logger.Error().Err(err).Msg("fail to get the peer id of the participants")
+		return localBlame, nil
+	}
+	t.p2pCommunication.Broadcast(peersID, buf, msgID)
+
+	voters := make(map[string]bool, len(participants))
+	for _, el := range participants {
+		voters[el] = true
+	}
+	votes := map[string]blame.Vote{
+		t.localNodePubKey: vote,
+	}
+	timeout := t.conf.BlameAgreementTimeout
+	if timeout == 0 {
+		timeout = defaultBlameAgreementTimeout
+	}
+	// the node should never be blamed by less than one party
+	minVotes := threshold
+	if minVotes < 1 {
+		minVotes = 1
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+collect:
+	for !votesSettled(votes, voters, minVotes) {
+		select {
+		case msg := <-voteChan:
+			v, err := parseBlameVote(msg, msgID)
+			if err != nil {
+				logger.Error().Err(err).Msgf("invalid blame vote from peer %s", msg.PeerID)
+				continue
+			}
+			if !voters[v.Voter] {
+				logger.Error().Msgf("blame vote from %s who is not the participant", v.Voter)
+				continue
+			}
+			votes[v.Voter] = v
+		case <-timer.C:
+			logger.Warn().Msgf("blame agreement timeout with %d out of %d votes", len(votes), len(voters))
+			break collect
+		case <-t.stopChan:
+			break collect
+		}
+	}
+
+	allVotes := make([]blame.Vote, 0, len(votes))
+	for _, el := range votes {
+		allVotes = append(allVotes, el)
+	}
+	merged, counts := blame.MergeVotes(localBlame, allVotes, minVotes)
+	logger.Info().Msgf("blame agreement with %d votes: %v", len(allVotes), counts)
+	return merged, counts
+}
+
+// votesSettled tells whether we have the votes of all the voters that are not accused. The votes of the accused
+// nodes are never counted, so we do not wait for them, and the nodes merge the same votes whatever the order the
+// votes of the accused nodes arrive in.
+func votesSettled(votes map[string]blame.Vote, voters map[string]bool, minVotes int) bool {
+	received := make([]blame.Vote, 0, len(votes))
+	for _, el := range votes {
+		received = append(received, el)
+	}
+	accused := blame.AccusedNodes(received, minVotes)
+	for el := range voters {
+		if _, ok := votes[el]; !ok && !accused[el] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBlameVote returns the vote in the message after we verify it is signed by the sender for this ceremony
+func parseBlameVote(msg *p2p.Message, msgID string) (blame.Vote, error) {
+	var wrappedMsg messages.WrappedMessage
+	if err := json.Unmarshal(msg.Payload, &wrappedMsg); err != nil {
+		return blame.Vote{}, fmt.Errorf("fail to unmarshal the wrapped message: %w", err)
+	}
+	var vote blame.Vote
+	if err := json.Unmarshal(wrappedMsg.Payload, &vote); err != nil {
+		return blame.Vote{}, fmt.Errorf("fail to unmarshal the blame vote: %w", err)
+	}
+	if vote.MsgID != msgID {
+		return blame.Vote{}, fmt.Errorf("blame vote of msgID(%s) is not for this ceremony", vote.MsgID)
+	}
+	voterID, err := conversion.GetPeerIDFromPubKey(vote.Voter)
+	if err != nil {
+		return blame.Vote{}, fmt.Errorf("fail to get the peer id of the voter: %w", err)
+	}
+	if voterID != msg.PeerID {
+		return blame.Vote{}, fmt.Errorf("blame vote of %s is sent by %s", voterID, msg.PeerID)
+	}
+	if err := blame.VerifyVote(vote); err != nil {
+		return blame.Vote{}, err
+	}
+	return vote, nil
+}
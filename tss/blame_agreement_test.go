@@ -0,0 +1,99 @@
+package tss
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+)
+
+type BlameAgreementTestSuite struct{}
+
+var _ = Suite(&BlameAgreementTestSuite{})
+
+func (BlameAgreementTestSuite) SetUpSuite(c *C) {
+	conversion.SetupBech32Prefix()
+}
+
+func wrapBlameVote(c *C, vote blame.Vote) []byte {
+	payload, err := json.Marshal(vote)
+	c.Assert(err, IsNil)
+	buf, err := json.Marshal(messages.WrappedMessage{
+		MessageType: messages.TSSBlameVote,
+		MsgID:       vote.MsgID,
+		Payload:     payload,
+	})
+	c.Assert(err, IsNil)
+	return buf
+}
+
+func (BlameAgreementTestSuite) TestParseBlameVote(c *C) {
+	priKey, err := conversion.GetPriKey(testPriKeyArr[0])
+	c.Assert(err, IsNil)
+	voterID, err := conversion.GetPeerIDFromPubKey(testPubKeys[0])
+	c.Assert(err, IsNil)
+	otherID, err := conversion.GetPeerIDFromPubKey(testPubKeys[1])
+	c.Assert(err, IsNil)
+
+	localBlame := blame.NewBlame(blame.TssTimeout, []blame.Node{{Pubkey: testPubKeys[2]}})
+	vote := blame.NewVote("msgID", testPubKeys[0], localBlame)
+	c.Assert(vote.Sign(priKey), IsNil)
+	buf := wrapBlameVote(c, vote)
+
+	v, err := parseBlameVote(&p2p.Message{PeerID: voterID, Payload: buf}, "msgID")
+	c.Assert(err, IsNil)
+	c.Assert(v.BlameNodes, DeepEquals, []string{testPubKeys[2]})
+
+	// the vote must be for this ceremony
+	_, err = parseBlameVote(&p2p.Message{PeerID: voterID, Payload: buf}, "otherMsgID")
+	c.Assert(err, NotNil)
+	// the vote must be sent by the voter
+	_, err = parseBlameVote(&p2p.Message{PeerID: otherID, Payload: buf}, "msgID")
+	c.Assert(err, NotNil)
+	// the vote must not be altered
+	vote.BlameNodes = []string{testPubKeys[3]}
+	_, err = parseBlameVote(&p2p.Message{PeerID: voterID, Payload: wrapBlameVote(c, vote)}, "msgID")
+	c.Assert(err, NotNil)
+	_, err = parseBlameVote(&p2p.Message{PeerID: voterID, Payload: []byte("invalid")}, "msgID")
+	c.Assert(err, NotNil)
+}
+
+func (BlameAgreementTestSuite) TestVotesSettled(c *C) {
+	voters := make(map[string]bool)
+	for _, el := range testPubKeys {
+		voters[el] = true
+	}
+	newVote := func(voter string, blamed ...string) blame.Vote {
+		nodes := make([]blame.Node, len(blamed))
+		for i, el := range blamed {
+			nodes[i] = blame.Node{Pubkey: el}
+		}
+		return blame.NewVote("msgID", voter, blame.NewBlame(blame.TssTimeout, nodes))
+	}
+	votes := map[string]blame.Vote{
+		testPubKeys[0]: newVote(testPubKeys[0], testPubKeys[3]),
+	}
+	c.Assert(votesSettled(votes, voters, 2), Equals, false)
+	votes[testPubKeys[1]] = newVote(testPubKeys[1], testPubKeys[3])
+	c.Assert(votesSettled(votes, voters, 2), Equals, false)
+	votes[testPubKeys[2]] = newVote(testPubKeys[2])
+	// the missing vote is only from the accused node
+	c.Assert(votesSettled(votes, voters, 2), Equals, true)
+
+	// the accused node can not make us stop waiting for the honest vote by blaming its voter
+	votes = map[string]blame.Vote{
+		testPubKeys[0]: newVote(testPubKeys[0], testPubKeys[3]),
+		testPubKeys[1]: newVote(testPubKeys[1], testPubKeys[3]),
+		testPubKeys[3]: newVote(testPubKeys[3], testPubKeys[2]),
+	}
+	c.Assert(votesSettled(votes, voters, 2), Equals, false)
+	votes[testPubKeys[2]] = newVote(testPubKeys[2], testPubKeys[3])
+	c.Assert(votesSettled(votes, voters, 2), Equals, true)
+
+	// only the expected voters are waited for
+	c.Assert(votesSettled(votes, map[string]bool{testPubKeys[0]: true}, 2), Equals, true)
+}
@@ -0,0 +1,122 @@
+package tss
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/storage"
+)
+
+const (
+	defaultBlameHistoryWindow = 100
+	defaultMinPeerScore       = 0.5
+	// we do not judge the peer until it has participated in enough ceremonies
+	minScoredCeremonies = 5
+)
+
+// recordCeremony saves the outcome of the ceremony to the blame history and the scores of the peers
+func (t *TssServer) recordCeremony(msgID, ceremonyType string, participants []string, status common.Status, b blame.Blame, round string, duration time.Duration) {
+	// the request is rejected before the ceremony starts
+	if status == common.NA {
+		return
+	}
+	record := storage.CeremonyRecord{
+		MsgID:        msgID,
+		Type:         ceremonyType,
+		Participants: participants,
+		Success:      status == common.Success,
+		Duration:     duration,
+		Timestamp:    time.Now().UTC(),
+	}
+	if !record.Success {
+		record.FailReason = b.FailReason
+		for _, el := range b.BlameNodes {
+			record.BlameNodes = append(record.BlameNodes, el.Pubkey)
+			t.tssMetrics.UpdateBlame(el.Pubkey, b.FailReason)
+		}
+		record.Round = round
+	}
+	t.scoreBoard.Add(record)
+	if err := t.stateManager.SaveCeremonyRecord(record); err != nil {
+		t.logger.Error().Err(err).Str("msgID", msgID).Msg("fail to save the ceremony record")
+	}
+}
+
+// newPeerScoreBoard loads the blame history into the score board
+func newPeerScoreBoard(conf common.TssConfig, stateManager storage.LocalStateManager) (*storage.PeerScoreBoard, error) {
+	window := conf.BlameHistoryWindow
+	if window == 0 {
+		window = defaultBlameHistoryWindow
+	}
+	board := storage.NewPeerScoreBoard(window)
+	records, err := stateManager.GetCeremonyRecords()
+	if err != nil {
+		return board, err
+	}
+	for _, el := range records {
+		board.Add(el)
+	}
+	return board, nil
+}
+
+// GetBlameHistory returns the outcome of all the ceremonies we have participated in, the oldest first
+func (t *TssServer) GetBlameHistory() ([]storage.CeremonyRecord, error) {
+	return t.GetBlameHistoryWithContext(context.Background())
+}
+
+// GetBlameHistoryWithContext returns the blame history to the caller in ctx, who is recorded in the audit log
+func (t *TssServer) GetBlameHistoryWithContext(ctx context.Context) ([]storage.CeremonyRecord, error) {
+	if err := t.auditAdmin(ctx, audit.ActionBlameHistory, nil); err != nil {
+		return nil, err
+	}
+	records, err := t.stateManager.GetCeremonyRecords()
+	if err != nil {
+		return nil, err
+	}
+	return t.encodeCeremonyRecords(records), nil
+}
+
+// GetPeerScores returns the reliability score of the peers over their recent ceremonies
+func (t *TssServer) GetPeerScores() ([]storage.PeerScore, error) {
+	return t.GetPeerScoresWithContext(context.Background())
+}
+
+// GetPeerScoresWithContext returns the peer scores to the caller in ctx, who is recorded in the audit log
+func (t *TssServer) GetPeerScoresWithContext(ctx context.Context) ([]storage.PeerScore, error) {
+	if err := t.auditAdmin(ctx, audit.ActionPeerScores, nil); err != nil {
+		return nil, err
+	}
+	scores := t.scoreBoard.Scores()
+	for i := range scores {
+		scores[i].Pubkey = t.encodePubKey(scores[i].Pubkey)
+	}
+	return scores, nil
+}
+
+// unreliablePeers returns the peers that keep failing the ceremonies
+func (t *TssServer) unreliablePeers() []peer.ID {
+	scores := t.scoreBoard.Scores()
+	minScore := t.conf.MinPeerScore
+	if minScore == 0 {
+		minScore = defaultMinPeerScore
+	}
+	var peers []peer.ID
+	for _, el := range scores {
+		if el.Ceremonies < minScoredCeremonies || el.Score >= minScore {
+			continue
+		}
+		pID, err := conversion.GetPeerIDFromPubKey(el.Pubkey)
+		if err != nil {
+			t.logger.Error().Err(err).Msgf("fail to get the peer id of %s", el.Pubkey)
+			continue
+		}
+		peers = append(peers, pID)
+	}
+	return peers
+}
@@ -0,0 +1,211 @@
+package tss
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/monitor"
+	"github.com/joltify-finance/tss/storage"
+)
+
+const (
+	CheckP2PListening  = "p2p_listening"
+	CheckCommittee     = "committee_peers"
+	CheckStateManager  = "state_manager"
+	CheckPreParams     = "pre_params"
+	CheckCeremonies    = "ceremonies"
+	CheckAuditLog      = "audit_log"
+	stuckCeremonyRatio = 2
+)
+
+// HealthCheck is the outcome of one of the readiness checks of the node
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Readiness tells whether the node is able to serve the keygen and keysign requests, it is ready only if all the
+// checks pass
+type Readiness struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Readiness runs the readiness checks of the node
+func (t *TssServer) Readiness() Readiness {
+	checks := []HealthCheck{
+		t.checkP2PListening(),
+		t.checkCommitteePeers(),
+		t.checkStateManager(),
+		t.checkPreParams(),
+		t.checkCeremonies(),
+		t.checkAuditLog(),
+	}
+	ready := true
+	for _, el := range checks {
+		ready = ready && el.Healthy
+	}
+	return Readiness{
+		Ready:  ready,
+		Checks: checks,
+	}
+}
+
+func (t *TssServer) checkP2PListening() HealthCheck {
+	check := HealthCheck{Name: CheckP2PListening}
+	addrs := t.p2pCommunication.GetHost().Network().ListenAddresses()
+	if len(addrs) == 0 {
+		check.Detail = "p2p host is not listening"
+		return check
+	}
+	listening := make([]string, len(addrs))
+	for i, el := range addrs {
+		listening[i] = el.String()
+	}
+	check.Healthy = true
+	check.Detail = strings.Join(listening, ",")
+	return check
+}
+
+// checkCommitteePeers checks that we are connected to enough members of the committee of our active pool to sign.
+// The active pool is the newest one, the committees of the older pools may have retired after the vault rotation,
+// so they are only reported and do not fail the readiness
+func (t *TssServer) checkCommitteePeers() HealthCheck {
+	check := HealthCheck{Name: CheckCommittee}
+	h := t.p2pCommunication.GetHost()
+	healthChecker, ok := t.stateManager.(storage.HealthChecker)
+	if !ok {
+		check.Healthy = true
+		check.Detail = "the state manager does not list the pools"
+		return check
+	}
+	pools, err := healthChecker.GetPoolPubKeys()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if len(pools) == 0 {
+		check.Healthy = true
+		check.Detail = fmt.Sprintf("no pool yet, %d peers connected", len(h.Network().Peers()))
+		return check
+	}
+	active, retired := pools[0], pools[1:]
+	state, err := t.stateManager.GetLocalState(active)
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s: fail to get the local state: %v", t.encodePubKey(active), err)
+		return check
+	}
+	threshold, err := conversion.GetThreshold(len(state.ParticipantKeys))
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s: %v", t.encodePubKey(active), err)
+		return check
+	}
+	online := 0
+	for _, el := range state.ParticipantKeys {
+		pID, err := conversion.GetPeerIDFromPubKey(el)
+		if err != nil {
+			continue
+		}
+		if pID == h.ID() || h.Network().Connectedness(pID) == network.Connected {
+			online++
+		}
+	}
+	if online <= threshold {
+		check.Detail = fmt.Sprintf("%s: %d of %d members online, %d needed", t.encodePubKey(active), online, len(state.ParticipantKeys), threshold+1)
+		return check
+	}
+	check.Healthy = true
+	check.Detail = fmt.Sprintf("enough committee members online for the active pool %s", t.encodePubKey(active))
+	if len(retired) != 0 {
+		check.Detail += fmt.Sprintf(", %d older pools not checked", len(retired))
+	}
+	return check
+}
+
+func (t *TssServer) checkStateManager() HealthCheck {
+	check := HealthCheck{Name: CheckStateManager}
+	healthChecker, ok := t.stateManager.(storage.HealthChecker)
+	if !ok {
+		check.Healthy = true
+		check.Detail = "the state manager does not check its health"
+		return check
+	}
+	if err := healthChecker.CheckHealth(); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.Healthy = true
+	return check
+}
+
+func (t *TssServer) checkPreParams() HealthCheck {
+	check := HealthCheck{Name: CheckPreParams}
+	if t.preParams == nil || !t.preParams.Validate() {
+		check.Detail = "pre-parameters are not available"
+		return check
+	}
+	check.Healthy = true
+	return check
+}
+
+// checkCeremonies checks none of the running ceremonies is stuck, which runs for much longer than its timeouts allow
+func (t *TssServer) checkCeremonies() HealthCheck {
+	check := HealthCheck{Name: CheckCeremonies}
+	ceremonies := t.inflight.list()
+	var stuck []string
+	for _, el := range ceremonies {
+		expected := t.expectedDuration(el)
+		elapsed := time.Since(el.Start)
+		if expected > 0 && elapsed > stuckCeremonyRatio*expected {
+			stuck = append(stuck, fmt.Sprintf("%s %s running for %s", el.Type, el.MsgID, elapsed.Round(time.Second)))
+		}
+	}
+	if len(stuck) != 0 {
+		check.Detail = "stuck ceremonies: " + strings.Join(stuck, "; ")
+		return check
+	}
+	check.Healthy = true
+	check.Detail = fmt.Sprintf("%d ceremonies running", len(ceremonies))
+	return check
+}
+
+// expectedDuration is the longest time the ceremony should take with the timeouts we have, the join party fails
+// over to the next leader after each party timeout
+func (t *TssServer) expectedDuration(ceremony inflightCeremony) time.Duration {
+	attempts := ceremony.JoinAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	expected := time.Duration(attempts) * t.conf.PartyTimeout
+	if ceremony.Type == monitor.CeremonyKeygen {
+		expected += t.conf.KeyGenTimeout
+	} else {
+		expected += t.conf.KeySignTimeout
+	}
+	if t.conf.EnableBlameAgreement {
+		expected += t.conf.BlameAgreementTimeout
+	}
+	return expected
+}
+
+// checkAuditLog checks the last write to the audit log succeeded, the node that fails to record the outcome of the
+// requests it served should not take more of them
+func (t *TssServer) checkAuditLog() HealthCheck {
+	check := HealthCheck{Name: CheckAuditLog}
+	if t.auditLog == nil {
+		check.Healthy = true
+		check.Detail = "the audit log is disabled"
+		return check
+	}
+	if err := t.auditLog.Failure(); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.Healthy = true
+	return check
+}
@@ -0,0 +1,106 @@
+package tss
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/monitor"
+	"github.com/joltify-finance/tss/storage"
+)
+
+type HealthTestSuite struct{}
+
+var _ = Suite(&HealthTestSuite{})
+
+func (HealthTestSuite) TestCheckCeremonies(c *C) {
+	server := &TssServer{
+		conf: common.TssConfig{
+			PartyTimeout:   time.Second,
+			KeyGenTimeout:  time.Minute,
+			KeySignTimeout: time.Second,
+		},
+		inflight: newInflightCeremonies(),
+	}
+	check := server.checkCeremonies()
+	c.Assert(check.Healthy, Equals, true)
+	c.Assert(check.Detail, Equals, "0 ceremonies running")
+
+	server.inflight.start("keygenMsgID", monitor.CeremonyKeygen)
+	server.inflight.start("keysignMsgID", monitor.CeremonyKeysign)
+	server.inflight.ceremonies["keysignMsgID"] = inflightCeremony{
+		MsgID: "keysignMsgID",
+		Type:  monitor.CeremonyKeysign,
+		Start: time.Now().Add(-5 * time.Second),
+	}
+	c.Assert(server.inflight.list()[0].MsgID, Equals, "keysignMsgID")
+	check = server.checkCeremonies()
+	c.Assert(check.Healthy, Equals, false)
+	c.Assert(check.Detail, Equals, "stuck ceremonies: keysign keysignMsgID running for 5s")
+
+	// the keysign that may fail over to two more leaders is given their party timeouts
+	server.inflight.setJoinAttempts("keysignMsgID", 3)
+	c.Assert(server.checkCeremonies().Healthy, Equals, true)
+
+	server.inflight.finish("keysignMsgID")
+	c.Assert(server.checkCeremonies().Healthy, Equals, true)
+}
+
+func (HealthTestSuite) TestCheckStateManagerSkipped(c *C) {
+	server := &TssServer{stateManager: &storage.MockLocalStateManager{}}
+	check := server.checkStateManager()
+	c.Assert(check.Healthy, Equals, true)
+	c.Assert(check.Detail, Equals, "the state manager does not check its health")
+}
+
+func (HealthTestSuite) TestCheckAuditLog(c *C) {
+	server := &TssServer{}
+	c.Assert(server.checkAuditLog().Healthy, Equals, true)
+
+	conversion.SetupBech32Prefix()
+	codec, err := conversion.NewPubKeyCodec(conversion.PubKeyEncodingBech32, "")
+	c.Assert(err, IsNil)
+	folder := c.MkDir()
+	auditLog, err := audit.NewLog(folder, audit.Config{})
+	c.Assert(err, IsNil)
+	server = &TssServer{auditLog: auditLog, pubKeyCodec: codec}
+	ctx := audit.WithCaller(context.Background(), audit.Caller{Identity: "operator"})
+	poolPubKey := conversion.GetRandomPubKey()
+	_, err = server.GetAddressesWithContext(ctx, poolPubKey)
+	c.Assert(err, IsNil)
+	c.Assert(server.checkAuditLog().Healthy, Equals, true)
+
+	// the admin query is recorded along with its caller
+	files, err := filepath.Glob(filepath.Join(folder, "audit-*.log"))
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+	f, err := os.Open(files[0])
+	c.Assert(err, IsNil)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	c.Assert(scanner.Scan(), Equals, true)
+	var entry audit.Entry
+	c.Assert(json.Unmarshal(scanner.Bytes(), &entry), IsNil)
+	c.Assert(entry.Action, Equals, audit.ActionAddresses)
+	c.Assert(entry.Caller.Identity, Equals, "operator")
+	c.Assert(string(entry.Request), Equals, `{"pool_pub_key":"`+poolPubKey+`"}`)
+
+	// the outcome that can not be written does not fail the finished request, it fails the readiness
+	c.Assert(auditLog.Close(), IsNil)
+	server.finishAudit(audit.NewEntry(ctx, audit.ActionKeysign, nil), common.Success, blame.Blame{}, nil, nil)
+	check := server.checkAuditLog()
+	c.Assert(check.Healthy, Equals, false)
+	c.Assert(check.Detail, Equals, "audit log is closed")
+	// while the admin query the node can not account for is refused
+	_, err = server.GetAddressesWithContext(ctx, poolPubKey)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,102 @@
+package tss
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflightCeremony is the ceremony the node is running
+type inflightCeremony struct {
+	MsgID      string
+	Type       string
+	Start      time.Time
+	Phase      string
+	PhaseStart time.Time
+	// JoinAttempts is the number of the leaders the join party may try
+	JoinAttempts int
+}
+
+// inflightCeremonies keeps the ceremonies the node is running, keyed by the msgID. All the methods are no-op on the
+// nil instance
+type inflightCeremonies struct {
+	lock       *sync.Mutex
+	ceremonies map[string]inflightCeremony
+}
+
+func newInflightCeremonies() *inflightCeremonies {
+	return &inflightCeremonies{
+		lock:       &sync.Mutex{},
+		ceremonies: make(map[string]inflightCeremony),
+	}
+}
+
+func (c *inflightCeremonies) start(msgID, ceremonyType string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ceremonies[msgID] = inflightCeremony{
+		MsgID: msgID,
+		Type:  ceremonyType,
+		Start: time.Now(),
+	}
+}
+
+// setPhase records the phase the ceremony enters, the phases of the ceremonies not started are ignored
+func (c *inflightCeremonies) setPhase(msgID, phase string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ceremony, ok := c.ceremonies[msgID]
+	if !ok {
+		return
+	}
+	ceremony.Phase = phase
+	ceremony.PhaseStart = time.Now()
+	c.ceremonies[msgID] = ceremony
+}
+
+// setJoinAttempts records the number of the leaders the join party of the ceremony may try
+func (c *inflightCeremonies) setJoinAttempts(msgID string, attempts int) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ceremony, ok := c.ceremonies[msgID]
+	if !ok {
+		return
+	}
+	ceremony.JoinAttempts = attempts
+	c.ceremonies[msgID] = ceremony
+}
+
+func (c *inflightCeremonies) finish(msgID string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.ceremonies, msgID)
+}
+
+// list returns the running ceremonies, the oldest first
+func (c *inflightCeremonies) list() []inflightCeremony {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	ceremonies := make([]inflightCeremony, 0, len(c.ceremonies))
+	for _, el := range c.ceremonies {
+		ceremonies = append(ceremonies, el)
+	}
+	c.lock.Unlock()
+	sort.Slice(ceremonies, func(i, j int) bool {
+		return ceremonies[i].Start.Before(ceremonies[j].Start)
+	})
+	return ceremonies
+}
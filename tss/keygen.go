@@ -1,24 +1,92 @@
 package tss
 
 import (
+	"context"
 	"time"
 
+	"github.com/joltify-finance/tss/audit"
 	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/common"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keygen"
 	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
+	"github.com/joltify-finance/tss/tracing"
 )
 
 func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
+	return t.KeygenWithContext(context.Background(), req)
+}
+
+// KeygenWithContext runs the keygen in the trace of the span in ctx. The request that can not be written to the
+// audit log is rejected, while the failure to write the outcome does not fail the finished keygen, it fails the
+// audit log readiness check instead
+func (t *TssServer) KeygenWithContext(ctx context.Context, req keygen.Request) (keygen.Response, error) {
+	entry := audit.NewEntry(ctx, audit.ActionKeygen, req)
+	resp, err := t.runKeygen(ctx, req, entry)
+	t.finishAudit(entry, resp.Status, resp.Blame, keygenResult{PubKey: resp.PubKey, PoolAddress: resp.PoolAddress}, err)
+	return resp, err
+}
+
+// keygenResult is what the keygen returns to the caller in the audit log
+type keygenResult struct {
+	PubKey      string `json:"pub_key"`
+	PoolAddress string `json:"pool_address"`
+}
+
+func (t *TssServer) runKeygen(ctx context.Context, req keygen.Request, entry *audit.Entry) (keygen.Response, error) {
 	t.tssKeyGenLocker.Lock()
 	defer t.tssKeyGenLocker.Unlock()
-	status := common.Success
+	req, err := normalizeKeygenRequest(req)
+	if err != nil {
+		return keygen.Response{}, err
+	}
 	msgID, err := t.requestToMsgId(req)
 	if err != nil {
 		return keygen.Response{}, err
 	}
+	entry.MsgID = msgID
+	entry.Participants = req.Keys
+	if err := t.beginAudit(entry); err != nil {
+		return keygen.Response{}, err
+	}
+	t.startTranscript(msgID)
+	defer t.finishTranscript(msgID)
+	t.tssMetrics.CeremonyStarted(monitor.CeremonyKeygen)
+	defer t.tssMetrics.CeremonyFinished(monitor.CeremonyKeygen)
+	t.inflight.start(msgID, monitor.CeremonyKeygen)
+	defer t.inflight.finish(msgID)
+	span := t.startTrace(ctx, "tss.keygen", msgID)
+	startTime := time.Now()
+	resp, err := t.keygenWithBlameAgreement(msgID, req)
+	t.recordCeremony(msgID, "keygen", req.Keys, resp.Status, resp.Blame, resp.Round, time.Since(startTime))
+	span.SetAttributes(tracing.PoolPubKeyKey.String(resp.PubKey))
+	t.finishTrace(msgID, span, resp.Status, resp.Blame, err)
+	return t.encodeKeygenResponse(resp), err
+}
+
+func (t *TssServer) keygenWithBlameAgreement(msgID string, req keygen.Request) (keygen.Response, error) {
+	if !t.conf.EnableBlameAgreement {
+		return t.generateNewKey(msgID, req)
+	}
+
+	// we subscribe the votes before the keygen, as the others may fail earlier than us
+	voteChan := t.subscribeBlameVotes(msgID, len(req.Keys))
+	defer t.cancelBlameVotes(msgID)
+	resp, err := t.generateNewKey(msgID, req)
+	if resp.Status == common.Fail {
+		threshold, errThreshold := conversion.GetThreshold(len(req.Keys))
+		if errThreshold != nil {
+			t.logger.Error().Err(errThreshold).Msg("fail to get the threshold for blame agreement")
+			return resp, err
+		}
+		resp.Blame, resp.BlameVotes = t.blameAgreement(msgID, req.Keys, threshold, resp.Blame, voteChan)
+	}
+	return resp, err
+}
 
+func (t *TssServer) generateNewKey(msgID string, req keygen.Request) (keygen.Response, error) {
+	status := common.Success
 	keygenInstance := keygen.NewTssKeyGen(
 		t.p2pCommunication.GetLocalPeerID(),
 		t.conf,
@@ -30,6 +98,9 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 		t.stateManager,
 		t.privateKey,
 		t.p2pCommunication)
+	keygenInstance.GetTssCommonStruct().SetRecorder(t.recorder)
+	keygenInstance.GetTssCommonStruct().SetMetrics(t.tssMetrics)
+	keygenInstance.GetTssCommonStruct().SetTraceContext(t.ceremonies.Context(msgID))
 
 	keygenMsgChannel := keygenInstance.GetTssKeyGenChannels()
 	t.p2pCommunication.SetSubscribe(messages.TSSKeyGenMsg, msgID, keygenMsgChannel)
@@ -49,15 +120,15 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 	sigChan := make(chan string)
 	blameMgr := keygenInstance.GetTssCommonStruct().GetBlameMgr()
 	joinPartyStartTime := time.Now()
-	onlinePeers, leader, errJoinParty := t.joinParty(msgID, req.Version, req.BlockHeight, req.Keys, len(req.Keys)-1, sigChan)
+	onlinePeers, leader, failedLeaders, errJoinParty := t.joinParty(msgID, req.Version, req.BlockHeight, req.Keys, len(req.Keys)-1, nil, sigChan)
 	joinPartyTime := time.Since(joinPartyStartTime)
 	if errJoinParty != nil {
 		t.tssMetrics.KeygenJoinParty(joinPartyTime, false)
-		t.tssMetrics.UpdateKeyGen(0, false)
+		t.tssMetrics.UpdateKeyGen("", 0, false)
 		// this indicate we are processing the leaderless join party
 		if leader == "NONE" {
 			if onlinePeers == nil {
-				t.logger.Error().Err(err).Msg("error before we start join party")
+				t.logger.Error().Err(errJoinParty).Msg("error before we start join party")
 				return keygen.Response{
 					Status: common.Fail,
 					Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
@@ -76,19 +147,12 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 
 		}
 
-		var blameLeader blame.Blame
-		var blameNodes blame.Blame
-		blameNodes, err = blameMgr.NodeSyncBlame(req.Keys, onlinePeers)
+		blameNodes, err := blameMgr.NodeSyncBlame(req.Keys, onlinePeers)
 		if err != nil {
 			t.logger.Err(errJoinParty).Msg("fail to get peers to blame")
 		}
-		leaderPubKey, err := conversion.GetPubKeyFromPeerID(leader)
-		if err != nil {
-			t.logger.Error().Err(errJoinParty).Msgf("fail to convert the peerID to public key with leader %s", leader)
-			blameLeader = blame.NewBlame(blame.TssSyncFail, []blame.Node{})
-		} else {
-			blameLeader = blame.NewBlame(blame.TssSyncFail, []blame.Node{{leaderPubKey, nil, nil}})
-		}
+		// we blame the leader as well as all the leaders we failed over from
+		blameLeader := blame.NewBlame(blame.TssSyncFail, t.leaderBlameNodes(leader, failedLeaders))
 		if len(onlinePeers) != 0 {
 			blameNodes.AddBlameNodes(blameLeader.BlameNodes...)
 		} else {
@@ -105,19 +169,27 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 
 	t.tssMetrics.KeygenJoinParty(joinPartyTime, true)
 	t.logger.Debug().Msg("keygen party formed")
+	failedLeaderPubKeys := t.leaderPubKeys(failedLeaders)
+	if len(failedLeaderPubKeys) != 0 {
+		t.logger.Warn().Msgf("keygen party formed after failing over from the leaders %v", failedLeaders)
+	}
 	// the statistic of keygen only care about Tss it self, even if the
 	// following http response aborts, it still counted as a successful keygen
 	// as the Tss model runs successfully.
+	_, span := t.startPhase(msgID, "tss.keygen.generate")
 	beforeKeygen := time.Now()
 	k, err := keygenInstance.GenerateNewKey(req)
 	keygenTime := time.Since(beforeKeygen)
+	tracing.End(span, err)
 	if err != nil {
-		t.tssMetrics.UpdateKeyGen(keygenTime, false)
+		t.tssMetrics.UpdateKeyGen("", keygenTime, false)
 		t.logger.Error().Err(err).Msg("err in keygen")
 		blameNodes := *blameMgr.GetBlame()
-		return keygen.NewResponse("", "", common.Fail, blameNodes), err
-	} else {
-		t.tssMetrics.UpdateKeyGen(keygenTime, true)
+		resp := keygen.NewResponse("", "", common.Fail, blameNodes)
+		resp.Evidence = blameMgr.GetEvidences()
+		resp.Round = blameMgr.GetFailedRound()
+		resp.FailedLeaders = failedLeaderPubKeys
+		return resp, err
 	}
 
 	newPubKey, addr, err := conversion.GetTssPubKey(k)
@@ -125,12 +197,31 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 		t.logger.Error().Err(err).Msg("fail to generate the new Tss key")
 		status = common.Fail
 	}
+	t.tssMetrics.UpdateKeyGen(newPubKey, keygenTime, true)
+
+	poolAddress, err := t.pubKeyCodec.EncodeAddress(addr)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to encode the pool address")
+		status = common.Fail
+	}
 
 	blameNodes := *blameMgr.GetBlame()
-	return keygen.NewResponse(
+	resp := keygen.NewResponse(
 		newPubKey,
-		addr.String(),
+		poolAddress,
 		status,
 		blameNodes,
-	), nil
+	)
+	// the evidence of the equivocation is kept even if the keygen succeeds with the message of the majority
+	if evidences := blameMgr.GetEvidences(); len(evidences) != 0 {
+		resp.Evidence = evidences
+	}
+	resp.FailedLeaders = failedLeaderPubKeys
+	if status == common.Success {
+		resp.Addresses, err = t.pubKeyCodec.GetAddresses(newPubKey)
+		if err != nil {
+			t.logger.Error().Err(err).Msg("fail to derive the addresses of the new Tss key")
+		}
+	}
+	return resp, nil
 }
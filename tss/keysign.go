@@ -1,9 +1,11 @@
 package tss
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/big"
 	"sort"
 	"strings"
 	"sync"
@@ -12,18 +14,23 @@ import (
 	tsslibcommon "github.com/binance-chain/tss-lib/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/joltify-finance/tss/audit"
 	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/common"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keysign"
 	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
 	"github.com/joltify-finance/tss/p2p"
 	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
 )
 
 func (t *TssServer) waitForSignatures(msgID, poolPubKey string, msgsToSign [][]byte, sigChan chan string) (keysign.Response, error) {
+	_, span := t.startPhase(msgID, "tss.wait_signature")
 	// TSS keysign include both form party and keysign itself, thus we wait twice of the timeout
 	data, err := t.signatureNotifier.WaitForSignature(msgID, msgsToSign, poolPubKey, t.conf.KeySignTimeout, sigChan)
+	tracing.End(span, err)
 	if err != nil {
 		return keysign.Response{}, err
 	}
@@ -35,14 +42,15 @@ func (t *TssServer) waitForSignatures(msgID, poolPubKey string, msgsToSign [][]b
 	return t.batchSignatures(data, msgsToSign), nil
 }
 
-func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req keysign.Request, threshold int, allParticipants []string, localStateItem storage.KeygenLocalState, blameMgr *blame.Manager, keysignInstance *keysign.TssKeySign, sigChan chan string) (keysign.Response, error) {
+// generateSignature joins the party and signs the messages, it also returns the signers of the party once it is formed
+func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req keysign.Request, threshold int, allParticipants []string, localStateItem storage.KeygenLocalState, blameMgr *blame.Manager, keysignInstance keysign.Backend, sigChan chan string) (keysign.Response, []string, error) {
 	allPeersID, err := conversion.GetPeerIDsFromPubKeys(allParticipants)
 	if err != nil {
 		t.logger.Error().Msg("invalid block height or public key")
 		return keysign.Response{
 			Status: common.Fail,
 			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
-		}, nil
+		}, nil, nil
 	}
 
 	oldJoinParty, err := conversion.VersionLTCheck(req.Version, messages.NEWJOINPARTYVERSION)
@@ -50,7 +58,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		return keysign.Response{
 			Status: common.Fail,
 			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
-		}, errors.New("fail to parse the version")
+		}, nil, errors.New("fail to parse the version")
 	}
 	// we use the old join party
 	if oldJoinParty {
@@ -58,7 +66,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		myPk, err := conversion.GetPubKeyFromPeerID(t.p2pCommunication.GetHost().ID().String())
 		if err != nil {
 			t.logger.Info().Msgf("fail to convert the p2p id(%s) to pubkey, turn to wait for signature", t.p2pCommunication.GetHost().ID().String())
-			return keysign.Response{}, p2p.ErrNotActiveSigner
+			return keysign.Response{}, nil, p2p.ErrNotActiveSigner
 		}
 		isSignMember := false
 		for _, el := range allParticipants {
@@ -69,20 +77,28 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		}
 		if !isSignMember {
 			t.logger.Info().Msgf("we(%s) are not the active signer", t.p2pCommunication.GetHost().ID().String())
-			return keysign.Response{}, p2p.ErrNotActiveSigner
+			return keysign.Response{}, nil, p2p.ErrNotActiveSigner
 		}
 
 	}
 
+	// the signers agree on the presignatures in the join party, so that either all of them finish the keysign
+	// with the same presignatures or all of them run the full keysign
+	presignBackend, canPresign := keysignInstance.(keysign.PresignBackend)
+	var presign *p2p.PresignAgreement
+	if canPresign {
+		presign = t.presignAgreement(msgID, req.PoolPubKey, len(msgsToSign))
+		defer t.presignLeases.release(msgID)
+	}
 	joinPartyStartTime := time.Now()
-	onlinePeers, leader, errJoinParty := t.joinParty(msgID, req.Version, req.BlockHeight, allParticipants, threshold, sigChan)
+	onlinePeers, leader, failedLeaders, errJoinParty := t.joinParty(msgID, req.Version, req.BlockHeight, allParticipants, threshold, presign, sigChan)
 	joinPartyTime := time.Since(joinPartyStartTime)
 	if errJoinParty != nil {
 		// we received the signature from waiting for signature
 		if errors.Is(errJoinParty, p2p.ErrSignReceived) {
-			return keysign.Response{}, errJoinParty
+			return keysign.Response{}, nil, errJoinParty
 		}
-		t.tssMetrics.KeysignJoinParty(joinPartyTime, false)
+		t.tssMetrics.KeysignJoinParty(req.PoolPubKey, joinPartyTime, false)
 		// this indicate we are processing the leaderness join party
 		if leader == "NONE" {
 			if onlinePeers == nil {
@@ -91,7 +107,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 				return keysign.Response{
 					Status: common.Fail,
 					Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
-				}, nil
+				}, nil, nil
 			}
 
 			blameNodes, err := blameMgr.NodeSyncBlame(req.SignerPubKeys, onlinePeers)
@@ -104,17 +120,11 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 			return keysign.Response{
 				Status: common.Fail,
 				Blame:  blameNodes,
-			}, nil
+			}, nil, nil
 		}
 
-		var blameLeader blame.Blame
-		leaderPubKey, err := conversion.GetPubKeyFromPeerID(leader)
-		if err != nil {
-			t.logger.Error().Err(errJoinParty).Msgf("fail to convert the peerID to public key %s", leader)
-			blameLeader = blame.NewBlame(blame.TssSyncFail, []blame.Node{})
-		} else {
-			blameLeader = blame.NewBlame(blame.TssSyncFail, []blame.Node{{leaderPubKey, nil, nil}})
-		}
+		// we blame the leader as well as all the leaders we failed over from
+		blameLeader := blame.NewBlame(blame.TssSyncFail, t.leaderBlameNodes(leader, failedLeaders))
 
 		t.broadcastKeysignFailure(msgID, allPeersID)
 		// make sure we blame the leader as well
@@ -122,10 +132,14 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		return keysign.Response{
 			Status: common.Fail,
 			Blame:  blameLeader,
-		}, nil
+		}, nil, nil
 
 	}
-	t.tssMetrics.KeysignJoinParty(joinPartyTime, true)
+	t.tssMetrics.KeysignJoinParty(req.PoolPubKey, joinPartyTime, true)
+	failedLeaderPubKeys := t.leaderPubKeys(failedLeaders)
+	if len(failedLeaderPubKeys) != 0 {
+		t.logger.Warn().Msgf("keysign party formed after failing over from the leaders %v", failedLeaders)
+	}
 	isKeySignMember := false
 	for _, el := range onlinePeers {
 		if el == t.p2pCommunication.GetHost().ID() {
@@ -135,7 +149,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 	if !isKeySignMember {
 		// we are not the keysign member so we quit keysign and waiting for signature
 		t.logger.Info().Msgf("we(%s) are not the active signer", t.p2pCommunication.GetHost().ID().String())
-		return keysign.Response{}, p2p.ErrNotActiveSigner
+		return keysign.Response{}, nil, p2p.ErrNotActiveSigner
 	}
 	parsedPeers := make([]string, len(onlinePeers))
 	for i, el := range onlinePeers {
@@ -148,9 +162,15 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		return keysign.Response{
 			Status: common.Fail,
 			Blame:  blame.Blame{},
-		}, nil
+		}, nil, nil
 	}
+	if canPresign {
+		t.presignLeases.keep(msgID, presign.Picked())
+		presignBackend.SetPresignatures(presign.Picked())
+	}
+	_, span := t.startPhase(msgID, "tss.keysign.sign")
 	signatureData, err := keysignInstance.SignMessage(msgsToSign, localStateItem, signers)
+	tracing.End(span, err)
 	// the statistic of keygen only care about Tss it self, even if the following http response aborts,
 	// it still counted as a successful keygen as the Tss model runs successfully.
 	if err != nil {
@@ -159,41 +179,149 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		t.broadcastKeysignFailure(msgID, allPeersID)
 		blameNodes := *blameMgr.GetBlame()
 		return keysign.Response{
-			Status: common.Fail,
-			Blame:  blameNodes,
-		}, nil
+			Status:        common.Fail,
+			Blame:         blameNodes,
+			Evidence:      blameMgr.GetEvidences(),
+			Round:         blameMgr.GetFailedRound(),
+			FailedLeaders: failedLeaderPubKeys,
+		}, signers, nil
 	}
 
 	sigChan <- "signature generated"
 	// update signature notification
-	if err := t.signatureNotifier.BroadcastSignature(msgID, signatureData, allPeersID); err != nil {
-		return keysign.Response{}, fmt.Errorf("fail to broadcast signature:%w", err)
+	_, span = t.startPhase(msgID, "tss.broadcast_signature")
+	err = t.signatureNotifier.BroadcastSignature(msgID, signatureData, allPeersID)
+	tracing.End(span, err)
+	if err != nil {
+		return keysign.Response{}, nil, fmt.Errorf("fail to broadcast signature:%w", err)
 	}
 
-	return t.batchSignatures(signatureData, msgsToSign), nil
+	resp := t.batchSignatures(signatureData, msgsToSign)
+	if evidences := blameMgr.GetEvidences(); len(evidences) != 0 {
+		resp.Evidence = evidences
+	}
+	resp.FailedLeaders = failedLeaderPubKeys
+	return resp, signers, nil
 }
 
-func (t *TssServer) updateKeySignResult(result keysign.Response, timeSpent time.Duration) {
+func (t *TssServer) updateKeySignResult(poolPubKey string, result keysign.Response, timeSpent time.Duration) {
 	if result.Status == common.Success {
-		t.tssMetrics.UpdateKeySign(timeSpent, true)
+		t.tssMetrics.UpdateKeySign(poolPubKey, timeSpent, true)
 		return
 	}
-	t.tssMetrics.UpdateKeySign(timeSpent, false)
+	t.tssMetrics.UpdateKeySign(poolPubKey, timeSpent, false)
 	return
 }
 
 func (t *TssServer) KeySign(req keysign.Request) (keysign.Response, error) {
+	return t.KeySignWithContext(context.Background(), req)
+}
+
+// KeySignWithContext runs the keysign in the trace of the span in ctx. The request that can not be written to the
+// audit log is rejected, while the failure to write the outcome does not fail the keysign whose signatures exist,
+// it fails the audit log readiness check instead
+func (t *TssServer) KeySignWithContext(ctx context.Context, req keysign.Request) (keysign.Response, error) {
+	entry := audit.NewEntry(ctx, audit.ActionKeysign, req)
+	resp, err := t.runKeySign(ctx, req, entry)
+	t.finishAudit(entry, resp.Status, resp.Blame, resp.Signatures, err)
+	return resp, err
+}
+
+func (t *TssServer) runKeySign(ctx context.Context, req keysign.Request, entry *audit.Entry) (keysign.Response, error) {
 	t.logger.Info().Str("pool pub key", req.PoolPubKey).
 		Str("signer pub keys", strings.Join(req.SignerPubKeys, ",")).
 		Str("msg", strings.Join(req.Messages, ",")).
 		Msg("received keysign request")
-	emptyResp := keysign.Response{}
+	req, err := normalizeKeysignRequest(req)
+	if err != nil {
+		return keysign.Response{}, err
+	}
+	// the parties agree on the message id of the hashes, so we hash the messages first
+	req, err = keysign.ApplyHashModes(req)
+	if err != nil {
+		return keysign.Response{}, err
+	}
+	if err := keysign.ValidateOutputFormats(req.OutputFormats); err != nil {
+		return keysign.Response{}, err
+	}
+	if err := keysign.ValidateEthereumChainID(req.ChainID); err != nil {
+		return keysign.Response{}, err
+	}
 	msgID, err := t.requestToMsgId(req)
 	if err != nil {
-		return emptyResp, err
+		return keysign.Response{}, err
 	}
+	localStateItem, err := t.stateManager.GetLocalState(req.PoolPubKey)
+	if err != nil {
+		return keysign.Response{}, fmt.Errorf("fail to get local keygen state: %w", err)
+	}
+	participants := req.SignerPubKeys
+	if len(participants) == 0 {
+		participants = localStateItem.ParticipantKeys
+	}
+	entry.MsgID = msgID
+	entry.Participants = participants
+	if err := t.beginAudit(entry); err != nil {
+		return keysign.Response{}, err
+	}
+	t.startTranscript(msgID)
+	defer t.finishTranscript(msgID)
+	t.tssMetrics.CeremonyStarted(monitor.CeremonyKeysign)
+	defer t.tssMetrics.CeremonyFinished(monitor.CeremonyKeysign)
+	t.inflight.start(msgID, monitor.CeremonyKeysign)
+	defer t.inflight.finish(msgID)
+	span := t.startTrace(ctx, "tss.keysign", msgID, tracing.PoolPubKeyKey.String(req.PoolPubKey))
+	startTime := time.Now()
+	resp, err := t.keysignWithBlameAgreement(msgID, req, participants, len(localStateItem.ParticipantKeys))
+	t.recordCeremony(msgID, "keysign", participants, resp.Status, resp.Blame, resp.Round, time.Since(startTime))
+	t.finishTrace(msgID, span, resp.Status, resp.Blame, err)
+	t.updatePresignPoolDepth(req.PoolPubKey)
+	if resp.Status == common.Success {
+		if errFormat := keysign.ApplyOutputFormats(resp.Signatures, req); errFormat != nil {
+			return t.encodeKeysignResponse(resp), fmt.Errorf("fail to format the signatures: %w", errFormat)
+		}
+	}
+	return t.encodeKeysignResponse(resp), err
+}
+
+func (t *TssServer) keysignWithBlameAgreement(msgID string, req keysign.Request, participants []string, partyNum int) (keysign.Response, error) {
+	if !t.conf.EnableBlameAgreement {
+		resp, _, err := t.signMessages(msgID, req)
+		return resp, err
+	}
+
+	// we subscribe the votes before the keysign, as the others may fail earlier than us
+	voteChan := t.subscribeBlameVotes(msgID, len(participants))
+	defer t.cancelBlameVotes(msgID)
+	resp, signers, err := t.signMessages(msgID, req)
+	if resp.Status == common.Fail {
+		threshold, errThreshold := conversion.GetThreshold(partyNum)
+		if errThreshold != nil {
+			t.logger.Error().Err(errThreshold).Msg("fail to get the threshold for blame agreement")
+			return resp, err
+		}
+		// only the signers of the formed party fail the keysign, the others wait for the signature and do not vote
+		voters := participants
+		if len(signers) != 0 {
+			voters = signers
+		}
+		resp.Blame, resp.BlameVotes = t.blameAgreement(msgID, voters, threshold, resp.Blame, voteChan)
+	}
+	return resp, err
+}
 
-	keysignInstance := keysign.NewTssKeySign(
+// signMessages runs the keysign, it also returns the signers of the party if we are one of them
+func (t *TssServer) signMessages(msgID string, req keysign.Request) (keysign.Response, []string, error) {
+	emptyResp := keysign.Response{}
+	protocol, err := keysign.ProtocolFromVersion(req.Version)
+	if err != nil {
+		return keysign.Response{
+			Status: common.Fail,
+			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
+		}, nil, err
+	}
+	keysignInstance, err := keysign.NewBackend(
+		protocol,
 		t.p2pCommunication.GetLocalPeerID(),
 		t.conf,
 		t.p2pCommunication.BroadcastMsgChan,
@@ -205,18 +333,26 @@ func (t *TssServer) KeySign(req keysign.Request) (keysign.Response, error) {
 		//since we have 4 message type
 		len(req.Messages),
 	)
+	if err != nil {
+		return emptyResp, nil, err
+	}
+	keysignInstance.GetTssCommonStruct().SetRecorder(t.recorder)
+	keysignInstance.GetTssCommonStruct().SetMetrics(t.tssMetrics)
+	keysignInstance.GetTssCommonStruct().SetTraceContext(t.ceremonies.Context(msgID))
 
 	keySignChannels := keysignInstance.GetTssKeySignChannels()
 	t.p2pCommunication.SetSubscribe(messages.TSSKeySignMsg, msgID, keySignChannels)
 	t.p2pCommunication.SetSubscribe(messages.TSSKeySignVerMsg, msgID, keySignChannels)
 	t.p2pCommunication.SetSubscribe(messages.TSSControlMsg, msgID, keySignChannels)
 	t.p2pCommunication.SetSubscribe(messages.TSSTaskDone, msgID, keySignChannels)
+	t.p2pCommunication.SetSubscribe(messages.TSSPresignShare, msgID, keySignChannels)
 
 	defer func() {
 		t.p2pCommunication.CancelSubscribe(messages.TSSKeySignMsg, msgID)
 		t.p2pCommunication.CancelSubscribe(messages.TSSKeySignVerMsg, msgID)
 		t.p2pCommunication.CancelSubscribe(messages.TSSControlMsg, msgID)
 		t.p2pCommunication.CancelSubscribe(messages.TSSTaskDone, msgID)
+		t.p2pCommunication.CancelSubscribe(messages.TSSPresignShare, msgID)
 
 		t.p2pCommunication.ReleaseStream(msgID)
 		t.signatureNotifier.ReleaseStream(msgID)
@@ -225,59 +361,62 @@ func (t *TssServer) KeySign(req keysign.Request) (keysign.Response, error) {
 
 	localStateItem, err := t.stateManager.GetLocalState(req.PoolPubKey)
 	if err != nil {
-		return emptyResp, fmt.Errorf("fail to get local keygen state: %w", err)
+		return emptyResp, nil, fmt.Errorf("fail to get local keygen state: %w", err)
 	}
 
-	var msgsToSign [][]byte
+	// the messages are converted before the sort, so that the one we can not sign fails the request
+	type hashedMsg struct {
+		msg     []byte
+		hashInt *big.Int
+	}
+	var hashedMsgs []hashedMsg
 	for _, val := range req.Messages {
 		msgToSign, err := base64.StdEncoding.DecodeString(val)
 		if err != nil {
-			return keysign.Response{}, fmt.Errorf("fail to decode message(%s): %w", strings.Join(req.Messages, ","), err)
-		}
-		msgsToSign = append(msgsToSign, msgToSign)
-	}
-
-	sort.SliceStable(msgsToSign, func(i, j int) bool {
-		ma, err := common.MsgToHashInt(msgsToSign[i])
-		if err != nil {
-			t.logger.Error().Err(err).Msgf("fail to convert the hash value")
+			return keysign.Response{}, nil, fmt.Errorf("fail to decode message(%s): %w", strings.Join(req.Messages, ","), err)
 		}
-		mb, err := common.MsgToHashInt(msgsToSign[j])
+		hashInt, err := common.MsgToHashInt(msgToSign)
 		if err != nil {
-			t.logger.Error().Err(err).Msgf("fail to convert the hash value")
-		}
-		if ma.Cmp(mb) == -1 {
-			return false
+			return keysign.Response{}, nil, fmt.Errorf("fail to convert the message(%s) to hash int: %w", val, err)
 		}
-		return true
+		hashedMsgs = append(hashedMsgs, hashedMsg{msg: msgToSign, hashInt: hashInt})
+	}
+
+	sort.SliceStable(hashedMsgs, func(i, j int) bool {
+		return hashedMsgs[i].hashInt.Cmp(hashedMsgs[j].hashInt) != -1
 	})
+	msgsToSign := make([][]byte, len(hashedMsgs))
+	for i, el := range hashedMsgs {
+		msgsToSign[i] = el.msg
+	}
 
 	oldJoinParty, err := conversion.VersionLTCheck(req.Version, messages.NEWJOINPARTYVERSION)
 	if err != nil {
 		return keysign.Response{
 			Status: common.Fail,
 			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
-		}, errors.New("fail to parse the version")
+		}, nil, errors.New("fail to parse the version")
 	}
 
 	if len(req.SignerPubKeys) == 0 && oldJoinParty {
-		return emptyResp, errors.New("empty signer pub keys")
+		return emptyResp, nil, errors.New("empty signer pub keys")
 	}
 
 	threshold, err := conversion.GetThreshold(len(localStateItem.ParticipantKeys))
 	if err != nil {
 		t.logger.Error().Err(err).Msg("fail to get the threshold")
-		return emptyResp, errors.New("fail to get threshold")
+		return emptyResp, nil, errors.New("fail to get threshold")
 	}
 	if len(req.SignerPubKeys) <= threshold && oldJoinParty {
 		t.logger.Error().Msgf("not enough signers, threshold=%d and signers=%d", threshold, len(req.SignerPubKeys))
-		return emptyResp, errors.New("not enough signers")
+		return emptyResp, nil, errors.New("not enough signers")
 	}
 
 	blameMgr := keysignInstance.GetTssCommonStruct().GetBlameMgr()
 
 	var receivedSig, generatedSig keysign.Response
 	var errWait, errGen error
+	var signers []string
 	sigChan := make(chan string, 2)
 	wg := sync.WaitGroup{}
 	wg.Add(2)
@@ -298,24 +437,24 @@ func (t *TssServer) KeySign(req keysign.Request) (keysign.Response, error) {
 	// we generate the signature ourselves
 	go func() {
 		defer wg.Done()
-		generatedSig, errGen = t.generateSignature(msgID, msgsToSign, req, threshold, localStateItem.ParticipantKeys, localStateItem, blameMgr, keysignInstance, sigChan)
+		generatedSig, signers, errGen = t.generateSignature(msgID, msgsToSign, req, threshold, localStateItem.ParticipantKeys, localStateItem, blameMgr, keysignInstance, sigChan)
 	}()
 	wg.Wait()
 	close(sigChan)
 	keysignTime := time.Since(keysignStartTime)
 	// we received the generated verified signature, so we return
 	if errWait == nil {
-		t.updateKeySignResult(receivedSig, keysignTime)
-		return receivedSig, nil
+		t.updateKeySignResult(req.PoolPubKey, receivedSig, keysignTime)
+		return receivedSig, nil, nil
 	}
 	// for this round, we are not the active signer
 	if errors.Is(errGen, p2p.ErrSignReceived) || errors.Is(errGen, p2p.ErrNotActiveSigner) {
-		t.updateKeySignResult(receivedSig, keysignTime)
-		return receivedSig, nil
+		t.updateKeySignResult(req.PoolPubKey, receivedSig, keysignTime)
+		return receivedSig, nil, nil
 	}
 	// we get the signature from our tss keysign
-	t.updateKeySignResult(generatedSig, keysignTime)
-	return generatedSig, errGen
+	t.updateKeySignResult(req.PoolPubKey, generatedSig, keysignTime)
+	return generatedSig, signers, errGen
 }
 
 func (t *TssServer) broadcastKeysignFailure(messageID string, peers []peer.ID) {
@@ -0,0 +1,249 @@
+package tss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/monitor"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
+)
+
+// Presign runs the message independent rounds of the keysign with the signers ahead of time, the later keysign of
+// the pool by exactly these signers finishes in one online round with the presignatures
+func (t *TssServer) Presign(req keysign.PresignRequest) (keysign.PresignResponse, error) {
+	return t.PresignWithContext(context.Background(), req)
+}
+
+// PresignWithContext runs the presign in the trace of the span in ctx. The request that can not be written to the
+// audit log is rejected, while the failure to write the outcome does not fail the finished presign, it fails the
+// audit log readiness check instead
+func (t *TssServer) PresignWithContext(ctx context.Context, req keysign.PresignRequest) (keysign.PresignResponse, error) {
+	entry := audit.NewEntry(ctx, audit.ActionPresign, req)
+	resp, err := t.runPresign(ctx, req, entry)
+	t.finishAudit(entry, resp.Status, resp.Blame, resp.Count, err)
+	return resp, err
+}
+
+func (t *TssServer) runPresign(ctx context.Context, req keysign.PresignRequest, entry *audit.Entry) (keysign.PresignResponse, error) {
+	req, err := normalizePresignRequest(req)
+	if err != nil {
+		return keysign.PresignResponse{}, err
+	}
+	t.logger.Info().Str("pool pub key", req.PoolPubKey).
+		Str("signer pub keys", strings.Join(req.SignerPubKeys, ",")).
+		Int("count", req.Count).
+		Msg("received presign request")
+	msgID, err := t.requestToMsgId(req)
+	if err != nil {
+		return keysign.PresignResponse{}, err
+	}
+	entry.MsgID = msgID
+	entry.Participants = req.SignerPubKeys
+	if err := t.beginAudit(entry); err != nil {
+		return keysign.PresignResponse{}, err
+	}
+	t.startTranscript(msgID)
+	defer t.finishTranscript(msgID)
+	t.tssMetrics.CeremonyStarted(monitor.CeremonyPresign)
+	defer t.tssMetrics.CeremonyFinished(monitor.CeremonyPresign)
+	t.inflight.start(msgID, monitor.CeremonyPresign)
+	defer t.inflight.finish(msgID)
+	span := t.startTrace(ctx, "tss.presign", msgID, tracing.PoolPubKeyKey.String(req.PoolPubKey))
+	startTime := time.Now()
+	resp, err := t.presign(msgID, req)
+	t.recordCeremony(msgID, "presign", req.SignerPubKeys, resp.Status, resp.Blame, resp.Round, time.Since(startTime))
+	t.finishTrace(msgID, span, resp.Status, resp.Blame, err)
+	resp.PoolDepth = t.updatePresignPoolDepth(req.PoolPubKey)
+	resp.Blame = t.encodeBlame(resp.Blame)
+	return resp, err
+}
+
+func (t *TssServer) presign(msgID string, req keysign.PresignRequest) (keysign.PresignResponse, error) {
+	protocol, err := keysign.ProtocolFromVersion(req.Version)
+	if err != nil {
+		return keysign.PresignResponse{}, err
+	}
+	if protocol != keysign.ProtocolGG20 {
+		return keysign.PresignResponse{}, fmt.Errorf("%w: presign of %s", keysign.ErrProtocolNotSupported, protocol)
+	}
+	if req.Count <= 0 {
+		return keysign.PresignResponse{}, errors.New("invalid number of presignatures")
+	}
+	localStateItem, err := t.stateManager.GetLocalState(req.PoolPubKey)
+	if err != nil {
+		return keysign.PresignResponse{}, fmt.Errorf("fail to get local keygen state: %w", err)
+	}
+	threshold, err := conversion.GetThreshold(len(localStateItem.ParticipantKeys))
+	if err != nil {
+		return keysign.PresignResponse{}, errors.New("fail to get threshold")
+	}
+	if len(req.SignerPubKeys) <= threshold {
+		t.logger.Error().Msgf("not enough signers, threshold=%d and signers=%d", threshold, len(req.SignerPubKeys))
+		return keysign.PresignResponse{}, errors.New("not enough signers")
+	}
+	if !t.isPartOfKeysignParty(req.SignerPubKeys) {
+		return keysign.PresignResponse{}, errors.New("we are not the signer of the presign")
+	}
+
+	presignInstance := keysign.NewTssKeySign(
+		t.p2pCommunication.GetLocalPeerID(),
+		t.conf,
+		t.p2pCommunication.BroadcastMsgChan,
+		t.stopChan,
+		msgID,
+		t.privateKey,
+		t.p2pCommunication,
+		t.stateManager,
+		req.Count,
+	)
+	presignInstance.GetTssCommonStruct().SetRecorder(t.recorder)
+	presignInstance.GetTssCommonStruct().SetMetrics(t.tssMetrics)
+	presignInstance.GetTssCommonStruct().SetTraceContext(t.ceremonies.Context(msgID))
+	presignChannels := presignInstance.GetTssKeySignChannels()
+	t.p2pCommunication.SetSubscribe(messages.TSSKeySignMsg, msgID, presignChannels)
+	t.p2pCommunication.SetSubscribe(messages.TSSKeySignVerMsg, msgID, presignChannels)
+	t.p2pCommunication.SetSubscribe(messages.TSSControlMsg, msgID, presignChannels)
+	t.p2pCommunication.SetSubscribe(messages.TSSTaskDone, msgID, presignChannels)
+	defer func() {
+		t.p2pCommunication.CancelSubscribe(messages.TSSKeySignMsg, msgID)
+		t.p2pCommunication.CancelSubscribe(messages.TSSKeySignVerMsg, msgID)
+		t.p2pCommunication.CancelSubscribe(messages.TSSControlMsg, msgID)
+		t.p2pCommunication.CancelSubscribe(messages.TSSTaskDone, msgID)
+
+		t.p2pCommunication.ReleaseStream(msgID)
+		t.partyCoordinator.ReleaseStream(msgID)
+	}()
+
+	// the presignatures are bound to the signers, so all of them must join the party
+	peersID, err := conversion.GetPeerIDsFromPubKeys(req.SignerPubKeys)
+	if err != nil {
+		return keysign.PresignResponse{
+			Status: common.Fail,
+			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
+		}, nil
+	}
+	peersIDStr := make([]string, len(peersID))
+	for i, el := range peersID {
+		peersIDStr[i] = el.String()
+	}
+	blameMgr := presignInstance.GetTssCommonStruct().GetBlameMgr()
+	_, span := t.startPhase(msgID, "tss.join_party")
+	onlinePeers, err := t.partyCoordinator.JoinPartyWithRetry(msgID, peersIDStr)
+	tracing.End(span, err)
+	if err != nil {
+		t.logger.Error().Err(err).Msgf("fail to form presign party with online:%v", onlinePeers)
+		blameNodes, errBlame := blameMgr.NodeSyncBlame(req.SignerPubKeys, onlinePeers)
+		if errBlame != nil {
+			t.logger.Err(errBlame).Msg("fail to get peers to blame")
+		}
+		return keysign.PresignResponse{
+			Status: common.Fail,
+			Blame:  blameNodes,
+		}, nil
+	}
+
+	_, span = t.startPhase(msgID, "tss.presign.generate")
+	presigs, err := presignInstance.Presign(localStateItem, req.SignerPubKeys, req.Count)
+	tracing.End(span, err)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("err in presign")
+		return keysign.PresignResponse{
+			Status: common.Fail,
+			Blame:  *blameMgr.GetBlame(),
+			Round:  blameMgr.GetFailedRound(),
+		}, nil
+	}
+	if err := t.stateManager.SavePresignatures(presigs); err != nil {
+		t.logger.Error().Err(err).Msg("fail to save the presignatures")
+		return keysign.PresignResponse{
+			Status: common.Fail,
+			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
+		}, nil
+	}
+	return keysign.PresignResponse{
+		Count:  len(presigs),
+		Status: common.Success,
+	}, nil
+}
+
+// presignAgreement offers the presignatures we hold for the pool to the join party, as the leader we pick num of
+// them generated by exactly the online signers and held by all of them. The offered presignatures are leased to the
+// keysign of msgID, so the concurrent keysigns do not offer them, until they are released
+func (t *TssServer) presignAgreement(msgID, poolPubKey string, num int) *p2p.PresignAgreement {
+	held, err := t.stateManager.GetPresignatures(poolPubKey)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the presignatures, we offer none of them")
+	}
+	ids := make([]string, len(held))
+	for i, el := range held {
+		ids[i] = el.ID
+	}
+	leased := make(map[string]bool)
+	for _, id := range t.presignLeases.lease(msgID, ids) {
+		leased[id] = true
+	}
+	var presigs []storage.Presignature
+	var offer []string
+	for _, el := range held {
+		if leased[el.ID] {
+			presigs = append(presigs, el)
+			offer = append(offer, el.ID)
+		}
+	}
+	return &p2p.PresignAgreement{
+		Offer: offer,
+		Pick: func(onlinePeers []peer.ID, offers map[peer.ID][]string) []string {
+			onlines := make([]string, len(onlinePeers))
+			for i, el := range onlinePeers {
+				onlines[i] = el.String()
+			}
+			signers, err := conversion.GetPubKeysFromPeerIDs(onlines)
+			if err != nil {
+				t.logger.Error().Err(err).Msg("fail to get the public keys of the online peers")
+				return nil
+			}
+			held := make(map[string]int)
+			for _, ids := range offers {
+				for _, id := range ids {
+					held[id]++
+				}
+			}
+			var candidates []storage.Presignature
+			for _, el := range presigs {
+				if held[el.ID] == len(onlinePeers) {
+					candidates = append(candidates, el)
+				}
+			}
+			selected, _ := storage.SelectPresignatures(candidates, poolPubKey, signers, num)
+			picked := make([]string, len(selected))
+			for i, el := range selected {
+				picked[i] = el.ID
+			}
+			return picked
+		},
+	}
+}
+
+// updatePresignPoolDepth reports the presignatures left for the pool
+func (t *TssServer) updatePresignPoolDepth(poolPubKey string) int {
+	depth, err := t.stateManager.GetPresignatureCount(poolPubKey)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the number of presignatures")
+		return 0
+	}
+	t.tssMetrics.UpdatePresignPoolDepth(poolPubKey, depth)
+	return depth
+}
@@ -0,0 +1,62 @@
+package tss
+
+import (
+	"sync"
+)
+
+// presignLeases reserves the presignatures we offer to the join party of a keysign, so that the concurrent keysigns
+// on the same pool never offer the same presignatures. All the methods are no-op on the nil instance
+type presignLeases struct {
+	lock *sync.Mutex
+	// leases maps the id of the presignature to the msgID of the keysign it is leased to
+	leases map[string]string
+}
+
+func newPresignLeases() *presignLeases {
+	return &presignLeases{
+		lock:   &sync.Mutex{},
+		leases: make(map[string]string),
+	}
+}
+
+// lease reserves the presignatures not leased to the other keysigns for msgID, and returns their ids
+func (l *presignLeases) lease(msgID string, ids []string) []string {
+	if l == nil {
+		return ids
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var leased []string
+	for _, id := range ids {
+		if owner, ok := l.leases[id]; ok && owner != msgID {
+			continue
+		}
+		l.leases[id] = msgID
+		leased = append(leased, id)
+	}
+	return leased
+}
+
+// keep releases the presignatures leased to msgID except the ones with the given ids, which are the ones the
+// join party agreed on
+func (l *presignLeases) keep(msgID string, ids []string) {
+	if l == nil {
+		return
+	}
+	kept := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		kept[id] = true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for id, owner := range l.leases {
+		if owner == msgID && !kept[id] {
+			delete(l.leases, id)
+		}
+	}
+}
+
+// release releases all the presignatures leased to msgID
+func (l *presignLeases) release(msgID string) {
+	l.keep(msgID, nil)
+}
@@ -0,0 +1,66 @@
+package tss
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/storage"
+)
+
+type PresignLeaseTestSuite struct{}
+
+var _ = Suite(&PresignLeaseTestSuite{})
+
+func (PresignLeaseTestSuite) TestPresignLeases(c *C) {
+	leases := newPresignLeases()
+	c.Assert(leases.lease("msg1", []string{"a", "b", "c"}), DeepEquals, []string{"a", "b", "c"})
+	// the presignatures leased to the other keysign are not offered again
+	c.Assert(leases.lease("msg2", []string{"a", "b", "c", "d"}), DeepEquals, []string{"d"})
+	c.Assert(leases.lease("msg1", []string{"a", "d"}), DeepEquals, []string{"a"})
+
+	// the join party agreed on b, the others go back to the pool
+	leases.keep("msg1", []string{"b"})
+	c.Assert(leases.lease("msg3", []string{"a", "b", "c"}), DeepEquals, []string{"a", "c"})
+	leases.release("msg1")
+	leases.release("msg3")
+	c.Assert(leases.lease("msg4", []string{"a", "b", "c", "d"}), DeepEquals, []string{"a", "b", "c"})
+
+	var nilLeases *presignLeases
+	c.Assert(nilLeases.lease("msg1", []string{"a"}), DeepEquals, []string{"a"})
+	nilLeases.keep("msg1", nil)
+	nilLeases.release("msg1")
+}
+
+func (PresignLeaseTestSuite) TestConcurrentPresignAgreement(c *C) {
+	folder, err := ioutil.TempDir("", "presignlease")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(folder)
+	stateMgr, err := storage.NewFileStateMgr(folder)
+	c.Assert(err, IsNil)
+	const poolPubKey = "oppypub1addwnpepqtmru87hylm9q0tcza8p0vze2zvmqk0wr0933qr472hggzw2tp4pvy3756g"
+	var presigs []storage.Presignature
+	for _, id := range []string{"p0", "p1", "p2", "p3"} {
+		presigs = append(presigs, storage.Presignature{ID: id, PoolPubKey: poolPubKey, Data: []byte(id)})
+	}
+	c.Assert(stateMgr.SavePresignatures(presigs), IsNil)
+	server := &TssServer{
+		stateManager:  stateMgr,
+		presignLeases: newPresignLeases(),
+	}
+
+	// the two keysigns in flight on the same pool offer the different presignatures
+	first := server.presignAgreement("msg1", poolPubKey, 2)
+	second := server.presignAgreement("msg2", poolPubKey, 2)
+	c.Assert(first.Offer, HasLen, 4)
+	c.Assert(second.Offer, HasLen, 0)
+
+	// once the first party agreed on its presignatures, the rest can be offered to the second keysign
+	server.presignLeases.keep("msg1", []string{"p0", "p1"})
+	third := server.presignAgreement("msg3", poolPubKey, 2)
+	c.Assert(third.Offer, DeepEquals, []string{"p2", "p3"})
+	server.presignLeases.release("msg1")
+	server.presignLeases.release("msg3")
+	c.Assert(server.presignAgreement("msg4", poolPubKey, 2).Offer, HasLen, 4)
+}
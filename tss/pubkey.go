@@ -0,0 +1,157 @@
+package tss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/storage"
+)
+
+// the requests accept the pub keys in any of the supported encodings, we convert them to the form used among the
+// parties before anything else, so all the parties get the same message id
+
+func normalizeKeygenRequest(req keygen.Request) (keygen.Request, error) {
+	keys, err := conversion.NormalizePubKeys(req.Keys)
+	if err != nil {
+		return req, fmt.Errorf("invalid keygen keys: %w", err)
+	}
+	req.Keys = keys
+	return req, nil
+}
+
+func normalizeKeysignRequest(req keysign.Request) (keysign.Request, error) {
+	poolPubKey, err := conversion.NormalizePubKey(req.PoolPubKey)
+	if err != nil {
+		return req, fmt.Errorf("invalid pool pub key: %w", err)
+	}
+	signers, err := conversion.NormalizePubKeys(req.SignerPubKeys)
+	if err != nil {
+		return req, fmt.Errorf("invalid signer pub keys: %w", err)
+	}
+	req.PoolPubKey = poolPubKey
+	req.SignerPubKeys = signers
+	return req, nil
+}
+
+func normalizePresignRequest(req keysign.PresignRequest) (keysign.PresignRequest, error) {
+	poolPubKey, err := conversion.NormalizePubKey(req.PoolPubKey)
+	if err != nil {
+		return req, fmt.Errorf("invalid pool pub key: %w", err)
+	}
+	signers, err := conversion.NormalizePubKeys(req.SignerPubKeys)
+	if err != nil {
+		return req, fmt.Errorf("invalid signer pub keys: %w", err)
+	}
+	req.PoolPubKey = poolPubKey
+	req.SignerPubKeys = signers
+	return req, nil
+}
+
+// encodePubKey converts the pub key to the encoding of the server, the pub key we fail to convert is returned as is
+func (t *TssServer) encodePubKey(pubKey string) string {
+	ret, err := t.pubKeyCodec.Encode(pubKey)
+	if err != nil {
+		t.logger.Error().Err(err).Msgf("fail to encode the pub key(%s)", pubKey)
+		return pubKey
+	}
+	return ret
+}
+
+func (t *TssServer) encodePubKeys(pubKeys []string) []string {
+	if pubKeys == nil {
+		return nil
+	}
+	ret := make([]string, len(pubKeys))
+	for i, el := range pubKeys {
+		ret[i] = t.encodePubKey(el)
+	}
+	return ret
+}
+
+func (t *TssServer) encodeBlame(b blame.Blame) blame.Blame {
+	if len(b.BlameNodes) == 0 {
+		return b
+	}
+	nodes := make([]blame.Node, len(b.BlameNodes))
+	for i, el := range b.BlameNodes {
+		nodes[i] = el
+		nodes[i].Pubkey = t.encodePubKey(el.Pubkey)
+	}
+	b.BlameNodes = nodes
+	return b
+}
+
+func (t *TssServer) encodeEvidences(evidences []blame.Evidence) []blame.Evidence {
+	if evidences == nil {
+		return nil
+	}
+	ret := make([]blame.Evidence, len(evidences))
+	for i, el := range evidences {
+		ret[i] = el
+		ret[i].Pubkey = t.encodePubKey(el.Pubkey)
+	}
+	return ret
+}
+
+func (t *TssServer) encodeBlameVotes(votes map[string]int) map[string]int {
+	if votes == nil {
+		return nil
+	}
+	ret := make(map[string]int, len(votes))
+	for k, v := range votes {
+		ret[t.encodePubKey(k)] = v
+	}
+	return ret
+}
+
+func (t *TssServer) encodeKeygenResponse(resp keygen.Response) keygen.Response {
+	resp.PubKey = t.encodePubKey(resp.PubKey)
+	resp.Blame = t.encodeBlame(resp.Blame)
+	resp.Evidence = t.encodeEvidences(resp.Evidence)
+	resp.BlameVotes = t.encodeBlameVotes(resp.BlameVotes)
+	resp.FailedLeaders = t.encodePubKeys(resp.FailedLeaders)
+	return resp
+}
+
+func (t *TssServer) encodeKeysignResponse(resp keysign.Response) keysign.Response {
+	resp.Blame = t.encodeBlame(resp.Blame)
+	resp.Evidence = t.encodeEvidences(resp.Evidence)
+	resp.BlameVotes = t.encodeBlameVotes(resp.BlameVotes)
+	resp.FailedLeaders = t.encodePubKeys(resp.FailedLeaders)
+	return resp
+}
+
+func (t *TssServer) encodeCeremonyRecords(records []storage.CeremonyRecord) []storage.CeremonyRecord {
+	ret := make([]storage.CeremonyRecord, len(records))
+	for i, el := range records {
+		ret[i] = el
+		ret[i].Participants = t.encodePubKeys(el.Participants)
+		ret[i].BlameNodes = t.encodePubKeys(el.BlameNodes)
+	}
+	return ret
+}
+
+// GetAddresses returns the addresses of the pool pub key on all the supported chains, the cosmos address has the
+// prefix of the server
+func (t *TssServer) GetAddresses(poolPubKey string) (map[string]string, error) {
+	return t.GetAddressesWithContext(context.Background(), poolPubKey)
+}
+
+// addressesRequest is the request of the addresses in the audit log
+type addressesRequest struct {
+	PoolPubKey string `json:"pool_pub_key"`
+}
+
+// GetAddressesWithContext returns the addresses of the pool pub key to the caller in ctx, who is recorded in the
+// audit log
+func (t *TssServer) GetAddressesWithContext(ctx context.Context, poolPubKey string) (map[string]string, error) {
+	if err := t.auditAdmin(ctx, audit.ActionAddresses, addressesRequest{PoolPubKey: poolPubKey}); err != nil {
+		return nil, err
+	}
+	return t.pubKeyCodec.GetAddresses(poolPubKey)
+}
@@ -0,0 +1,49 @@
+package tss
+
+import (
+	"encoding/hex"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keysign"
+)
+
+type PubKeyTestSuite struct{}
+
+var _ = Suite(&PubKeyTestSuite{})
+
+func (PubKeyTestSuite) TestNormalizeKeysignRequest(c *C) {
+	conversion.SetupBech32Prefix()
+	pk, err := conversion.ParsePubKey(testPubKeys[1])
+	c.Assert(err, IsNil)
+	req := keysign.NewRequest(hex.EncodeToString(pk.Bytes()), nil, 10, []string{testPubKeys[0], hex.EncodeToString(pk.Bytes())}, "0.14.0")
+	req, err = normalizeKeysignRequest(req)
+	c.Assert(err, IsNil)
+	c.Assert(req.PoolPubKey, Equals, testPubKeys[1])
+	c.Assert(req.SignerPubKeys, DeepEquals, []string{testPubKeys[0], testPubKeys[1]})
+
+	req.SignerPubKeys = []string{"invalid"}
+	_, err = normalizeKeysignRequest(req)
+	c.Assert(err, NotNil)
+}
+
+func (PubKeyTestSuite) TestEncodeBlame(c *C) {
+	conversion.SetupBech32Prefix()
+	codec, err := conversion.NewPubKeyCodec(conversion.PubKeyEncodingCompressed, "")
+	c.Assert(err, IsNil)
+	server := &TssServer{pubKeyCodec: codec}
+	pk, err := conversion.ParsePubKey(testPubKeys[0])
+	c.Assert(err, IsNil)
+
+	b := blame.NewBlame(blame.TssTimeout, []blame.Node{{Pubkey: testPubKeys[0]}})
+	encoded := server.encodeBlame(b)
+	c.Assert(encoded.BlameNodes[0].Pubkey, Equals, hex.EncodeToString(pk.Bytes()))
+	// the blame of the caller is not changed
+	c.Assert(b.BlameNodes[0].Pubkey, Equals, testPubKeys[0])
+
+	votes := server.encodeBlameVotes(map[string]int{testPubKeys[0]: 2})
+	c.Assert(votes, DeepEquals, map[string]int{hex.EncodeToString(pk.Bytes()): 2})
+	c.Assert(server.encodePubKey("invalid"), Equals, "invalid")
+}
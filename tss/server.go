@@ -1,8 +1,11 @@
 package tss
 
 import (
+	"context"
+
 	"github.com/joltify-finance/tss/keygen"
 	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/storage"
 )
 
 // Server define the necessary functionality should be provide by a TSS Server implementation
@@ -12,4 +15,16 @@ type Server interface {
 	GetLocalPeerID() string
 	Keygen(req keygen.Request) (keygen.Response, error)
 	KeySign(req keysign.Request) (keysign.Response, error)
+	Presign(req keysign.PresignRequest) (keysign.PresignResponse, error)
+	KeygenWithContext(ctx context.Context, req keygen.Request) (keygen.Response, error)
+	KeySignWithContext(ctx context.Context, req keysign.Request) (keysign.Response, error)
+	PresignWithContext(ctx context.Context, req keysign.PresignRequest) (keysign.PresignResponse, error)
+	GetBlameHistory() ([]storage.CeremonyRecord, error)
+	GetPeerScores() ([]storage.PeerScore, error)
+	GetAddresses(poolPubKey string) (map[string]string, error)
+	GetBlameHistoryWithContext(ctx context.Context) ([]storage.CeremonyRecord, error)
+	GetPeerScoresWithContext(ctx context.Context) ([]storage.PeerScore, error)
+	GetAddressesWithContext(ctx context.Context, poolPubKey string) (map[string]string, error)
+	Readiness() Readiness
+	Status() Status
 }
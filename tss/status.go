@@ -0,0 +1,69 @@
+package tss
+
+import (
+	"time"
+
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/p2p"
+)
+
+// CeremonyStatus is the ceremony the node is running and the phase it is in
+type CeremonyStatus struct {
+	MsgID        string        `json:"msg_id"`
+	Type         string        `json:"type"`
+	Phase        string        `json:"phase,omitempty"`
+	Elapsed      time.Duration `json:"elapsed"`
+	PhaseElapsed time.Duration `json:"phase_elapsed,omitempty"`
+}
+
+// Status is what the node is connected to and busy with
+type Status struct {
+	// Version is the version of the binary, it is set by the caller that knows it
+	Version       string              `json:"version,omitempty"`
+	PeerID        string              `json:"peer_id"`
+	PubKey        string              `json:"pub_key"`
+	ListenAddrs   []string            `json:"listen_addrs"`
+	AnnounceAddrs []string            `json:"announce_addrs"`
+	Peers         []p2p.ConnectedPeer `json:"peers"`
+	// Subscriptions are the msgIDs subscribed on each message type
+	Subscriptions     map[string][]string       `json:"subscriptions"`
+	JoinPartyGroups   []p2p.JoinPartyGroup      `json:"join_party_groups"`
+	Ceremonies        []CeremonyStatus          `json:"ceremonies"`
+	PendingSignatures []keysign.PendingNotifier `json:"pending_signatures"`
+}
+
+// Status returns the snapshot of what the node is connected to and busy with
+func (t *TssServer) Status() Status {
+	h := t.p2pCommunication.GetHost()
+	status := Status{
+		PeerID:            h.ID().String(),
+		PubKey:            t.encodePubKey(t.localNodePubKey),
+		Peers:             t.p2pCommunication.ConnectedPeers(),
+		Subscriptions:     t.p2pCommunication.Subscriptions(),
+		JoinPartyGroups:   t.partyCoordinator.JoinPartyGroups(),
+		PendingSignatures: t.signatureNotifier.PendingNotifiers(),
+	}
+	for _, el := range h.Network().ListenAddresses() {
+		status.ListenAddrs = append(status.ListenAddrs, el.String())
+	}
+	for _, el := range h.Addrs() {
+		status.AnnounceAddrs = append(status.AnnounceAddrs, el.String())
+	}
+	for i := range status.PendingSignatures {
+		status.PendingSignatures[i].PoolPubKey = t.encodePubKey(status.PendingSignatures[i].PoolPubKey)
+	}
+	now := time.Now()
+	for _, el := range t.inflight.list() {
+		ceremony := CeremonyStatus{
+			MsgID:   el.MsgID,
+			Type:    el.Type,
+			Phase:   el.Phase,
+			Elapsed: now.Sub(el.Start),
+		}
+		if len(el.Phase) != 0 {
+			ceremony.PhaseElapsed = now.Sub(el.PhaseStart)
+		}
+		status.Ceremonies = append(status.Ceremonies, ceremony)
+	}
+	return status
+}
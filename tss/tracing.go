@@ -0,0 +1,33 @@
+package tss
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joltify-finance/tss/blame"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/tracing"
+)
+
+// startTrace starts the span of the ceremony, the phases of the ceremony are traced in it with the msgID
+func (t *TssServer) startTrace(ctx context.Context, name, msgID string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := t.ceremonies.Start(ctx, name, msgID, attrs...)
+	return span
+}
+
+// startPhase starts the span of the phase in the trace of the ceremony, and records the phase the ceremony is in
+func (t *TssServer) startPhase(msgID, name string) (context.Context, trace.Span) {
+	t.inflight.setPhase(msgID, strings.TrimPrefix(name, "tss."))
+	return t.ceremonies.StartPhase(msgID, name)
+}
+
+// finishTrace ends the span of the ceremony, the ceremony fails either with the error or with the blame
+func (t *TssServer) finishTrace(msgID string, span trace.Span, status common.Status, b blame.Blame, err error) {
+	if err == nil && status == common.Fail {
+		tracing.Fail(span, b.FailReason)
+	}
+	t.ceremonies.Finish(msgID, span, err)
+}
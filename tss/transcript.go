@@ -0,0 +1,20 @@
+package tss
+
+// startTranscript begins the transcript of the ceremony if the recorder is enabled
+func (t *TssServer) startTranscript(msgID string) {
+	t.recorder.Start(msgID)
+}
+
+// finishTranscript saves the transcript of the ceremony, the failure of saving the transcript does not fail the
+// ceremony
+func (t *TssServer) finishTranscript(msgID string) {
+	if t.recorder == nil {
+		return
+	}
+	filePathName, err := t.recorder.Finish(msgID)
+	if err != nil {
+		t.logger.Error().Err(err).Str("msgID", msgID).Msg("fail to save the transcript")
+		return
+	}
+	t.logger.Info().Str("msgID", msgID).Msgf("transcript is saved to %s", filePathName)
+}
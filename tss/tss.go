@@ -1,8 +1,10 @@
 package tss
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -10,13 +12,14 @@ import (
 
 	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
 	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
-	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	tcrypto "github.com/tendermint/tendermint/crypto"
 
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/blame"
 	"github.com/joltify-finance/tss/common"
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keygen"
@@ -25,6 +28,8 @@ import (
 	"github.com/joltify-finance/tss/monitor"
 	"github.com/joltify-finance/tss/p2p"
 	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
+	"github.com/joltify-finance/tss/transcript"
 )
 
 // TssServer is the structure that can provide all keysign and key gen features
@@ -41,6 +46,13 @@ type TssServer struct {
 	signatureNotifier *keysign.SignatureNotifier
 	privateKey        tcrypto.PrivKey
 	tssMetrics        *monitor.Metric
+	pubKeyCodec       conversion.PubKeyCodec
+	recorder          *transcript.Recorder
+	ceremonies        *tracing.Ceremonies
+	auditLog          *audit.Log
+	inflight          *inflightCeremonies
+	scoreBoard        *storage.PeerScoreBoard
+	presignLeases     *presignLeases
 }
 
 // NewTss create a new instance of Tss
@@ -54,15 +66,6 @@ func NewTss(
 	preParams *bkeygen.LocalPreParams,
 	externalIP string,
 ) (*TssServer, error) {
-	pk := coskey.PubKey{
-		Key: priKey.PubKey().Bytes(),
-	}
-
-	pubKey, err := legacybech32.MarshalPubKey(legacybech32.AccPK, &pk)
-	if err != nil {
-		return nil, fmt.Errorf("fail to genearte the key: %w", err)
-	}
-
 	stateManager, err := storage.NewFileStateMgr(baseFolder)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create file state manager")
@@ -80,6 +83,43 @@ func NewTss(
 	if err != nil {
 		return nil, fmt.Errorf("fail to create communication layer: %w", err)
 	}
+	return NewTssWithCommunication(comm, priKey, baseFolder, stateManager, conf, preParams)
+}
+
+// NewTssWithCommunication create a new instance of Tss on the communication which is created but not started by
+// the caller, for example to run on the mock network in the tests
+func NewTssWithCommunication(
+	comm *p2p.Communication,
+	priKey tcrypto.PrivKey,
+	baseFolder string,
+	stateManager storage.LocalStateManager,
+	conf common.TssConfig,
+	preParams *bkeygen.LocalPreParams,
+) (*TssServer, error) {
+	pk := coskey.PubKey{
+		Key: priKey.PubKey().Bytes(),
+	}
+
+	pubKey, err := conversion.MarshalPubKey(&pk)
+	if err != nil {
+		return nil, fmt.Errorf("fail to genearte the key: %w", err)
+	}
+
+	pubKeyCodec, err := conversion.NewPubKeyCodec(conf.PubKeyEncoding, conf.Bech32Prefix)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyCodec, err = pubKeyCodec.WithAddressPrefixes(conf.AddressPrefixes)
+	if err != nil {
+		return nil, fmt.Errorf("fail to set the address prefixes: %w", err)
+	}
+
+	if conf.EnableMDNS {
+		comm.EnableMDNS()
+	}
+	if conf.EnableRelay || conf.RelayService {
+		comm.EnableRelay(conf.RelayPeers, conf.RelayService, conf.BehindNAT)
+	}
 	// When using the keygen party it is recommended that you pre-compute the
 	// "safe primes" and Paillier secret beforehand because this can take some
 	// time.
@@ -99,15 +139,43 @@ func NewTss(
 	if err != nil {
 		return nil, fmt.Errorf("fail to get private key")
 	}
+	metrics := monitor.NewMetric()
+	if conf.EnableMonitor {
+		metrics.Enable()
+	}
+	comm.SetMetrics(metrics)
 	if err := comm.Start(priKeyRawBytes); nil != err {
 		return nil, fmt.Errorf("fail to start p2p network: %w", err)
 	}
+	var recorder *transcript.Recorder
+	if conf.EnableTranscript {
+		recorder, err = transcript.NewRecorder(filepath.Join(baseFolder, "transcripts"), comm.GetLocalPeerID(), priKey)
+		if err != nil {
+			return nil, fmt.Errorf("fail to create the transcript recorder: %w", err)
+		}
+		comm.SetRecorder(recorder)
+	}
+	var auditLog *audit.Log
+	if conf.EnableAudit {
+		auditLog, err = audit.NewLog(filepath.Join(baseFolder, "audit"), audit.Config{
+			MaxFileSize: conf.AuditMaxFileSize,
+			MaxFiles:    conf.AuditMaxFiles,
+			PrivKey:     priKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fail to open the audit log: %w", err)
+		}
+	}
 	pc := p2p.NewPartyCoordinator(comm.GetHost(), conf.PartyTimeout)
-	pc.Start()
+	leaderSelector, err := p2p.NewLeaderSelector(conf.LeaderSelector, baseFolder)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create the leader selector: %w", err)
+	}
+	pc.SetLeaderSelector(leaderSelector)
 	sn := keysign.NewSignatureNotifier(comm.GetHost())
-	metrics := monitor.NewMetric()
-	if conf.EnableMonitor {
-		metrics.Enable()
+	scoreBoard, err := newPeerScoreBoard(conf, stateManager)
+	if err != nil {
+		log.Error().Err(err).Msg("fail to load the blame history, the peers are scored from now on")
 	}
 	tssServer := TssServer{
 		conf:              conf,
@@ -122,7 +190,18 @@ func NewTss(
 		signatureNotifier: sn,
 		privateKey:        priKey,
 		tssMetrics:        metrics,
+		pubKeyCodec:       pubKeyCodec,
+		recorder:          recorder,
+		ceremonies:        tracing.NewCeremonies(),
+		auditLog:          auditLog,
+		inflight:          newInflightCeremonies(),
+		scoreBoard:        scoreBoard,
+		presignLeases:     newPresignLeases(),
 	}
+	if conf.DeprioritizeFailingPeers {
+		pc.SetUnreliablePeers(tssServer.unreliablePeers)
+	}
+	pc.Start()
 
 	return &tssServer, nil
 }
@@ -130,7 +209,7 @@ func NewTss(
 // Start Tss server
 func (t *TssServer) Start() error {
 	log.Info().Msg("Starting the TSS servers")
-	return nil
+	return t.writeAudit(audit.NewEntry(context.Background(), audit.ActionStart, nil))
 }
 
 // Stop Tss server
@@ -144,9 +223,21 @@ func (t *TssServer) Stop() {
 		t.logger.Error().Msgf("error in shutdown the p2p server")
 	}
 
+	if err := t.writeAudit(audit.NewEntry(context.Background(), audit.ActionStop, nil)); err != nil {
+		t.logger.Error().Err(err).Msg("fail to record the stop of the server")
+	}
+	if err := t.auditLog.Close(); err != nil {
+		t.logger.Error().Err(err).Msg("fail to close the audit log")
+	}
 	log.Info().Msg("The Tss and p2p server has been stopped successfully")
 }
 
+// SetPhaseObserver sets the observer of the time spent in each phase of the keygen and keysign, it must be set before
+// the server handles the requests
+func (t *TssServer) SetPhaseObserver(observer monitor.PhaseObserver) {
+	t.tssMetrics.SetPhaseObserver(observer)
+}
+
 func (t *TssServer) requestToMsgId(request interface{}) (string, error) {
 	var dat []byte
 	var keys []string
@@ -157,6 +248,9 @@ func (t *TssServer) requestToMsgId(request interface{}) (string, error) {
 		sort.Strings(value.Messages)
 		dat = []byte(strings.Join(value.Messages, ","))
 		keys = value.SignerPubKeys
+	case keysign.PresignRequest:
+		dat = []byte(fmt.Sprintf("presign-%s-%d-%d", value.PoolPubKey, value.Count, value.BlockHeight))
+		keys = value.SignerPubKeys
 	default:
 		t.logger.Error().Msg("unknown request type")
 		return "", errors.New("unknown request type")
@@ -175,41 +269,83 @@ func (t *TssServer) requestToMsgId(request interface{}) (string, error) {
 	return common.MsgToHashString(dat)
 }
 
-func (t *TssServer) joinParty(msgID, version string, blockHeight int64, participants []string, threshold int, sigChan chan string) ([]peer.ID, string, error) {
+func (t *TssServer) joinParty(msgID, version string, blockHeight int64, participants []string, threshold int, presign *p2p.PresignAgreement, sigChan chan string) ([]peer.ID, string, []string, error) {
+	_, span := t.startPhase(msgID, "tss.join_party")
+	onlinePeers, leader, failedLeaders, err := t.coordinateParty(msgID, version, blockHeight, participants, threshold, presign, sigChan)
+	span.SetAttributes(tracing.LeaderKey.String(leader))
+	tracing.End(span, err)
+	return onlinePeers, leader, failedLeaders, err
+}
+
+func (t *TssServer) coordinateParty(msgID, version string, blockHeight int64, participants []string, threshold int, presign *p2p.PresignAgreement, sigChan chan string) ([]peer.ID, string, []string, error) {
 	oldJoinParty, err := conversion.VersionLTCheck(version, messages.NEWJOINPARTYVERSION)
 	if err != nil {
-		return nil, "", fmt.Errorf("fail to parse the version with error:%w", err)
+		return nil, "", nil, fmt.Errorf("fail to parse the version with error:%w", err)
 	}
 	if oldJoinParty {
 		t.logger.Info().Msg("we apply the leadless join party")
 		peerIDs, err := conversion.GetPeerIDsFromPubKeys(participants)
 		if err != nil {
-			return nil, "NONE", fmt.Errorf("fail to convert pub key to peer id: %w", err)
+			return nil, "NONE", nil, fmt.Errorf("fail to convert pub key to peer id: %w", err)
 		}
 		var peersIDStr []string
 		for _, el := range peerIDs {
 			peersIDStr = append(peersIDStr, el.String())
 		}
 		onlines, err := t.partyCoordinator.JoinPartyWithRetry(msgID, peersIDStr)
-		return onlines, "NONE", err
+		return onlines, "NONE", nil, err
 	} else {
 		t.logger.Info().Msgf("we apply the join party with a leader msgID(%v)", msgID)
 
 		if len(participants) == 0 {
 			t.logger.Error().Msg("we fail to have any participants or passed by request")
-			return nil, "", errors.New("no participants can be found")
+			return nil, "", nil, errors.New("no participants can be found")
 		}
 		peersID, err := conversion.GetPeerIDsFromPubKeys(participants)
 		if err != nil {
-			return nil, "", errors.New("fail to convert the public key to peer ID")
+			return nil, "", nil, errors.New("fail to convert the public key to peer ID")
 		}
 		var peersIDStr []string
 		for _, el := range peersID {
 			peersIDStr = append(peersIDStr, el.String())
 		}
 
-		return t.partyCoordinator.JoinPartyWithLeader(msgID, blockHeight, peersIDStr, threshold, sigChan)
+		unsigned, err := conversion.VersionLTCheck(version, messages.SIGNEDJOINPARTYVERSION)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("fail to parse the version with error:%w", err)
+		}
+		t.inflight.setJoinAttempts(msgID, p2p.LeaderAttempts(len(peersIDStr), threshold))
+		return t.partyCoordinator.JoinPartyWithLeader(msgID, blockHeight, peersIDStr, threshold, !unsigned, presign, sigChan)
+	}
+}
+
+// leaderBlameNodes returns the blame nodes of the leader and all the leaders we failed over from
+func (t *TssServer) leaderBlameNodes(leader string, failedLeaders []string) []blame.Node {
+	leaders := make([]string, 0, len(failedLeaders)+1)
+	leaders = append(leaders, failedLeaders...)
+	if len(failedLeaders) == 0 || failedLeaders[len(failedLeaders)-1] != leader {
+		leaders = append(leaders, leader)
+	}
+	pubKeys := t.leaderPubKeys(leaders)
+	nodes := make([]blame.Node, len(pubKeys))
+	for i, el := range pubKeys {
+		nodes[i] = blame.Node{Pubkey: el}
+	}
+	return nodes
+}
+
+// leaderPubKeys converts the peer IDs of the leaders to their public keys, it returns nil if there is no leader
+func (t *TssServer) leaderPubKeys(leaders []string) []string {
+	var pubKeys []string
+	for _, el := range leaders {
+		leaderPubKey, err := conversion.GetPubKeyFromPeerID(el)
+		if err != nil {
+			t.logger.Error().Err(err).Msgf("fail to convert the peerID to public key with leader %s", el)
+			continue
+		}
+		pubKeys = append(pubKeys, leaderPubKey)
 	}
+	return pubKeys
 }
 
 // GetLocalPeerID return the local peer
@@ -24,6 +24,7 @@ import (
 	"github.com/joltify-finance/tss/conversion"
 	"github.com/joltify-finance/tss/keygen"
 	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/transcript"
 )
 
 const (
@@ -78,6 +79,8 @@ func (s *FourNodeTestSuite) SetUpTest(c *C) {
 		KeySignTimeout:  30 * time.Second,
 		PreParamTimeout: 5 * time.Second,
 		EnableMonitor:   false,
+		// we replay the transcript of the keygen
+		EnableTranscript: true,
 	}
 
 	var wg sync.WaitGroup
@@ -166,6 +169,7 @@ func (s *FourNodeTestSuite) doTestKeygenAndKeySign(c *C, newJoinParty bool) {
 			c.Assert(poolPubKey, Equals, item.PubKey)
 		}
 	}
+	s.checkKeygenTranscript(c, keygen.NewRequest(append([]string{}, testPubKeys...), 10, "0.14.0"))
 
 	keysignReqWithErr := keysign.NewRequest(poolPubKey, []string{"helloworld", "helloworld2"}, 10, testPubKeys, "0.13.0")
 	if newJoinParty {
@@ -200,8 +204,20 @@ func (s *FourNodeTestSuite) doTestKeygenAndKeySign(c *C, newJoinParty bool) {
 			} else {
 				keysignReq = keysign.NewRequest(poolPubKey, []string{base64.StdEncoding.EncodeToString(hash([]byte("helloworld"))), base64.StdEncoding.EncodeToString(hash([]byte("helloworld2")))}, 10, localPubKeys, "0.13.0")
 			}
+			keysignReq.OutputFormats = []string{keysign.FormatDER, keysign.FormatEthereum}
+			// the parties may hash the messages themselves or leave it to the node
+			if idx%2 == 1 {
+				keysignReq.Messages = []string{base64.StdEncoding.EncodeToString([]byte("helloworld")), base64.StdEncoding.EncodeToString([]byte("helloworld2"))}
+				keysignReq.HashModes = []string{keysign.HashModeSHA256}
+			} else {
+				keysignReq.HashModes = []string{keysign.HashModePrehashed, keysign.HashModePrehashed}
+			}
 			res, err := s.servers[idx].KeySign(keysignReq)
 			c.Assert(err, IsNil)
+			for _, el := range res.Signatures {
+				c.Assert(el.DER, Not(Equals), "")
+				c.Assert(el.Ethereum, Not(Equals), "")
+			}
 			lock.Lock()
 			defer lock.Unlock()
 			keysignResult[idx] = res
@@ -221,6 +237,20 @@ func (s *FourNodeTestSuite) doTestKeygenAndKeySign(c *C, newJoinParty bool) {
 			} else {
 				keysignReq = keysign.NewRequest(poolPubKey, []string{base64.StdEncoding.EncodeToString(hash([]byte("helloworld"))), base64.StdEncoding.EncodeToString(hash([]byte("helloworld2")))}, 10, testPubKeys[:3], "0.13.0")
 			}
+			// the parties may send the pub keys in different encodings
+			if idx%2 == 1 {
+				codec, err := conversion.NewPubKeyCodec(conversion.PubKeyEncodingCompressed, "")
+				c.Assert(err, IsNil)
+				keysignReq.PoolPubKey, err = codec.Encode(keysignReq.PoolPubKey)
+				c.Assert(err, IsNil)
+				var signers []string
+				for _, el := range keysignReq.SignerPubKeys {
+					signer, err := codec.Encode(el)
+					c.Assert(err, IsNil)
+					signers = append(signers, signer)
+				}
+				keysignReq.SignerPubKeys = signers
+			}
 			res, err := s.servers[idx].KeySign(keysignReq)
 			c.Assert(err, IsNil)
 			lock.Lock()
@@ -336,6 +366,23 @@ func (s *FourNodeTestSuite) TearDownTest(c *C) {
 	}
 }
 
+// checkKeygenTranscript replays the transcript of the successful keygen, which has nobody to blame
+func (s *FourNodeTestSuite) checkKeygenTranscript(c *C, req keygen.Request) {
+	req, err := normalizeKeygenRequest(req)
+	c.Assert(err, IsNil)
+	msgID, err := s.servers[0].requestToMsgId(req)
+	c.Assert(err, IsNil)
+	t, err := transcript.Load(path.Join(os.TempDir(), "4nodes_test", "0", "transcripts", msgID+".json"))
+	c.Assert(err, IsNil)
+	c.Assert(t.Parties, HasLen, partyNum)
+	result, err := common.ReplayTranscript(t, common.TssConfig{})
+	c.Assert(err, IsNil)
+	c.Assert(result.Processed, Not(Equals), 0)
+	// the secret shares of the keygen are not recorded
+	c.Assert(result.Skipped, Not(Equals), 0)
+	c.Assert(result.Blame.FailReason, Equals, "")
+}
+
 func (s *FourNodeTestSuite) getTssServer(c *C, index int, conf common.TssConfig, bootstrap string) *TssServer {
 	priKey, err := conversion.GetPriKey(testPriKeyArr[index])
 	c.Assert(err, IsNil)
@@ -0,0 +1,242 @@
+package tsstest
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/joltify-finance/tss/messages"
+)
+
+// AnyNode matches all the nodes in the From and To of the rule
+const AnyNode = -1
+
+// reorderTimeout is how long the held message waits for the next message on the link before it is sent anyway
+const reorderTimeout = 500 * time.Millisecond
+
+// errPartitioned is returned for the messages between the nodes in the different groups of the partition
+var errPartitioned = errors.New("nodes are partitioned")
+
+// Fault is what happens to the message that matches the rule
+type Fault struct {
+	// Drop discards the message
+	Drop bool
+	// Duplicate sends the message this many more times
+	Duplicate int
+	// Delay sends the message after the delay
+	Delay time.Duration
+	// Reorder holds the message until the next message on the same link is sent, so the two are swapped
+	Reorder bool
+}
+
+// Rule selects the messages from one node to the other and injects the fault on them
+type Rule struct {
+	// From and To are the index of the sender and the receiver, AnyNode matches all of them
+	From int
+	To   int
+	// MessageTypes are the types of the message the rule applies to, all the types if it is empty
+	MessageTypes []messages.THORChainTSSMessageType
+	// Probability is the chance the fault is injected on the matched message, 0 means always
+	Probability float64
+	// Count is the number of the messages the fault is injected on, 0 means no limit
+	Count int
+	Fault Fault
+}
+
+func (r *Rule) match(from, to int, msgType messages.THORChainTSSMessageType, known bool) bool {
+	if r.From != AnyNode && r.From != from {
+		return false
+	}
+	if r.To != AnyNode && r.To != to {
+		return false
+	}
+	if len(r.MessageTypes) == 0 {
+		return true
+	}
+	if !known {
+		return false
+	}
+	for _, el := range r.MessageTypes {
+		if el == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+type link struct {
+	from, to int
+}
+
+// heldMessage is the message held for the reorder
+type heldMessage struct {
+	deliver func() error
+	done    chan struct{}
+}
+
+// faultInjector applies the rules and the partition to the messages the nodes send
+type faultInjector struct {
+	lock      *sync.Mutex
+	rand      *rand.Rand
+	rules     []*ruleState
+	partition []int
+	held      map[link]*heldMessage
+	stopChan  chan struct{}
+	stopOnce  *sync.Once
+}
+
+type ruleState struct {
+	rule    Rule
+	applied int
+}
+
+func newFaultInjector(seed int64) *faultInjector {
+	return &faultInjector{
+		lock:     &sync.Mutex{},
+		rand:     rand.New(rand.NewSource(seed)),
+		held:     make(map[link]*heldMessage),
+		stopChan: make(chan struct{}),
+		stopOnce: &sync.Once{},
+	}
+}
+
+func (f *faultInjector) addRule(rule Rule) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.rules = append(f.rules, &ruleState{rule: rule})
+}
+
+func (f *faultInjector) clearRules() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.rules = nil
+}
+
+// setPartition sets the group of each node, nil removes the partition
+func (f *faultInjector) setPartition(group []int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.partition = group
+}
+
+// faultFor returns the faults of all the rules the message matches, the rules are applied in the order they are added
+func (f *faultInjector) faultFor(from, to int, msg []byte) (Fault, bool) {
+	msgType, known := messageType(msg)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.partition != nil && from >= 0 && to >= 0 && from < len(f.partition) && to < len(f.partition) &&
+		f.partition[from] != f.partition[to] {
+		return Fault{}, true
+	}
+	var fault Fault
+	for _, el := range f.rules {
+		if !el.rule.match(from, to, msgType, known) {
+			continue
+		}
+		if el.rule.Count > 0 && el.applied >= el.rule.Count {
+			continue
+		}
+		if el.rule.Probability > 0 && f.rand.Float64() >= el.rule.Probability {
+			continue
+		}
+		el.applied++
+		fault.Drop = fault.Drop || el.rule.Fault.Drop
+		fault.Duplicate += el.rule.Fault.Duplicate
+		fault.Delay += el.rule.Fault.Delay
+		fault.Reorder = fault.Reorder || el.rule.Fault.Reorder
+	}
+	return fault, false
+}
+
+// intercept delivers the message from one node to the other with the faults of the rules
+func (f *faultInjector) intercept(from, to int, msg []byte, send func([]byte) error) error {
+	fault, partitioned := f.faultFor(from, to, msg)
+	if partitioned {
+		return errPartitioned
+	}
+	if fault.Drop {
+		return nil
+	}
+	deliver := func() error {
+		for i := 0; i < fault.Duplicate; i++ {
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+		return send(msg)
+	}
+	if fault.Delay > 0 {
+		select {
+		case <-time.After(fault.Delay):
+		case <-f.stopChan:
+			return nil
+		}
+	}
+	l := link{from: from, to: to}
+	if fault.Reorder {
+		return f.hold(l, deliver)
+	}
+	err := deliver()
+	f.release(l)
+	return err
+}
+
+// hold keeps the message until the next message on the link is sent or the reorder timeout. If a message is held on
+// the link already, this one is sent first and then the held one, so the two are swapped
+func (f *faultInjector) hold(l link, deliver func() error) error {
+	held := &heldMessage{
+		deliver: deliver,
+		done:    make(chan struct{}),
+	}
+	f.lock.Lock()
+	if _, ok := f.held[l]; ok {
+		f.lock.Unlock()
+		err := deliver()
+		f.release(l)
+		return err
+	}
+	f.held[l] = held
+	f.lock.Unlock()
+	select {
+	case <-held.done:
+	case <-time.After(reorderTimeout):
+		f.lock.Lock()
+		if f.held[l] == held {
+			delete(f.held, l)
+		}
+		f.lock.Unlock()
+	case <-f.stopChan:
+		return nil
+	}
+	return held.deliver()
+}
+
+// release lets the message held on the link go after the message just sent
+func (f *faultInjector) release(l link) {
+	f.lock.Lock()
+	held, ok := f.held[l]
+	if ok {
+		delete(f.held, l)
+	}
+	f.lock.Unlock()
+	if ok {
+		close(held.done)
+	}
+}
+
+func (f *faultInjector) stop() {
+	f.stopOnce.Do(func() {
+		close(f.stopChan)
+	})
+}
+
+// messageType returns the type of the wrapped message, the messages that are not wrapped are not known
+func messageType(msg []byte) (messages.THORChainTSSMessageType, bool) {
+	var wrappedMsg messages.WrappedMessage
+	if err := json.Unmarshal(msg, &wrappedMsg); err != nil {
+		return messages.Unknown, false
+	}
+	return wrappedMsg.MessageType, true
+}
@@ -0,0 +1,100 @@
+package tsstest
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/joltify-finance/tss/p2p"
+)
+
+// tssProtocolPrefix is the prefix of the tss, join party and signature notifier protocols, whose messages are framed
+// by p2p.WriteStreamWithBuffer. The streams of the other protocols, like the dht, are not intercepted
+const tssProtocolPrefix = "/p2p/"
+
+// Interceptor intercepts the message the node sends to the peer. It delivers the message with send, and it may drop,
+// delay or duplicate the message by not calling send, calling it later or more than once
+type Interceptor func(to peer.ID, msg []byte, send func([]byte) error) error
+
+// interceptedHost intercepts the messages written to the tss streams of the host, both the ones it opens and the
+// ones it accepts
+type interceptedHost struct {
+	host.Host
+	interceptor Interceptor
+}
+
+// NewInterceptedHost wraps the host, so all the tss messages it sends go through the interceptor
+func NewInterceptedHost(h host.Host, interceptor Interceptor) host.Host {
+	return &interceptedHost{
+		Host:        h,
+		interceptor: interceptor,
+	}
+}
+
+func (h *interceptedHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+	for _, el := range pids {
+		if !strings.HasPrefix(string(el), tssProtocolPrefix) {
+			return stream, nil
+		}
+	}
+	return h.intercept(stream), nil
+}
+
+func (h *interceptedHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if !strings.HasPrefix(string(pid), tssProtocolPrefix) {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		handler(h.intercept(stream))
+	})
+}
+
+func (h *interceptedHost) intercept(stream network.Stream) network.Stream {
+	return &interceptedStream{
+		Stream:      stream,
+		interceptor: h.interceptor,
+		lock:        &sync.Mutex{},
+	}
+}
+
+// interceptedStream is the tss stream of the intercepted host
+type interceptedStream struct {
+	network.Stream
+	interceptor Interceptor
+	lock        *sync.Mutex
+	pending     []byte
+}
+
+// Write collects the bytes until it has the whole message framed with the length header, and sends each message
+// through the interceptor on its own
+func (s *interceptedStream) Write(b []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending = append(s.pending, b...)
+	for len(s.pending) >= p2p.LengthHeader {
+		end := p2p.LengthHeader + int(binary.LittleEndian.Uint32(s.pending[:p2p.LengthHeader]))
+		if len(s.pending) < end {
+			break
+		}
+		msg := append([]byte{}, s.pending[p2p.LengthHeader:end]...)
+		s.pending = s.pending[end:]
+		err := s.interceptor(s.Conn().RemotePeer(), msg, func(buf []byte) error {
+			return p2p.WriteStreamWithBuffer(buf, s.Stream)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
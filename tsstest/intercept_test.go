@@ -0,0 +1,83 @@
+package tsstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/p2p"
+)
+
+type InterceptSuite struct{}
+
+var _ = Suite(&InterceptSuite{})
+
+func (s *InterceptSuite) TestInterceptedHost(c *C) {
+	p2p.ApplyDeadline = false
+	mn, err := mocknet.FullMeshConnected(2)
+	c.Assert(err, IsNil)
+	defer mn.Close()
+	hosts := mn.Hosts()
+	lock := &sync.Mutex{}
+	var intercepted []string
+	interceptor := func(to peer.ID, msg []byte, send func([]byte) error) error {
+		lock.Lock()
+		intercepted = append(intercepted, to.String()+"/"+string(msg))
+		lock.Unlock()
+		if string(msg) == "drop" {
+			return nil
+		}
+		// the reply is duplicated
+		if string(msg) == "reply" {
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+		return send(msg)
+	}
+	sender := NewInterceptedHost(hosts[0], interceptor)
+	receiver := NewInterceptedHost(hosts[1], interceptor)
+	receiver.SetStreamHandler("/p2p/test", func(stream network.Stream) {
+		defer stream.Close()
+		payload, err := p2p.ReadStreamWithBuffer(stream)
+		c.Check(err, IsNil)
+		c.Check(string(payload), Equals, "request")
+		// the reply on the stream we accept is intercepted as well
+		c.Check(p2p.WriteStreamWithBuffer([]byte("reply"), stream), IsNil)
+	})
+	// the streams of the other protocols are not intercepted
+	receiver.SetStreamHandler("/other", func(stream network.Stream) {
+		defer stream.Close()
+		c.Check(p2p.WriteStreamWithBuffer([]byte("other"), stream), IsNil)
+	})
+
+	stream, err := sender.NewStream(context.Background(), hosts[1].ID(), "/p2p/test")
+	c.Assert(err, IsNil)
+	c.Assert(p2p.WriteStreamWithBuffer([]byte("drop"), stream), IsNil)
+	c.Assert(p2p.WriteStreamWithBuffer([]byte("request"), stream), IsNil)
+	for i := 0; i < 2; i++ {
+		payload, err := p2p.ReadStreamWithBuffer(stream)
+		c.Assert(err, IsNil)
+		c.Assert(string(payload), Equals, "reply")
+	}
+	c.Assert(stream.Close(), IsNil)
+
+	stream, err = sender.NewStream(context.Background(), hosts[1].ID(), "/other")
+	c.Assert(err, IsNil)
+	payload, err := p2p.ReadStreamWithBuffer(stream)
+	c.Assert(err, IsNil)
+	c.Assert(string(payload), Equals, "other")
+	c.Assert(stream.Close(), IsNil)
+
+	lock.Lock()
+	defer lock.Unlock()
+	c.Assert(intercepted, DeepEquals, []string{
+		hosts[1].ID().String() + "/drop",
+		hosts[1].ID().String() + "/request",
+		hosts[0].ID().String() + "/reply",
+	})
+}
@@ -0,0 +1,336 @@
+package tsstest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	coskey "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	maddr "github.com/multiformats/go-multiaddr"
+	tcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tss"
+)
+
+// Config is the configuration of the simulated network
+type Config struct {
+	// Nodes is the number of the TssServers
+	Nodes int
+	// Seed makes the keys of the nodes and the random faults reproducible
+	Seed int64
+	// TssConfig is the configuration of all the nodes, the timeouts not set have the defaults of the tests
+	TssConfig common.TssConfig
+	// PreParams are the pre parameters of the nodes, the nodes without the pre parameters generate them, which is slow
+	PreParams []*bkeygen.LocalPreParams
+	// BaseFolder keeps the local state of the nodes, a temporary folder is used and removed on Stop if it is empty
+	BaseFolder string
+}
+
+// Node is a TssServer in the simulated network
+type Node struct {
+	Server       *tss.TssServer
+	PrivKey      tcrypto.PrivKey
+	PubKey       string
+	PeerID       peer.ID
+	StateManager storage.LocalStateManager
+}
+
+// Network runs the TssServers in process on the libp2p mock network, the messages among them are subject to the
+// fault rules and the partition of the network
+type Network struct {
+	mn         mocknet.Mocknet
+	nodes      []*Node
+	baseFolder string
+	removeBase bool
+	faults     *faultInjector
+}
+
+// NewNetwork create the TssServers of the config, all of them are connected to each other
+func NewNetwork(cfg Config) (*Network, error) {
+	if cfg.Nodes < 2 {
+		return nil, errors.New("at least 2 nodes are required")
+	}
+	conf := withDefaults(cfg.TssConfig)
+	// the mock streams do not support the deadline
+	p2p.ApplyDeadline = false
+	n := &Network{
+		mn:         mocknet.New(),
+		baseFolder: cfg.BaseFolder,
+		faults:     newFaultInjector(cfg.Seed),
+	}
+	if len(n.baseFolder) == 0 {
+		folder, err := ioutil.TempDir("", "tsstest")
+		if err != nil {
+			return nil, fmt.Errorf("fail to create the base folder: %w", err)
+		}
+		n.baseFolder = folder
+		n.removeBase = true
+	}
+	// the nodes are created one by one, as each of them connects to the ones created before it
+	for i := 0; i < cfg.Nodes; i++ {
+		var preParams *bkeygen.LocalPreParams
+		if i < len(cfg.PreParams) {
+			preParams = cfg.PreParams[i]
+		}
+		node, err := n.newNode(i, cfg.Seed, conf, preParams)
+		if err != nil {
+			n.Stop()
+			return nil, fmt.Errorf("fail to create node %d: %w", i, err)
+		}
+		n.nodes = append(n.nodes, node)
+	}
+	return n, nil
+}
+
+func withDefaults(conf common.TssConfig) common.TssConfig {
+	if conf.PartyTimeout == 0 {
+		conf.PartyTimeout = 10 * time.Second
+	}
+	if conf.KeyGenTimeout == 0 {
+		conf.KeyGenTimeout = 60 * time.Second
+	}
+	if conf.KeySignTimeout == 0 {
+		conf.KeySignTimeout = 60 * time.Second
+	}
+	if conf.PreParamTimeout == 0 {
+		conf.PreParamTimeout = 5 * time.Minute
+	}
+	return conf
+}
+
+func (n *Network) newNode(idx int, seed int64, conf common.TssConfig, preParams *bkeygen.LocalPreParams) (*Node, error) {
+	privKey := ed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("tsstest-%d-%d", seed, idx)))
+	pubKey, err := conversion.MarshalPubKey(&coskey.PubKey{Key: privKey.PubKey().Bytes()})
+	if err != nil {
+		return nil, err
+	}
+	peerID, err := conversion.GetPeerIDFromPubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	folder := filepath.Join(n.baseFolder, fmt.Sprintf("node%d", idx))
+	stateManager, err := storage.NewFileStateMgr(folder)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := crypto.UnmarshalEd25519PrivateKey(privKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	h, err := n.addHost(idx, sk)
+	if err != nil {
+		return nil, err
+	}
+	h = NewInterceptedHost(h, func(to peer.ID, msg []byte, send func([]byte) error) error {
+		return n.faults.intercept(idx, n.nodeIndex(to), msg, send)
+	})
+	comm, err := p2p.NewCommunicationWithHost("tsstest", nil, h)
+	if err != nil {
+		return nil, err
+	}
+	server, err := tss.NewTssWithCommunication(comm, privKey, folder, stateManager, conf, preParams)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{
+		Server:       server,
+		PrivKey:      privKey,
+		PubKey:       pubKey,
+		PeerID:       peerID,
+		StateManager: stateManager,
+	}, nil
+}
+
+// addHost adds the host of the node to the mock network and connects it to the nodes created before it
+func (n *Network) addHost(idx int, sk crypto.PrivKey) (host.Host, error) {
+	addr, err := maddr.NewMultiaddr(fmt.Sprintf("/ip4/10.0.%d.%d/tcp/6668", idx/250, idx%250+1))
+	if err != nil {
+		return nil, err
+	}
+	h, err := n.mn.AddPeer(sk, addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, el := range n.mn.Peers() {
+		if el == h.ID() {
+			continue
+		}
+		if _, err := n.mn.LinkPeers(h.ID(), el); err != nil {
+			return nil, err
+		}
+		if _, err := n.mn.ConnectPeers(h.ID(), el); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// nodeIndex returns the index of the node with the peer id, -1 if it is not in the network
+func (n *Network) nodeIndex(peerID peer.ID) int {
+	for i, el := range n.nodes {
+		if el.PeerID == peerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Nodes returns the nodes of the network
+func (n *Network) Nodes() []*Node {
+	return n.nodes
+}
+
+// PubKeys returns the pub keys of the nodes in the order of the nodes
+func (n *Network) PubKeys() []string {
+	pubKeys := make([]string, len(n.nodes))
+	for i, el := range n.nodes {
+		pubKeys[i] = el.PubKey
+	}
+	return pubKeys
+}
+
+// AddRule injects the fault on the messages that match the rule
+func (n *Network) AddRule(rule Rule) {
+	n.faults.addRule(rule)
+}
+
+// ClearRules removes all the fault rules
+func (n *Network) ClearRules() {
+	n.faults.clearRules()
+}
+
+// Partition splits the nodes into the groups, the nodes in the different groups cannot reach each other. The nodes
+// not in any of the groups form a group together
+func (n *Network) Partition(groups ...[]int) error {
+	group := make([]int, len(n.nodes))
+	for i := range group {
+		group[i] = -1
+	}
+	for i, el := range groups {
+		for _, idx := range el {
+			if idx < 0 || idx >= len(n.nodes) {
+				return fmt.Errorf("node %d is not in the network", idx)
+			}
+			group[idx] = i
+		}
+	}
+	if err := n.Heal(); err != nil {
+		return err
+	}
+	for i := range n.nodes {
+		for j := i + 1; j < len(n.nodes); j++ {
+			if group[i] == group[j] {
+				continue
+			}
+			if err := n.mn.UnlinkPeers(n.nodes[i].PeerID, n.nodes[j].PeerID); err != nil {
+				return err
+			}
+			if err := n.mn.DisconnectPeers(n.nodes[i].PeerID, n.nodes[j].PeerID); err != nil {
+				return err
+			}
+		}
+	}
+	n.faults.setPartition(group)
+	return nil
+}
+
+// Heal removes the partition of the network
+func (n *Network) Heal() error {
+	n.faults.setPartition(nil)
+	for i := range n.nodes {
+		for j := i + 1; j < len(n.nodes); j++ {
+			a, b := n.nodes[i].PeerID, n.nodes[j].PeerID
+			if len(n.mn.LinksBetweenPeers(a, b)) != 0 {
+				continue
+			}
+			if _, err := n.mn.LinkPeers(a, b); err != nil {
+				return err
+			}
+			if _, err := n.mn.ConnectPeers(a, b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Keygen runs the keygen on the nodes concurrently, all the nodes if none is given
+func (n *Network) Keygen(req keygen.Request, nodes ...int) ([]keygen.Response, []error) {
+	nodes = n.selectNodes(nodes)
+	responses := make([]keygen.Response, len(nodes))
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, idx := range nodes {
+		wg.Add(1)
+		go func(i, idx int) {
+			defer wg.Done()
+			// each node owns its copy of the request
+			localReq := req
+			localReq.Keys = append([]string{}, req.Keys...)
+			responses[i], errs[i] = n.nodes[idx].Server.Keygen(localReq)
+		}(i, idx)
+	}
+	wg.Wait()
+	return responses, errs
+}
+
+// KeySign runs the keysign on the nodes concurrently, all the nodes if none is given
+func (n *Network) KeySign(req keysign.Request, nodes ...int) ([]keysign.Response, []error) {
+	nodes = n.selectNodes(nodes)
+	responses := make([]keysign.Response, len(nodes))
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, idx := range nodes {
+		wg.Add(1)
+		go func(i, idx int) {
+			defer wg.Done()
+			localReq := req
+			localReq.Messages = append([]string{}, req.Messages...)
+			localReq.SignerPubKeys = append([]string{}, req.SignerPubKeys...)
+			responses[i], errs[i] = n.nodes[idx].Server.KeySign(localReq)
+		}(i, idx)
+	}
+	wg.Wait()
+	return responses, errs
+}
+
+func (n *Network) selectNodes(nodes []int) []int {
+	if len(nodes) != 0 {
+		return nodes
+	}
+	nodes = make([]int, len(n.nodes))
+	for i := range nodes {
+		nodes[i] = i
+	}
+	return nodes
+}
+
+// Stop stops all the nodes and the mock network
+func (n *Network) Stop() {
+	n.faults.stop()
+	for _, el := range n.nodes {
+		el.Server.Stop()
+	}
+	if err := n.mn.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "fail to close the mock network: %v\n", err)
+	}
+	if n.removeBase {
+		_ = os.RemoveAll(n.baseFolder)
+	}
+}
@@ -0,0 +1,511 @@
+package tsstest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	. "gopkg.in/check.v1"
+
+	"github.com/joltify-finance/tss/audit"
+	"github.com/joltify-finance/tss/common"
+	"github.com/joltify-finance/tss/conversion"
+	"github.com/joltify-finance/tss/keygen"
+	"github.com/joltify-finance/tss/keysign"
+	"github.com/joltify-finance/tss/messages"
+	"github.com/joltify-finance/tss/p2p"
+	"github.com/joltify-finance/tss/storage"
+	"github.com/joltify-finance/tss/tracing"
+	"github.com/joltify-finance/tss/tss"
+)
+
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type FaultSuite struct{}
+
+var _ = Suite(&FaultSuite{})
+
+func wrapped(c *C, msgType messages.THORChainTSSMessageType, payload string) []byte {
+	buf, err := json.Marshal(messages.WrappedMessage{
+		MessageType: msgType,
+		MsgID:       "msgID",
+		Payload:     []byte(payload),
+	})
+	c.Assert(err, IsNil)
+	return buf
+}
+
+type recorder struct {
+	lock *sync.Mutex
+	sent []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{lock: &sync.Mutex{}}
+}
+
+func (r *recorder) send(buf []byte) error {
+	var msg messages.WrappedMessage
+	if err := json.Unmarshal(buf, &msg); err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sent = append(r.sent, string(msg.Payload))
+	return nil
+}
+
+func (r *recorder) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.sent...)
+}
+
+func (s *FaultSuite) TestRules(c *C) {
+	f := newFaultInjector(1)
+	defer f.stop()
+	f.addRule(Rule{From: 0, To: AnyNode, MessageTypes: []messages.THORChainTSSMessageType{messages.TSSKeyGenVerMsg}, Fault: Fault{Drop: true}})
+	f.addRule(Rule{From: AnyNode, To: 2, Count: 1, Fault: Fault{Duplicate: 2}})
+
+	r := newRecorder()
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeyGenVerMsg, "a"), r.send), IsNil)
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeyGenMsg, "b"), r.send), IsNil)
+	c.Assert(f.intercept(1, 2, wrapped(c, messages.TSSKeyGenMsg, "c"), r.send), IsNil)
+	c.Assert(f.intercept(1, 2, wrapped(c, messages.TSSKeyGenMsg, "d"), r.send), IsNil)
+	// the message that is not wrapped only matches the rules of all the types
+	c.Assert(f.intercept(0, 1, []byte("raw"), func([]byte) error { return nil }), IsNil)
+	c.Assert(r.get(), DeepEquals, []string{"b", "c", "c", "c", "d"})
+
+	f.clearRules()
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeyGenVerMsg, "e"), r.send), IsNil)
+	c.Assert(r.get()[5:], DeepEquals, []string{"e"})
+}
+
+func (s *FaultSuite) TestProbabilityIsReproducible(c *C) {
+	run := func() []string {
+		f := newFaultInjector(42)
+		defer f.stop()
+		f.addRule(Rule{From: AnyNode, To: AnyNode, Probability: 0.5, Fault: Fault{Drop: true}})
+		r := newRecorder()
+		for _, el := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+			c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, el), r.send), IsNil)
+		}
+		return r.get()
+	}
+	first := run()
+	c.Assert(len(first) > 0 && len(first) < 10, Equals, true)
+	c.Assert(run(), DeepEquals, first)
+}
+
+func (s *FaultSuite) TestDelayAndReorder(c *C) {
+	f := newFaultInjector(1)
+	defer f.stop()
+	f.addRule(Rule{From: 0, To: 1, Count: 1, Fault: Fault{Reorder: true}})
+	r := newRecorder()
+	done := make(chan error)
+	go func() {
+		done <- f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "a"), r.send)
+	}()
+	// wait for the first message to be held
+	for i := 0; i < 100; i++ {
+		f.lock.Lock()
+		held := len(f.held)
+		f.lock.Unlock()
+		if held != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "b"), r.send), IsNil)
+	c.Assert(<-done, IsNil)
+	c.Assert(r.get(), DeepEquals, []string{"b", "a"})
+
+	// the held message goes anyway without the next message
+	f.addRule(Rule{From: 0, To: 1, Count: 1, Fault: Fault{Reorder: true}})
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "c"), r.send), IsNil)
+	c.Assert(r.get()[2:], DeepEquals, []string{"c"})
+
+	f.addRule(Rule{From: 0, To: 1, Fault: Fault{Delay: 100 * time.Millisecond}})
+	start := time.Now()
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "d"), r.send), IsNil)
+	c.Assert(time.Since(start) >= 100*time.Millisecond, Equals, true)
+}
+
+func (s *FaultSuite) TestReorderInARow(c *C) {
+	f := newFaultInjector(1)
+	defer f.stop()
+	f.addRule(Rule{From: 0, To: 1, Count: 2, Fault: Fault{Reorder: true}})
+	r := newRecorder()
+	done := make(chan error)
+	go func() {
+		done <- f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "a"), r.send)
+	}()
+	for i := 0; i < 100; i++ {
+		f.lock.Lock()
+		held := len(f.held)
+		f.lock.Unlock()
+		if held != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the second message to reorder is not held as well, it goes before the first one
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "b"), r.send), IsNil)
+	c.Assert(<-done, IsNil)
+	c.Assert(r.get(), DeepEquals, []string{"b", "a"})
+}
+
+func (s *FaultSuite) TestPartition(c *C) {
+	f := newFaultInjector(1)
+	defer f.stop()
+	f.setPartition([]int{0, 0, 1})
+	r := newRecorder()
+	c.Assert(f.intercept(0, 1, wrapped(c, messages.TSSKeySignMsg, "a"), r.send), IsNil)
+	c.Assert(errors.Is(f.intercept(0, 2, wrapped(c, messages.TSSKeySignMsg, "b"), r.send), errPartitioned), Equals, true)
+	f.setPartition(nil)
+	c.Assert(f.intercept(0, 2, wrapped(c, messages.TSSKeySignMsg, "c"), r.send), IsNil)
+	c.Assert(r.get(), DeepEquals, []string{"a", "c"})
+}
+
+type NetworkSuite struct {
+	preParams []*bkeygen.LocalPreParams
+}
+
+var _ = Suite(&NetworkSuite{})
+
+func (s *NetworkSuite) SetUpSuite(c *C) {
+	common.InitLog("info", true, "tsstest")
+	buf, err := ioutil.ReadFile("../test_data/preParam_test.data")
+	c.Assert(err, IsNil)
+	for _, item := range strings.Split(string(buf), "\n") {
+		var preParam bkeygen.LocalPreParams
+		val, err := hex.DecodeString(item)
+		c.Assert(err, IsNil)
+		c.Assert(json.Unmarshal(val, &preParam), IsNil)
+		s.preParams = append(s.preParams, &preParam)
+	}
+}
+
+func (s *NetworkSuite) newNetwork(c *C) *Network {
+	return s.newNetworkWithConfig(c, common.TssConfig{})
+}
+
+func (s *NetworkSuite) newNetworkWithConfig(c *C, conf common.TssConfig) *Network {
+	conf.PartyTimeout = 5 * time.Second
+	conf.KeyGenTimeout = 60 * time.Second
+	n, err := NewNetwork(Config{
+		Nodes:     4,
+		Seed:      1,
+		PreParams: s.preParams,
+		TssConfig: conf,
+	})
+	c.Assert(err, IsNil)
+	return n
+}
+
+func (s *NetworkSuite) TestKeygenWithFaults(c *C) {
+	n := s.newNetwork(c)
+	defer n.Stop()
+	c.Assert(n.PubKeys(), HasLen, 4)
+	n.AddRule(Rule{From: 0, To: AnyNode, Fault: Fault{Duplicate: 1}})
+	n.AddRule(Rule{From: 1, To: 2, Fault: Fault{Delay: 20 * time.Millisecond}})
+	n.AddRule(Rule{From: 3, To: AnyNode, Count: 10, Fault: Fault{Reorder: true}})
+
+	responses, errs := n.Keygen(keygen.NewRequest(n.PubKeys(), 10, "0.14.0"))
+	for _, el := range errs {
+		c.Assert(el, IsNil)
+	}
+	for _, el := range responses {
+		c.Assert(el.Status, Equals, common.Success)
+		c.Assert(el.PubKey, Equals, responses[0].PubKey)
+	}
+}
+
+func (s *NetworkSuite) TestKeygenPartition(c *C) {
+	n := s.newNetwork(c)
+	defer n.Stop()
+	pubKeys := n.PubKeys()
+	c.Assert(n.Partition([]int{3}), IsNil)
+
+	responses, errs := n.Keygen(keygen.NewRequest(pubKeys, 10, "0.14.0"), 0, 1, 2)
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Fail)
+		blamed := make(map[string]bool)
+		for _, node := range el.Blame.BlameNodes {
+			blamed[node.Pubkey] = true
+		}
+		// the isolated node is blamed, along with the leader if it fails to form the party
+		c.Assert(blamed[pubKeys[3]], Equals, true)
+	}
+	c.Assert(n.Heal(), IsNil)
+}
+
+func (s *NetworkSuite) TestKeysignLeaderFailover(c *C) {
+	n := s.newNetworkWithConfig(c, common.TssConfig{LeaderSelector: p2p.RoundRobinLeaderSelectorName})
+	defer n.Stop()
+	pubKeys := n.PubKeys()
+	responses, errs := n.Keygen(keygen.NewRequest(pubKeys, 10, "0.14.0"))
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+		c.Assert(el.FailedLeaders, HasLen, 0)
+	}
+	poolPubKey := responses[0].PubKey
+
+	// the round robin selector makes the node at blockHeight%4 of the sorted peer IDs the first leader, it does
+	// not take part in the keysign so that the others fail over to the next leader
+	var peerIDs []string
+	for _, el := range n.Nodes() {
+		peerIDs = append(peerIDs, el.PeerID.String())
+	}
+	sort.Strings(peerIDs)
+	blockHeight := int64(101)
+	leader := -1
+	var signers []int
+	for i, el := range n.Nodes() {
+		if el.PeerID.String() == peerIDs[blockHeight%int64(len(peerIDs))] {
+			leader = i
+			continue
+		}
+		signers = append(signers, i)
+	}
+
+	hash := sha256.Sum256([]byte("failover"))
+	msg := base64.StdEncoding.EncodeToString(hash[:])
+	signResponses, errs := n.KeySign(keysign.NewRequest(poolPubKey, []string{msg}, blockHeight, nil, "0.14.0"), signers...)
+	for i, el := range signResponses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+		c.Assert(el.Signatures, HasLen, 1)
+		c.Assert(el.FailedLeaders, DeepEquals, []string{pubKeys[leader]})
+	}
+}
+
+func (s *NetworkSuite) TestKeysignWithPresignatures(c *C) {
+	n := s.newNetwork(c)
+	defer n.Stop()
+	responses, errs := n.Keygen(keygen.NewRequest(n.PubKeys(), 10, "0.14.0"))
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+	}
+	poolPubKey := responses[0].PubKey
+
+	signers := []int{0, 1, 2}
+	var signerPubKeys []string
+	for _, idx := range signers {
+		signerPubKeys = append(signerPubKeys, n.Nodes()[idx].PubKey)
+	}
+	var wg sync.WaitGroup
+	for _, idx := range signers {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			resp, err := n.Nodes()[idx].Server.Presign(keysign.NewPresignRequest(poolPubKey, signerPubKeys, 1, 10, "0.15.0"))
+			c.Check(err, IsNil)
+			c.Check(resp.Status, Equals, common.Success)
+			c.Check(resp.PoolDepth, Equals, 1)
+		}(idx)
+	}
+	wg.Wait()
+
+	// the signers agree on the presignature in the join party, and it is consumed by all of them
+	hash := sha256.Sum256([]byte("presign"))
+	msg := base64.StdEncoding.EncodeToString(hash[:])
+	signResponses, errs := n.KeySign(keysign.NewRequest(poolPubKey, []string{msg}, 11, signerPubKeys, "0.15.0"), signers...)
+	for i, el := range signResponses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+		c.Assert(el.Signatures, DeepEquals, signResponses[0].Signatures)
+	}
+	for _, idx := range signers {
+		count, err := n.Nodes()[idx].StateManager.GetPresignatureCount(poolPubKey)
+		c.Assert(err, IsNil)
+		c.Assert(count, Equals, 0)
+	}
+
+	// the party runs the full keysign once the presignatures are used up
+	signResponses, errs = n.KeySign(keysign.NewRequest(poolPubKey, []string{msg}, 12, signerPubKeys, "0.15.0"), signers...)
+	for i, el := range signResponses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+	}
+}
+
+func (s *NetworkSuite) TestKeygenTrace(c *C) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	n := s.newNetwork(c)
+	defer n.Stop()
+
+	responses, errs := n.Keygen(keygen.NewRequest(n.PubKeys(), 10, "0.14.0"))
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+	}
+	spans := exporter.GetSpans()
+	ceremonies := make(map[trace.TraceID]trace.SpanID)
+	for _, el := range spans {
+		if el.Name == "tss.keygen" {
+			ceremonies[el.SpanContext.TraceID()] = el.SpanContext.SpanID()
+		}
+	}
+	// each node traces its keygen
+	c.Assert(ceremonies, HasLen, 4)
+	phases := make(map[string]int)
+	for _, el := range spans {
+		phases[el.Name]++
+		switch el.Name {
+		case "tss.join_party", "tss.keygen.generate":
+			c.Assert(el.Parent.SpanID(), Equals, ceremonies[el.SpanContext.TraceID()])
+		case "tss.apply_share":
+			c.Assert(attributeValue(el, tracing.RoundKey), Matches, "KGRound.*")
+		case "tss.process_message":
+			// the peer handles the message in the trace of the sender, and links it to its own keygen
+			_, ok := ceremonies[el.SpanContext.TraceID()]
+			c.Assert(ok, Equals, true)
+			c.Assert(el.Parent.IsRemote(), Equals, true)
+			c.Assert(el.Links, HasLen, 1)
+			c.Assert(el.Links[0].SpanContext.SpanID(), Equals, ceremonies[el.Links[0].SpanContext.TraceID()])
+			c.Assert(el.Links[0].SpanContext.TraceID(), Not(Equals), el.SpanContext.TraceID())
+		}
+	}
+	c.Assert(phases["tss.join_party"], Equals, 4)
+	c.Assert(phases["tss.keygen.generate"], Equals, 4)
+	c.Assert(phases["tss.apply_share"] > 0, Equals, true)
+	c.Assert(phases["tss.process_message"] > 0, Equals, true)
+}
+
+func (s *NetworkSuite) TestKeygenAudit(c *C) {
+	n := s.newNetworkWithConfig(c, common.TssConfig{EnableAudit: true})
+	defer n.Stop()
+
+	req := keygen.NewRequest(n.PubKeys(), 10, "0.14.0")
+	responses, errs := n.Keygen(req)
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+	}
+	for i := range n.Nodes() {
+		folder := filepath.Join(n.baseFolder, fmt.Sprintf("node%d", i), "audit")
+		summary, err := audit.Verify(folder)
+		c.Assert(err, IsNil)
+		c.Assert(summary.Entries, Equals, uint64(2))
+		c.Assert(summary.PubKey, Equals, n.PubKeys()[i])
+		files, err := filepath.Glob(filepath.Join(folder, "audit-*.log"))
+		c.Assert(err, IsNil)
+		buf, err := ioutil.ReadFile(files[0])
+		c.Assert(err, IsNil)
+		// the request is recorded before the party, and the outcome links to it
+		lines := bytes.Split(bytes.TrimSpace(buf), []byte("\n"))
+		c.Assert(lines, HasLen, 2)
+		var request, outcome audit.Entry
+		c.Assert(json.Unmarshal(lines[0], &request), IsNil)
+		c.Assert(request.Action, Equals, audit.ActionKeygen)
+		c.Assert(request.Stage, Equals, audit.StageRequest)
+		c.Assert(request.Participants, DeepEquals, req.Keys)
+		c.Assert(json.Unmarshal(lines[1], &outcome), IsNil)
+		c.Assert(outcome.Stage, Equals, audit.StageOutcome)
+		c.Assert(outcome.RequestHash, Equals, request.Hash)
+		c.Assert(outcome.MsgID, Equals, request.MsgID)
+		c.Assert(outcome.Status, Equals, "success")
+		c.Assert(string(outcome.Result), Matches, ".*"+responses[i].PubKey+".*")
+	}
+}
+
+func (s *NetworkSuite) TestReadiness(c *C) {
+	n := s.newNetwork(c)
+	defer n.Stop()
+	for _, el := range n.Nodes() {
+		c.Assert(el.Server.Readiness().Ready, Equals, true)
+	}
+	responses, errs := n.Keygen(keygen.NewRequest(n.PubKeys(), 10, "0.14.0"))
+	for i, el := range responses {
+		c.Assert(errs[i], IsNil)
+		c.Assert(el.Status, Equals, common.Success)
+	}
+	c.Assert(n.Nodes()[0].Server.Readiness().Ready, Equals, true)
+
+	// the node sees 2 of the 4 members, while 3 are needed to sign
+	c.Assert(n.Partition([]int{2, 3}), IsNil)
+	readiness := n.Nodes()[0].Server.Readiness()
+	c.Assert(readiness.Ready, Equals, false)
+	for _, el := range readiness.Checks {
+		c.Assert(el.Healthy, Equals, el.Name != tss.CheckCommittee, Commentf("%s: %s", el.Name, el.Detail))
+	}
+	c.Assert(n.Heal(), IsNil)
+	c.Assert(n.Nodes()[0].Server.Readiness().Ready, Equals, true)
+
+	// after the vault rotation to the committee of the nodes 0 and 1, the old committee going offline does not
+	// fail the readiness
+	rotated := storage.KeygenLocalState{
+		PubKey:          conversion.GetRandomPubKey(),
+		ParticipantKeys: n.PubKeys()[:2],
+	}
+	c.Assert(n.Nodes()[0].StateManager.SaveLocalState(rotated), IsNil)
+	c.Assert(n.Partition([]int{2, 3}), IsNil)
+	c.Assert(n.Nodes()[0].Server.Readiness().Ready, Equals, true)
+	c.Assert(n.Partition([]int{1, 2, 3}), IsNil)
+	c.Assert(n.Nodes()[0].Server.Readiness().Ready, Equals, false)
+	c.Assert(n.Heal(), IsNil)
+}
+
+func (s *NetworkSuite) TestStatus(c *C) {
+	n := s.newNetwork(c)
+	defer n.Stop()
+	node := n.Nodes()[0].Server
+	status := node.Status()
+	c.Assert(status.PeerID, Equals, n.Nodes()[0].PeerID.String())
+	c.Assert(status.Peers, HasLen, 3)
+	c.Assert(status.Ceremonies, HasLen, 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n.Keygen(keygen.NewRequest(n.PubKeys(), 10, "0.14.0"))
+	}()
+	// the keygen shows up with its phase while it is running
+	var ceremony *tss.CeremonyStatus
+	for i := 0; i < 100 && ceremony == nil; i++ {
+		for _, el := range node.Status().Ceremonies {
+			if len(el.Phase) != 0 {
+				ceremony = &el
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.Assert(ceremony, NotNil)
+	c.Assert(ceremony.Type, Equals, "keygen")
+	c.Assert(ceremony.Phase, Matches, "join_party|keygen.generate")
+	<-done
+	c.Assert(node.Status().Ceremonies, HasLen, 0)
+	c.Assert(node.Status().JoinPartyGroups, HasLen, 0)
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) string {
+	for _, el := range span.Attributes {
+		if el.Key == key {
+			return el.Value.AsString()
+		}
+	}
+	return ""
+}